@@ -60,6 +60,7 @@ export const conversationApi = createApi({
 								
 								markedAsExcludedAt: interaction.markedAsExcludedAt,
 
+								status: interaction.status,
 								messageContent: interaction.messageContent,
 								errors: interaction.errors,
 
@@ -109,6 +110,7 @@ export const conversationApi = createApi({
 
 								markedAsExcludedAt: interaction.markedAsExcludedAt,
 
+								status: interaction.status,
 								messageContent: interaction.messageContent,
 								errors: interaction.errors,
 
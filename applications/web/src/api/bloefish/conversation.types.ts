@@ -1,5 +1,7 @@
 import type { Actor, AiRelayOptions, BloefishError } from './shared.types';
 
+export type InteractionStatus = 'pending' | 'streaming' | 'completed' | 'failed' | 'cancelled';
+
 export interface Interaction {
 	id: string;
 	fileIds: string[];
@@ -9,6 +11,7 @@ export interface Interaction {
 
 	markedAsExcludedAt: string | null;
 
+	status: InteractionStatus;
 	messageContent: string;
 	errors: BloefishError[];
 
@@ -19,6 +22,8 @@ export interface Interaction {
 	updatedAt: string;
 	completedAt: string | null;
 	deletedAt: string | null;
+	startedAt: string | null;
+	firstTokenAt: string | null;
 }
 
 export interface Conversation {
@@ -1,6 +1,7 @@
 import type { Actor } from './shared.types';
 
 export interface CreateUploadRequest {
+	idempotencyKey: string;
 	name: string;
 	size: number;
 	mimeType: string;
@@ -15,6 +15,7 @@ export interface SkillSet {
 }
 
 export interface CreateSkillSetRequest {
+	idempotencyKey: string;
 	name: string;
 	icon: string;
 	description: string;
@@ -1,7 +1,7 @@
 import { useEffect } from 'react';
 import { useAppDispatch } from '~/store';
 import type { StreamMessage } from './stream.types';
-import { addInteractionError, addInteractionFragment, updateConversationTitle, updateInteractionMessageContent } from '~/features/conversations/store';
+import { addInteractionError, addInteractionFragment, resetConversationTitle, resetInteractionMessageContent, updateConversationTitle, updateInteractionMessageContent } from '~/features/conversations/store';
 import camelcaseKeys from 'camelcase-keys';
 
 export function useStreamListener() {
@@ -57,6 +57,22 @@ export function useStreamListener() {
 					break;
 				}
 
+				case 'message_reset': {
+					const [conversationId, interactionId] = message.channelId.split('/');
+					if (!conversationId || !interactionId) return;
+
+					if (interactionId === 'title') {
+						dispatch(resetConversationTitle({ conversationId }));
+						break;
+					}
+
+					dispatch(resetInteractionMessageContent({
+						conversationId,
+						interactionId,
+					}));
+					break;
+				}
+
 				case 'error': {
 					const [conversationId, interactionId] = message.channelId.split('/');
 					if (!conversationId || !interactionId) return;
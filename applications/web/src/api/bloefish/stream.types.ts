@@ -24,4 +24,12 @@ export interface StreamErrorMessage {
 	error: BloefishError;
 }
 
-export type StreamMessage = StreamMessageFull | StreamMessageFragment | StreamErrorMessage;
+export interface StreamMessageReset {
+	channelId: string;
+	type: 'message_reset';
+	messageFull: null;
+	messageFragment: null;
+	error: null;
+}
+
+export type StreamMessage = StreamMessageFull | StreamMessageFragment | StreamErrorMessage | StreamMessageReset;
@@ -1,5 +1,5 @@
 import { createApi } from '@reduxjs/toolkit/query/react';
-import type { GetOrCreateDefaultUserResponse } from './user.types';
+import type { GetOrCreateDefaultUserResponse, GetUserPreferencesRequest, GetUserPreferencesResponse } from './user.types';
 import { createBaseQueryWithSnake } from './base';
 
 export const userApi = createApi({
@@ -10,5 +10,12 @@ export const userApi = createApi({
 		getOrCreateDefaultUser: builder.query<GetOrCreateDefaultUserResponse, void>({
 			query: () => `2025-02-12/get_or_create_default_user`,
 		}),
+
+		getUserPreferences: builder.query<GetUserPreferencesResponse, GetUserPreferencesRequest>({
+			query: (body) => ({
+				url: '2025-02-12/get_user_preferences',
+				body,
+			}),
+		}),
 	}),
 })
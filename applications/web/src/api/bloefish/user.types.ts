@@ -1,3 +1,5 @@
+import type { AiRelayOptions } from './shared.types';
+
 export interface User {
 	id: string;
 	defaultUser: boolean;
@@ -5,6 +7,22 @@ export interface User {
 	updatedAt: string | null;
 }
 
+export interface UserPreferences {
+	userId: string;
+	defaultAiRelayOptions: AiRelayOptions | null;
+	titleGenerationAiRelayOptions: AiRelayOptions | null;
+	createdAt: string | null;
+	updatedAt: string | null;
+}
+
 export interface GetOrCreateDefaultUserResponse {
 	user: User;
 }
+
+export interface GetUserPreferencesRequest {
+	userId: string;
+}
+
+export interface GetUserPreferencesResponse {
+	preferences: UserPreferences;
+}
@@ -59,6 +59,7 @@ export const continueConversationChain = createAsyncThunk<
 				
 				markedAsExcludedAt: null,
 
+				status: interaction.inputInteraction.status,
 				messageContent: params.messageContent,
 				errors: [],
 
@@ -76,6 +77,7 @@ export const continueConversationChain = createAsyncThunk<
 				
 				markedAsExcludedAt: null,
 
+				status: interaction.responseInteraction.status,
 				messageContent: '',
 				errors: [],
 
@@ -80,6 +80,7 @@ export const startConversationChain = createAsyncThunk<
 
 				markedAsExcludedAt: null,
 
+				status: interaction.inputInteraction.status,
 				messageContent: params.messageContent,
 				errors: [],
 
@@ -97,6 +98,7 @@ export const startConversationChain = createAsyncThunk<
 
 				markedAsExcludedAt: null,
 
+				status: interaction.responseInteraction.status,
 				messageContent: '',
 				errors: [],
 
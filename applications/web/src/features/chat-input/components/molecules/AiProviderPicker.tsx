@@ -1,6 +1,7 @@
 import { useEffect, useState } from 'react';
 import { LuBot, LuChevronDown } from 'react-icons/lu';
 import { aiRelayApi } from '~/api/bloefish/ai-relay';
+import { userApi } from '~/api/bloefish/user';
 import { Button } from '~/components/ui/button';
 import { MenuContent, MenuRadioItem, MenuRadioItemGroup, MenuRoot, MenuTrigger } from '~/components/ui/menu';
 import { useAppDispatch } from '~/store';
@@ -18,6 +19,12 @@ export const AiProviderPicker: React.FC<AiProviderPickerProps> = ({
 	identifier,
 }) => {
 	const { data: providers } = aiRelayApi.useListSupportedQuery();
+	const { data: userData } = userApi.useGetOrCreateDefaultUserQuery();
+	const { data: preferences, isLoading: preferencesLoading } = userApi.useGetUserPreferencesQuery({
+		userId: userData?.user.id ?? 'impossible',
+	}, {
+		skip: !userData,
+	});
 	const { destinationModel } = useChatInput(identifier);
 	const dispatch = useAppDispatch();
 
@@ -25,7 +32,8 @@ export const AiProviderPicker: React.FC<AiProviderPickerProps> = ({
 	const loading = !availableModels || availableModels.length === 0;
 
 	useEffect(() => {
-		if (!providers) return;
+		// Wait for the preferences, so the default model is picked rather than the first
+		if (!providers || !userData || preferencesLoading) return;
 
 		const availableModels = providers.models ? [...providers.models].sort((a, b) => {
 			const aKey = `${a.providerId}-${a.modelId}`;
@@ -36,18 +44,21 @@ export const AiProviderPicker: React.FC<AiProviderPickerProps> = ({
 		if (availableModels)
 			setAvailableModels(availableModels);
 
+		const defaultOptions = preferences?.preferences.defaultAiRelayOptions;
+		const defaultModel = defaultOptions && availableModels.find(model => model.providerId === defaultOptions.providerId && model.modelId === defaultOptions.modelId);
+
 		if (!destinationModel && availableModels.length > 0) {
 			dispatch(updateDestinationModel({
 				identifier,
-				destinationModel: availableModels[0],
+				destinationModel: defaultModel ?? availableModels[0],
 			}));
 		} else if (destinationModel && !availableModels.find(model => model.providerId === destinationModel.providerId && model.modelId === destinationModel.modelId)) {
 			dispatch(updateDestinationModel({
 				identifier,
-				destinationModel: availableModels[0],
+				destinationModel: defaultModel ?? availableModels[0],
 			}));
 		}
-	}, [providers]);
+	}, [providers, userData, preferences, preferencesLoading]);
 
 	return (
 		<MenuRoot>
@@ -40,6 +40,7 @@ export const useFileUpload = (identifier: string) => {
 			dispatch(updateFileUploadStatus({ identifier, fileUploadId, status: 'uploading' }));
 
 			const createUploadResponse = await createUploadTrigger({
+				idempotencyKey: generateRandomString(20),
 				name: file.name,
 				size: file.size,
 				mimeType: file.type,
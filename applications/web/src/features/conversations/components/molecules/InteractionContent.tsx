@@ -38,7 +38,7 @@ export const InteractionContent: React.FC<InteractionContentProps> = ({
 					<Card.Body px={isBot ? 6 : 4} py={isBot ? 6 : 3}>
 						<Stack gap={4}>
 							{Boolean(interaction.messageContent) && (<MarkdownRenderer markdown={interaction.messageContent} />)}
-							{!interaction.messageContent && !interaction.errors?.length && (
+							{!interaction.messageContent && (interaction.status === 'pending' || interaction.status === 'streaming') && (
 								<Center>
 									<Spinner />
 								</Center>
@@ -1,7 +1,7 @@
 import { Alert, Box, Stack, Text } from '@chakra-ui/react';
 import { motion } from 'motion/react';
 import { useState } from 'react';
-import { LuFileQuestion } from 'react-icons/lu';
+import { LuFileQuestion, LuShieldAlert } from 'react-icons/lu';
 import type { BloefishError } from '~/api/bloefish/shared.types';
 import { Button } from '~/components/ui/button';
 import { generateRandomString } from '~/utils/random';
@@ -45,6 +45,25 @@ const InteractionError: React.FC<{ error: BloefishError }> = ({ error }) => {
 				</Alert.Root>
 			);
 
+		case 'guardrail_failed':
+			return (
+				<Alert.Root status={'warning'} variant={'subtle'}>
+					<Alert.Indicator>
+						<LuShieldAlert />
+					</Alert.Indicator>
+					<Alert.Content overflowX={'scroll'}>
+						<Alert.Title>
+							Message blocked
+						</Alert.Title>
+						<Alert.Description>
+							{error.meta.stage === 'input'
+								? 'Your message was blocked by a guardrail, so it wasn\'t sent to the model.'
+								: 'The response was blocked by a guardrail, so it isn\'t shown.'}
+						</Alert.Description>
+					</Alert.Content>
+				</Alert.Root>
+			);
+
 		default:
 			return (
 				<Alert.Root status={'error'} variant={'subtle'}>
@@ -19,9 +19,9 @@ export const ConversationInteractionActions: React.FC<ConversationInteractionAct
 	const isBot = interaction.owner.type === 'bot';
 	const hasErrors = interaction.errors?.length > 0;
 	const hasMessageContent = interaction.messageContent !== '';
-	const hasCompletedAt = interaction.completedAt !== null;
+	const inProgress = interaction.status === 'pending' || interaction.status === 'streaming';
 
-	const pending = isBot && (!hasErrors && !hasMessageContent && !hasCompletedAt);
+	const pending = isBot && inProgress && !hasMessageContent;
 
 	async function setExcludedState(excluded: boolean, undoable: boolean = true) {
 		if (excludedMutationState.isLoading) return;
@@ -1,5 +1,5 @@
 import { createSlice, type PayloadAction } from '@reduxjs/toolkit';
-import type { AddActiveInteractionPayload, AddInteractionErrorPayload, AddInteractionFragmentPayload, AddInteractionPayload, Conversation, CreateConversationPayload, DeleteConversationsPayload as DeleteConversationsPayload, DeleteInteractionsPayload, UpdateConversationTitlePayload, UpdateInteractionExcludedStatePayload, UpdateInteractionMessageContentPayload } from './types';
+import type { AddActiveInteractionPayload, AddInteractionErrorPayload, AddInteractionFragmentPayload, AddInteractionPayload, Conversation, CreateConversationPayload, DeleteConversationsPayload as DeleteConversationsPayload, DeleteInteractionsPayload, ResetConversationTitlePayload, ResetInteractionMessageContentPayload, UpdateConversationTitlePayload, UpdateInteractionExcludedStatePayload, UpdateInteractionMessageContentPayload } from './types';
 
 const initialState: Record<string, Conversation | undefined> = {};
 
@@ -42,6 +42,7 @@ export const conversationsSlice = createSlice({
 			conversation.interactions[payload.interactionId] = {
 				conversationId: payload.conversationId,
 				id: payload.interactionId,
+				status: payload.status,
 				messageContent: payload.messageContent,
 				streamChannelId: conversation.streamChannelId,
 
@@ -66,6 +67,7 @@ export const conversationsSlice = createSlice({
 			conversation.interactions[payload.interactionId] = {
 				id: payload.interactionId,
 				conversationId: payload.conversationId,
+				status: payload.status,
 				messageContent: payload.messageContent,
 				streamChannelId: conversation.streamChannelId,
 
@@ -95,6 +97,7 @@ export const conversationsSlice = createSlice({
 				return;
 			}
 
+			interaction.status = 'streaming';
 			interaction.messageContent += payload.fragment;
 		},
 		addInteractionError: (state, { payload }: PayloadAction<AddInteractionErrorPayload>) => {
@@ -108,6 +111,7 @@ export const conversationsSlice = createSlice({
 				return;
 			}
 
+			interaction.status = 'failed';
 			interaction.errors = [payload.error]; // TODO(afr): Support multiple errors?
 		},
 		updateInteractionMessageContent: (state, { payload }: PayloadAction<UpdateInteractionMessageContentPayload>) => {
@@ -121,9 +125,26 @@ export const conversationsSlice = createSlice({
 				return;
 			}
 
+			interaction.status = 'completed';
 			interaction.messageContent = payload.content;
 			interaction.completedAt = new Date().toISOString();
 		},
+		resetInteractionMessageContent: (state, { payload }: PayloadAction<ResetInteractionMessageContentPayload>) => {
+			const conversation = state[payload.conversationId];
+			if (!conversation) {
+				return;
+			}
+
+			const interaction = conversation.interactions[payload.interactionId];
+			if (!interaction) {
+				return;
+			}
+
+			// The message is being sent again from the start
+			interaction.status = 'streaming';
+			interaction.messageContent = '';
+			interaction.errors = [];
+		},
 		deleteConversations: (state, { payload }: PayloadAction<DeleteConversationsPayload>) => {
 			for (const conversationId of payload.conversationIds) {
 				state[conversationId] = void 0;
@@ -145,6 +166,14 @@ export const conversationsSlice = createSlice({
 				conversation.title = payload.title;
 			}
 		},
+		resetConversationTitle: (state, { payload }: PayloadAction<ResetConversationTitlePayload>) => {
+			const conversation = state[payload.conversationId];
+			if (!conversation) {
+				return;
+			}
+
+			conversation.title = null;
+		},
 		deleteInteractions: (state, { payload }: PayloadAction<DeleteInteractionsPayload>) => {
 			for (const conversation of Object.values(state)) {
 				if (!conversation) {
@@ -185,8 +214,10 @@ export const {
 	addInteractionFragment,
 	addInteractionError,
 	updateInteractionMessageContent,
+	resetInteractionMessageContent,
 	deleteConversations,
 	updateConversationTitle,
+	resetConversationTitle,
 	deleteInteractions,
 	updateInteractionIncludedState,
 } = conversationsSlice.actions;
@@ -1,4 +1,5 @@
 import type { Actor, AiRelayOptions, BloefishError } from '~/api/bloefish/shared.types';
+import type { InteractionStatus } from '~/api/bloefish/conversation.types';
 
 export interface ConversationPlugin {
 	conversationId: string;
@@ -23,6 +24,7 @@ export interface CreateConversationPayload {
 export interface AddInteractionPayload extends ConversationPlugin, InteractionPlugin {
 	streamChannelId: string;
 	owner: Actor;
+	status: InteractionStatus;
 	messageContent: string;
 	errors: BloefishError[];
 	aiRelayOptions: AiRelayOptions;
@@ -34,6 +36,7 @@ export interface AddInteractionPayload extends ConversationPlugin, InteractionPl
 }
 
 export interface AddActiveInteractionPayload extends ConversationPlugin, InteractionPlugin {
+	status: InteractionStatus;
 	messageContent: string;
 	streamChannelId: string;
 	aiRelayOptions: AiRelayOptions; // TODO(afr): this should come from the backend
@@ -57,11 +60,15 @@ export interface UpdateInteractionMessageContentPayload extends ConversationPlug
 	content: string;
 }
 
+export type ResetInteractionMessageContentPayload = ConversationPlugin & InteractionPlugin;
+
 export interface UpdateConversationTitlePayload extends ConversationPlugin {
 	title: string;
 	treatAsFragment: boolean;
 }
 
+export type ResetConversationTitlePayload = ConversationPlugin;
+
 export interface DeleteConversationsPayload {
 	conversationIds: string[];
 }
@@ -93,6 +100,7 @@ export interface Interaction {
 	id: string;
 	conversationId: string;
 	streamChannelId: string | null;
+	status: InteractionStatus;
 	messageContent: string;
 
 	markedAsExcludedAt: string | null;
@@ -8,6 +8,7 @@ import { z } from 'zod';
 import { zodResolver } from '@hookform/resolvers/zod';
 import { userApi } from '~/api/bloefish/user';
 import { toaster } from '~/components/ui/toaster';
+import { useIdempotencyKey } from '~/hooks/useIdempotencyKey';
 
 const schema = z.object({
 	name: z.string().min(2, 'Name must be at least 2 characters'),
@@ -31,6 +32,7 @@ export const CreateSkillSetDialog: React.FC<React.PropsWithChildren<CreateSkillS
 	const initialFocusRef = useRef<HTMLInputElement>(null)
 	const closeRef = useRef<HTMLButtonElement>(null);
 	const [createSkillSet] = skillSetApi.useCreateSkillSetMutation();
+	const [idempotencyKey, generateNewIdempotencyKey] = useIdempotencyKey();
 
 	const {
 		register,
@@ -43,6 +45,7 @@ export const CreateSkillSetDialog: React.FC<React.PropsWithChildren<CreateSkillS
 	const onSubmit = async (data: FormData) => {
 		try {
 			await createSkillSet({
+				idempotencyKey,
 				name: data.name,
 				description: data.description,
 				prompt: data.prompt,
@@ -53,6 +56,7 @@ export const CreateSkillSetDialog: React.FC<React.PropsWithChildren<CreateSkillS
 				},
 			}).unwrap();
 
+			generateNewIdempotencyKey();
 			closeRef.current?.click();
 
 			onSuccess?.();
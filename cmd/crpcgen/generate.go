@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by crpcgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+// Ensure RPCClient implements {{ .Interface }}.
+var _ {{ .Interface }} = (*RPCClient)(nil)
+
+type RPCClient struct {
+	client *crpc.Client
+}
+
+func NewRPCClient(ctx context.Context, cfg config.UnauthenticatedService) {{ .Interface }} {
+	return &RPCClient{
+		client: crpc.NewClient(ctx, cfg.BaseURL, nil),
+	}
+}
+{{ range .Methods }}
+{{- if .EventType }}
+func (r *RPCClient) {{ .Name }}(ctx context.Context{{ if .RequestType }}, req {{ .RequestType }}{{ end }}, send func({{ .EventType }}) error) error {
+	stream, err := r.client.DoStream(ctx, "{{ .Method }}", "{{ .Version }}", {{ if .RequestType }}req{{ else }}nil{{ end }})
+	if err != nil {
+		return err
+	}
+
+	return crpc.Receive(stream, send)
+}
+{{ else if .ResponseType }}
+func (r *RPCClient) {{ .Name }}(ctx context.Context{{ if .RequestType }}, req {{ .RequestType }}{{ end }}) (resp {{ .ResponseType }}, err error) {
+	return resp, r.client.Do(ctx, "{{ .Method }}", "{{ .Version }}", {{ if .RequestType }}req{{ else }}nil{{ end }}, &resp)
+}
+{{ else }}
+func (r *RPCClient) {{ .Name }}(ctx context.Context{{ if .RequestType }}, req {{ .RequestType }}{{ end }}) error {
+	return r.client.Do(ctx, "{{ .Method }}", "{{ .Version }}", {{ if .RequestType }}req{{ else }}nil{{ end }}, nil)
+}
+{{ end }}
+{{- end }}
+`))
+
+type clientMethod struct {
+	*serviceMethod
+	*registration
+}
+
+func generateClient(svc *service, registrations map[string]*registration) ([]byte, error) {
+	methods := make([]*clientMethod, 0, len(svc.Methods))
+	imports := map[string]string{
+		"context": "context",
+		"config":  "github.com/0xdeafcafe/bloefish/libraries/config",
+		"crpc":    "github.com/0xdeafcafe/bloefish/libraries/crpc",
+	}
+
+	var problems []string
+	declared := map[string]bool{}
+
+	for _, method := range svc.Methods {
+		declared[method.Name] = true
+
+		reg, ok := registrations[method.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is on the interface but not registered on the server", method.Name))
+			continue
+		}
+
+		for _, qualifier := range method.Qualifiers {
+			path, ok := svc.Imports[qualifier]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown package %s", method.Name, qualifier)
+			}
+
+			imports[qualifier] = path
+		}
+
+		methods = append(methods, &clientMethod{method, reg})
+	}
+
+	for handler, reg := range registrations {
+		if !declared[handler] {
+			problems = append(problems, fmt.Sprintf("%s is registered on the server as %s but not on the interface", handler, reg.Method))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("service and server disagree:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, map[string]any{
+		"Package":   svc.Package,
+		"Interface": svc.Interface,
+		"Imports":   importLines(imports),
+		"Methods":   methods,
+	}); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// importLines returns the import specs, with the standard library grouped
+// before everything else.
+func importLines(imports map[string]string) []string {
+	var std, other []string
+
+	for name, path := range imports {
+		line := fmt.Sprintf("%q", path)
+		if path[strings.LastIndex(path, "/")+1:] != name {
+			line = fmt.Sprintf("%s %q", name, path)
+		}
+
+		if strings.Contains(path, ".") {
+			other = append(other, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+
+	sort.Strings(std)
+	sort.Strings(other)
+
+	if len(std) > 0 && len(other) > 0 {
+		std = append(std, "")
+	}
+
+	return append(std, other...)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testServiceSource = `package things
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+)
+
+type Service interface {
+	CreateThing(ctx context.Context, req *CreateThingRequest) (*CreateThingResponse, error)
+	ListThings(context.Context) (*ListThingsResponse, error)
+	DeleteThing(ctx context.Context, req *DeleteThingRequest) error
+	Ping(ctx context.Context) error
+	WatchThings(ctx context.Context, req *WatchThingsRequest, send func(*WatchThingsEvent) error) error
+}
+
+type CreateThingRequest struct{ Err cher.E }
+type CreateThingResponse struct{}
+type ListThingsResponse struct{}
+type DeleteThingRequest struct{}
+type WatchThingsRequest struct{}
+type WatchThingsEvent struct{}
+`
+
+const testRegistrationsSource = `package rpc
+
+func New() {
+	svr.Register("create_thing", "2025-01-01", schema("create_thing"), rpc.CreateThing)
+	svr.Register("create_thing", "2025-02-02", schema("create_thing"), rpc.CreateThing)
+	svr.Register("list_things", "2025-01-01", nil, rpc.ListThings)
+	svr.Register("list_things", "preview", nil, rpc.ListThings)
+	svr.Register("delete_thing", "2025-01-01", schema("delete_thing"), rpc.DeleteThing)
+	svr.Register("ping", "preview", nil, rpc.Ping)
+	svr.Register("watch_things", "2025-02-02", schema("watch_things"), rpc.WatchThings)
+	svr.Register("old_thing", "2025-02-02", nil, nil)
+}
+`
+
+func writeTestService(t *testing.T, serviceSource, registrationsSource string) (string, string) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "things.go"), []byte(serviceSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registrations := filepath.Join(dir, "rpc.go.txt")
+	if err := os.WriteFile(registrations, []byte(registrationsSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, registrations
+}
+
+func TestGenerateClient(t *testing.T) {
+	is := is.New(t)
+
+	dir, registrationsPath := writeTestService(t, testServiceSource, testRegistrationsSource)
+
+	svc, err := parseService(dir, "Service")
+	is.NoErr(err)
+
+	registrations, err := parseRegistrations(registrationsPath)
+	is.NoErr(err)
+
+	client, err := generateClient(svc, registrations)
+	is.NoErr(err)
+
+	source := string(client)
+	is.True(strings.HasPrefix(source, "// Code generated by crpcgen. DO NOT EDIT.\n\npackage things\n"))
+	is.True(strings.Contains(source, "var _ Service = (*RPCClient)(nil)"))
+	is.True(strings.Contains(source, `return resp, r.client.Do(ctx, "create_thing", "2025-02-02", req, &resp)`))
+	is.True(strings.Contains(source, `return resp, r.client.Do(ctx, "list_things", "2025-01-01", nil, &resp)`))
+	is.True(strings.Contains(source, `return r.client.Do(ctx, "delete_thing", "2025-01-01", req, nil)`))
+	is.True(strings.Contains(source, `return r.client.Do(ctx, "ping", "preview", nil, nil)`))
+	is.True(strings.Contains(source, "WatchThings(ctx context.Context, req *WatchThingsRequest, send func(*WatchThingsEvent) error) error {"))
+	is.True(strings.Contains(source, `stream, err := r.client.DoStream(ctx, "watch_things", "2025-02-02", req)`))
+	is.True(!strings.Contains(source, "old_thing"))
+}
+
+func TestGenerateClientDisagreement(t *testing.T) {
+	is := is.New(t)
+
+	registrationsSource := strings.Replace(testRegistrationsSource, "rpc.DeleteThing", "rpc.DeleteThings", 1)
+	dir, registrationsPath := writeTestService(t, testServiceSource, registrationsSource)
+
+	svc, err := parseService(dir, "Service")
+	is.NoErr(err)
+
+	registrations, err := parseRegistrations(registrationsPath)
+	is.NoErr(err)
+
+	_, err = generateClient(svc, registrations)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "DeleteThing is on the interface but not registered on the server"))
+	is.True(strings.Contains(err.Error(), "DeleteThings is registered on the server as delete_thing but not on the interface"))
+}
@@ -0,0 +1,56 @@
+// Command crpcgen generates the RPC client of a service from its Service
+// interface and the methods registered on its crpc server.
+//
+// It is intended to be run through go generate from the service's root
+// package:
+//
+//	//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+//
+// Generation fails if a method on the interface isn't registered on the
+// server, or if a method registered on the server isn't on the interface, so
+// the two can't drift apart. The generated client also asserts that it
+// implements the interface, so an interface change that hasn't been
+// regenerated fails the build.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "crpcgen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("crpcgen", flag.ContinueOnError)
+	interfaceName := flags.String("interface", "Service", "name of the service interface")
+	registrations := flags.String("registrations", filepath.Join("internal", "transport", "rpc", "rpc.go"), "file the crpc methods are registered in")
+	output := flags.String("output", "rpcclient.go", "file to write the client to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	svc, err := parseService(".", *interfaceName)
+	if err != nil {
+		return err
+	}
+
+	registered, err := parseRegistrations(*registrations)
+	if err != nil {
+		return err
+	}
+
+	client, err := generateClient(svc, registered)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(*output, client, 0o644) //nolint:gosec // generated source files are world readable
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// service is a service interface, as declared in the service's root package.
+type service struct {
+	Package   string
+	Interface string
+	Imports   map[string]string
+	Methods   []*serviceMethod
+}
+
+type serviceMethod struct {
+	Name         string
+	RequestType  string
+	ResponseType string
+
+	// EventType is the type of each event sent by a streaming method, which
+	// accepts a send function as its last parameter.
+	EventType string
+
+	// Qualifiers are the package names referenced by the request and response
+	// types.
+	Qualifiers []string
+}
+
+// registration is a method registered on a crpc server.
+type registration struct {
+	Method  string
+	Version string
+}
+
+func parseService(dir, interfaceName string) (*service, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.Name, "_test") {
+			continue
+		}
+
+		for _, file := range pkg.Files {
+			iface := findInterface(file, interfaceName)
+			if iface == nil {
+				continue
+			}
+
+			svc := &service{
+				Package:   pkg.Name,
+				Interface: interfaceName,
+				Imports:   fileImports(file),
+			}
+
+			for _, field := range iface.Methods.List {
+				fn, ok := field.Type.(*ast.FuncType)
+				if !ok || len(field.Names) != 1 {
+					return nil, fmt.Errorf("%s: embedded interfaces are not supported", fset.Position(field.Pos()))
+				}
+
+				method, err := parseServiceMethod(fset, field.Names[0].Name, fn)
+				if err != nil {
+					return nil, err
+				}
+
+				svc.Methods = append(svc.Methods, method)
+			}
+
+			return svc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s not found in %s", interfaceName, dir)
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != name {
+				continue
+			}
+
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				return iface
+			}
+		}
+	}
+
+	return nil
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imports[name] = path
+	}
+
+	return imports
+}
+
+func parseServiceMethod(fset *token.FileSet, name string, fn *ast.FuncType) (*serviceMethod, error) {
+	pos := fset.Position(fn.Pos())
+	params := flattenFields(fn.Params)
+	results := flattenFields(fn.Results)
+
+	var send *ast.FuncType
+	if len(params) > 1 {
+		send, _ = params[len(params)-1].(*ast.FuncType)
+		if send != nil {
+			params = params[:len(params)-1]
+		}
+	}
+
+	if len(params) < 1 || len(params) > 2 {
+		return nil, fmt.Errorf("%s: %s must accept a context and an optional request", pos, name)
+	} else if len(results) < 1 || len(results) > 2 {
+		return nil, fmt.Errorf("%s: %s must return an optional response and an error", pos, name)
+	}
+
+	if typ := exprString(fset, params[0]); typ != "context.Context" {
+		return nil, fmt.Errorf("%s: %s must accept a context.Context first, not %s", pos, name, typ)
+	} else if typ := exprString(fset, results[len(results)-1]); typ != "error" {
+		return nil, fmt.Errorf("%s: %s must return an error last, not %s", pos, name, typ)
+	}
+
+	method := &serviceMethod{Name: name}
+
+	if send != nil {
+		sendParams := flattenFields(send.Params)
+		sendResults := flattenFields(send.Results)
+
+		if len(sendParams) != 1 || len(sendResults) != 1 || exprString(fset, sendResults[0]) != "error" {
+			return nil, fmt.Errorf("%s: %s must accept a send function of func(event *T) error", pos, name)
+		} else if len(results) != 1 {
+			return nil, fmt.Errorf("%s: %s streams events, so must only return an error", pos, name)
+		}
+
+		method.EventType = exprString(fset, sendParams[0])
+		method.Qualifiers = append(method.Qualifiers, exprQualifiers(sendParams[0])...)
+	}
+
+	if len(params) == 2 {
+		method.RequestType = exprString(fset, params[1])
+		method.Qualifiers = append(method.Qualifiers, exprQualifiers(params[1])...)
+	}
+
+	if len(results) == 2 {
+		method.ResponseType = exprString(fset, results[0])
+		method.Qualifiers = append(method.Qualifiers, exprQualifiers(results[0])...)
+	}
+
+	return method, nil
+}
+
+// flattenFields returns the type of each field, expanding fields which
+// declare several names of the same type.
+func flattenFields(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+
+	var types []ast.Expr
+	for _, field := range fields.List {
+		count := max(len(field.Names), 1)
+		for range count {
+			types = append(types, field.Type)
+		}
+	}
+
+	return types
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, fset, expr)
+
+	return buf.String()
+}
+
+func exprQualifiers(expr ast.Expr) []string {
+	var qualifiers []string
+
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				qualifiers = append(qualifiers, ident.Name)
+			}
+
+			return false
+		}
+
+		return true
+	})
+
+	return qualifiers
+}
+
+// parseRegistrations finds each call to Register in the file, and returns the
+// registration for each handler, keyed by the name of the handler's method. If
+// a handler is registered on several versions, the most recent dated version
+// is used, falling back to preview.
+func parseRegistrations(path string) (map[string]*registration, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := map[string][]*registration{}
+
+	var inspectErr error
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Register" || len(call.Args) < 4 {
+			return true
+		}
+
+		pos := fset.Position(call.Pos())
+
+		method, err := stringLiteral(call.Args[0])
+		if err != nil {
+			inspectErr = fmt.Errorf("%s: method name: %w", pos, err)
+			return false
+		}
+
+		version, err := stringLiteral(call.Args[1])
+		if err != nil {
+			inspectErr = fmt.Errorf("%s: version: %w", pos, err)
+			return false
+		}
+
+		// Withdrawn methods are registered with a nil handler, and have no
+		// client method
+		handler, ok := call.Args[3].(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		candidates[handler.Sel.Name] = append(candidates[handler.Sel.Name], &registration{
+			Method:  method,
+			Version: version,
+		})
+
+		return true
+	})
+	if inspectErr != nil {
+		return nil, inspectErr
+	}
+
+	registrations := make(map[string]*registration, len(candidates))
+	for handler, regs := range candidates {
+		sort.Slice(regs, func(i, j int) bool {
+			return versionRank(regs[i].Version) < versionRank(regs[j].Version)
+		})
+
+		registrations[handler] = regs[len(regs)-1]
+	}
+
+	return registrations, nil
+}
+
+func versionRank(version string) string {
+	if version == "preview" {
+		// Sorts before any dated version
+		return "0"
+	}
+
+	return version
+}
+
+func stringLiteral(expr ast.Expr) (string, error) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", fmt.Errorf("must be a string literal")
+	}
+
+	return strconv.Unquote(lit.Value)
+}
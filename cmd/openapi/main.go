@@ -0,0 +1,104 @@
+// Command openapi emits an OpenAPI 3.1 document describing the RPC methods of
+// running services, built from each service's crpc discovery endpoint.
+//
+// Services are given as name=url pairs, where url is the base URL of the RPC
+// transport. When none are given, the default local addresses of every
+// service are used.
+//
+//	go run ./cmd/openapi user=http://localhost:4001/rpc > openapi.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/crpc"
+)
+
+type service struct {
+	Name    string
+	BaseURL string
+}
+
+var defaultServices = []service{
+	{"user", "http://localhost:4001/rpc"},
+	{"conversation", "http://localhost:4002/rpc"},
+	{"ai_relay", "http://localhost:4003/rpc"},
+	{"stream", "http://localhost:4004/rpc"},
+	{"file_upload", "http://localhost:4005/rpc"},
+	{"skill_set", "http://localhost:4006/rpc"},
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	services := defaultServices
+	if len(args) > 0 {
+		services = make([]service, len(args))
+
+		for i, arg := range args {
+			name, baseURL, ok := strings.Cut(arg, "=")
+			if !ok || name == "" || baseURL == "" {
+				return fmt.Errorf("invalid service %q, expected name=url", arg)
+			}
+
+			services[i] = service{name, strings.TrimSuffix(baseURL, "/")}
+		}
+	}
+
+	doc := crpc.NewOpenAPIDocument("Bloefish", "latest")
+
+	for _, svc := range services {
+		desc, err := describe(ctx, svc.BaseURL)
+		if err != nil {
+			return fmt.Errorf("failed to describe %s: %w", svc.Name, err)
+		}
+
+		if err := doc.AddService(svc.Name, svc.BaseURL, desc); err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(doc)
+}
+
+func describe(ctx context.Context, baseURL string) (*crpc.Description, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+crpc.DescribePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var desc crpc.Description
+	if err := json.NewDecoder(res.Body).Decode(&desc); err != nil {
+		return nil, err
+	}
+
+	return &desc, nil
+}
@@ -3,9 +3,14 @@ package config
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type MongoDB struct {
@@ -14,7 +19,12 @@ type MongoDB struct {
 }
 
 func (m MongoDB) MustConnect(ctx context.Context) (*mongo.Client, *mongo.Database) {
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(m.URI))
+	monitor, err := newMongoCommandMonitor()
+	if err != nil {
+		panic(fmt.Errorf("failed to create mongodb command monitor: %w", err))
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(m.URI).SetMonitor(monitor))
 	if err != nil {
 		panic(fmt.Errorf("failed to connect to mongodb: %w", err))
 	}
@@ -25,3 +35,64 @@ func (m MongoDB) MustConnect(ctx context.Context) (*mongo.Client, *mongo.Databas
 
 	return client, client.Database(m.DatabaseName)
 }
+
+// mongoCommandMonitor records how long each command sent to MongoDB takes, by
+// database, collection and command.
+type mongoCommandMonitor struct {
+	duration metric.Float64Histogram
+
+	// collections holds the collection of each command in flight, keyed by
+	// request ID, as it's only part of the started event
+	collections sync.Map
+}
+
+func newMongoCommandMonitor() (*event.CommandMonitor, error) {
+	meter := otel.Meter("github.com/0xdeafcafe/bloefish/libraries/config")
+
+	duration, err := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of MongoDB commands"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mongoCommandMonitor{duration: duration}
+
+	return &event.CommandMonitor{
+		Started: m.started,
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.finished(ctx, &evt.CommandFinishedEvent, "ok")
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.finished(ctx, &evt.CommandFinishedEvent, "error")
+		},
+	}, nil
+}
+
+func (m *mongoCommandMonitor) started(_ context.Context, evt *event.CommandStartedEvent) {
+	// Most commands are keyed by the collection they run against, but getMore
+	// is keyed by its cursor
+	value := evt.Command.Lookup(evt.CommandName)
+	if evt.CommandName == "getMore" {
+		value = evt.Command.Lookup("collection")
+	}
+
+	collection, _ := value.StringValueOK()
+	m.collections.Store(evt.RequestID, collection)
+}
+
+func (m *mongoCommandMonitor) finished(ctx context.Context, evt *event.CommandFinishedEvent, status string) {
+	collection, _ := m.collections.LoadAndDelete(evt.RequestID)
+	collectionName, _ := collection.(string)
+
+	m.duration.Record(ctx, evt.Duration.Seconds(), metric.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.namespace", evt.DatabaseName),
+		attribute.String("db.collection.name", collectionName),
+		attribute.String("db.operation.name", evt.CommandName),
+		attribute.String("db.response.status", status),
+	))
+}
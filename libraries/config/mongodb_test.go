@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMongoCommandMonitor(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(ctx)
+
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(provider)
+	defer otel.SetMeterProvider(previous)
+
+	monitor, err := newMongoCommandMonitor()
+	is.NoErr(err)
+
+	commands := []struct {
+		Name    string
+		Command bson.D
+		Failed  bool
+	}{
+		{"find", bson.D{{Key: "find", Value: "files"}}, false},
+		{"getMore", bson.D{{Key: "getMore", Value: int64(1)}, {Key: "collection", Value: "files"}}, false},
+		{"update", bson.D{{Key: "update", Value: "blobs"}}, true},
+	}
+
+	for i, command := range commands {
+		raw, err := bson.Marshal(command.Command)
+		is.NoErr(err)
+
+		monitor.Started(ctx, &event.CommandStartedEvent{
+			Command:      raw,
+			DatabaseName: "bloefish",
+			CommandName:  command.Name,
+			RequestID:    int64(i),
+		})
+
+		finished := event.CommandFinishedEvent{
+			DatabaseName: "bloefish",
+			CommandName:  command.Name,
+			RequestID:    int64(i),
+			Duration:     time.Millisecond,
+		}
+		if command.Failed {
+			monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished})
+		} else {
+			monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	is.NoErr(reader.Collect(ctx, &rm))
+	is.Equal(len(rm.ScopeMetrics), 1)
+	is.Equal(len(rm.ScopeMetrics[0].Metrics), 1)
+
+	histogram, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	is.True(ok)
+
+	operations := map[string]string{}
+	for _, point := range histogram.DataPoints {
+		operation, _ := point.Attributes.Value(attribute.Key("db.operation.name"))
+		collection, _ := point.Attributes.Value(attribute.Key("db.collection.name"))
+		status, _ := point.Attributes.Value(attribute.Key("db.response.status"))
+		operations[operation.AsString()] = collection.AsString() + "/" + status.AsString()
+	}
+
+	is.Equal(operations, map[string]string{
+		"find":    "files/ok",
+		"getMore": "files/ok",
+		"update":  "blobs/error",
+	})
+}
@@ -2,9 +2,11 @@ package crpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/0xdeafcafe/bloefish/libraries/contexts"
 	"github.com/0xdeafcafe/bloefish/libraries/errfuncs"
@@ -60,6 +62,36 @@ func (c *Client) Do(ctx context.Context, method, version string, src, dst any, r
 	return err
 }
 
+// DoStream executes a streaming RPC request against the configured server, and
+// returns the stream of events once the server has started responding. Errors
+// returned before the stream starts are returned here, later errors are
+// returned by the stream. The stream must be closed.
+func (c *Client) DoStream(ctx context.Context, method, version string, src any, requestModifiers ...func(r *http.Request)) (*Stream, error) {
+	headers := http.Header{"Accept": []string{ContentTypeNDJSON}}
+
+	res, err := c.client.Stream(ctx, "POST", path.Join(version, method), headers, src, requestModifiers...)
+	if err != nil {
+		if err, ok := errfuncs.As[jsonclient.ClientTransportError](err); ok {
+			return nil, ClientTransportError{method, version, err.ErrorString, err.Cause()}
+		}
+
+		return nil, err
+	}
+
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, ContentTypeNDJSON) {
+		res.Body.Close()
+
+		return nil, ClientTransportError{method, version, fmt.Sprintf("unexpected content type %q", contentType), nil}
+	}
+
+	return &Stream{
+		method:  method,
+		version: version,
+		body:    res.Body,
+		decoder: json.NewDecoder(res.Body),
+	}, nil
+}
+
 // ClientTransportError is returned when an error related to
 // executing a client request occurs.
 type ClientTransportError struct {
@@ -0,0 +1,146 @@
+package crpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// DescribePath is the path of the discovery endpoint, relative to where the
+// server is mounted. A GET request to it returns the server's Description.
+const DescribePath = "/_describe"
+
+// Description is a machine-readable description of every method a server
+// exposes, on every version it can be called with.
+type Description struct {
+	Versions []*VersionDescription `json:"versions"`
+}
+
+// VersionDescription describes the methods callable on a version.
+type VersionDescription struct {
+	Version string               `json:"version"`
+	Methods []*MethodDescription `json:"methods"`
+}
+
+// MethodDescription describes a method. RegisteredVersion is the version the
+// method was registered on, which may be earlier than the version it is being
+// called with.
+type MethodDescription struct {
+	Name              string `json:"name"`
+	RegisteredVersion string `json:"registered_version"`
+
+	// RequestSchema is the JSON schema used to validate requests, or nil if the
+	// method doesn't accept a request body.
+	RequestSchema any `json:"request_schema"`
+
+	// ResponseSchema is a JSON schema reflected from the response type. It is
+	// nil if the method doesn't return a response body, or if the method was
+	// registered as a HandlerFunc and the response type is unknown.
+	ResponseSchema any `json:"response_schema"`
+
+	// Streaming is true if the method streams events back to the client, in
+	// which case ResponseSchema describes each event.
+	Streaming bool `json:"streaming"`
+}
+
+type methodDescription struct {
+	requestSchema any
+	responseType  reflect.Type
+	streaming     bool
+}
+
+func newMethodDescription(schema gojsonschema.JSONLoader, wrapped *WrappedFunc) (*methodDescription, error) {
+	description := &methodDescription{}
+
+	if wrapped != nil {
+		description.responseType = wrapped.ResponseType
+		description.streaming = wrapped.HasStreamOutput
+	}
+
+	if schema != nil {
+		requestSchema, err := schema.LoadJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		description.requestSchema = requestSchema
+	}
+
+	return description, nil
+}
+
+// Describe returns a description of the server built from the registered
+// methods. The latest version is omitted, as it is an alias of the most recent
+// dated version.
+func (s *Server) Describe() *Description {
+	versions := make([]string, 0, len(s.resolvedMethods))
+	for version := range s.resolvedMethods {
+		if version == VersionLatest {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Strings(versions)
+
+	desc := &Description{
+		Versions: make([]*VersionDescription, 0, len(versions)),
+	}
+
+	for _, version := range versions {
+		methodSet := s.resolvedMethods[version]
+
+		methods := make([]string, 0, len(methodSet))
+		for method := range methodSet {
+			methods = append(methods, method)
+		}
+
+		sort.Strings(methods)
+
+		versionDesc := &VersionDescription{
+			Version: version,
+			Methods: make([]*MethodDescription, 0, len(methods)),
+		}
+
+		for _, method := range methods {
+			handler := methodSet[method]
+
+			methodDesc := &MethodDescription{
+				Name:              method,
+				RegisteredVersion: handler.v,
+			}
+
+			if handler.description != nil {
+				methodDesc.RequestSchema = handler.description.requestSchema
+				methodDesc.Streaming = handler.description.streaming
+
+				if handler.description.responseType != nil {
+					methodDesc.ResponseSchema = ReflectJSONSchema(handler.description.responseType)
+				}
+			}
+
+			versionDesc.Methods = append(versionDesc.Methods, methodDesc)
+		}
+
+		desc.Versions = append(desc.Versions, versionDesc)
+	}
+
+	return desc
+}
+
+func (s *Server) serveDescribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s.Describe())
+}
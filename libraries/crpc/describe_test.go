@@ -0,0 +1,159 @@
+package crpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type describeTestRequest struct {
+	Name string `json:"name"`
+}
+
+type describeTestResponse struct {
+	ID        string     `json:"id"`
+	Tags      []string   `json:"tags"`
+	Count     int        `json:"count,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Ignored   string     `json:"-"`
+
+	describeTestEmbedded
+
+	Children []*describeTestResponse `json:"children"`
+}
+
+type describeTestEmbedded struct {
+	Embedded bool `json:"embedded"`
+}
+
+func newDescribeTestServer() *Server {
+	schema := gojsonschema.NewStringLoader(`{"type": "object"}`)
+
+	rpc := NewServer(UnsafeNoAuthentication)
+	rpc.Register("create_thing", "2019-01-01", schema, func(context.Context, *describeTestRequest) (*describeTestResponse, error) {
+		return nil, nil
+	})
+	rpc.Register("delete_thing", "2019-01-01", schema, func(context.Context, *describeTestRequest) error {
+		return nil
+	})
+	rpc.Register("delete_thing", "2019-02-02", nil, nil)
+	rpc.Register("list_things", "2019-02-02", nil, func(context.Context) (*describeTestResponse, error) {
+		return nil, nil
+	})
+	rpc.Register("try_thing", "preview", nil, func(context.Context) error {
+		return nil
+	})
+
+	return rpc
+}
+
+func TestDescribe(t *testing.T) {
+	is := is.New(t)
+
+	desc := newDescribeTestServer().Describe()
+
+	matrix := map[string][]string{}
+	registeredVersions := map[string]string{}
+	for _, version := range desc.Versions {
+		for _, method := range version.Methods {
+			matrix[version.Version] = append(matrix[version.Version], method.Name)
+			registeredVersions[version.Version+"/"+method.Name] = method.RegisteredVersion
+		}
+	}
+
+	is.Equal(map[string][]string{
+		"2019-01-01": {"create_thing", "delete_thing"},
+		"2019-02-02": {"create_thing", "list_things"},
+		"preview":    {"try_thing"},
+	}, matrix)
+	is.Equal("2019-01-01", registeredVersions["2019-02-02/create_thing"])
+	is.Equal("2019-02-02", registeredVersions["2019-02-02/list_things"])
+
+	createThing := desc.Versions[0].Methods[0]
+	is.Equal(map[string]any{"type": "object"}, createThing.RequestSchema)
+	is.True(createThing.ResponseSchema != nil)
+
+	deleteThing := desc.Versions[0].Methods[1]
+	is.True(deleteThing.RequestSchema != nil)
+	is.Equal(nil, deleteThing.ResponseSchema)
+}
+
+func TestDescribeEndpoint(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	rpc := newDescribeTestServer()
+
+	rec := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, DescribePath, nil)
+	rpc.ServeHTTP(rec, r)
+
+	is.Equal(http.StatusOK, rec.Code)
+
+	var desc Description
+	is.NoErr(json.NewDecoder(rec.Body).Decode(&desc))
+	is.Equal(3, len(desc.Versions))
+
+	rec = httptest.NewRecorder()
+	r, _ = http.NewRequestWithContext(ctx, http.MethodPost, DescribePath, nil)
+	rpc.ServeHTTP(rec, r)
+
+	is.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestReflectJSONSchema(t *testing.T) {
+	is := is.New(t)
+
+	schema := ReflectJSONSchema(reflect.TypeOf(describeTestResponse{}))
+
+	is.Equal("object", schema["type"])
+	is.Equal([]string{"id", "tags", "created_at", "deleted_at", "embedded", "children"}, schema["required"])
+
+	properties := schema["properties"].(map[string]any) //nolint:forcetypeassert // required for test
+	is.Equal(map[string]any{"type": "string"}, properties["id"])
+	is.Equal(map[string]any{"type": []string{"array", "null"}, "items": map[string]any{"type": "string"}}, properties["tags"])
+	is.Equal(map[string]any{"type": "integer"}, properties["count"])
+	is.Equal(map[string]any{"type": "string", "format": "date-time"}, properties["created_at"])
+	is.Equal(map[string]any{"type": []string{"string", "null"}, "format": "date-time"}, properties["deleted_at"])
+	is.Equal(map[string]any{"type": "boolean"}, properties["embedded"])
+	is.Equal(map[string]any{"type": []string{"array", "null"}, "items": map[string]any{}}, properties["children"])
+
+	_, ok := properties["Ignored"]
+	is.True(!ok)
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	is := is.New(t)
+
+	desc := newDescribeTestServer().Describe()
+
+	doc := NewOpenAPIDocument("Test", "latest")
+	is.NoErr(doc.AddService("things", "http://localhost/rpc", desc))
+
+	is.Equal(5, len(doc.Paths))
+
+	createThing := doc.Paths["/2019-01-01/create_thing"]
+	is.Equal("http://localhost/rpc", createThing.Servers[0].URL)
+	is.Equal([]string{"things"}, createThing.Post.Tags)
+	is.True(createThing.Post.RequestBody != nil)
+	is.True(createThing.Post.Responses["200"] != nil)
+	is.True(createThing.Post.Responses["default"] != nil)
+
+	deleteThing := doc.Paths["/2019-01-01/delete_thing"]
+	is.True(deleteThing.Post.Responses["204"] != nil)
+
+	listThings := doc.Paths["/2019-02-02/list_things"]
+	is.Equal(nil, listThings.Post.RequestBody)
+
+	is.True(doc.Paths["/preview/try_thing"].Post.Deprecated)
+
+	err := doc.AddService("more_things", "http://localhost/rpc", desc)
+	is.True(err != nil)
+}
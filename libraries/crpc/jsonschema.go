@@ -0,0 +1,166 @@
+package crpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// ReflectJSONSchema returns a JSON schema describing how encoding/json
+// marshals values of the given type. Recursive types are described as far as
+// the first repetition, after which any value is allowed.
+func ReflectJSONSchema(typ reflect.Type) map[string]any {
+	return reflectJSONSchema(typ, map[reflect.Type]bool{})
+}
+
+func reflectJSONSchema(typ reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	switch typ {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch typ.Kind() { //nolint:exhaustive // unsupported kinds allow any value
+	case reflect.Ptr:
+		return nullable(reflectJSONSchema(typ.Elem(), visiting))
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+
+	case reflect.String:
+		return map[string]any{"type": "string"}
+
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+
+		schema := map[string]any{
+			"type":  "array",
+			"items": reflectJSONSchema(typ.Elem(), visiting),
+		}
+		if typ.Kind() == reflect.Slice {
+			return nullable(schema)
+		}
+
+		return schema
+
+	case reflect.Map:
+		return nullable(map[string]any{
+			"type":                 "object",
+			"additionalProperties": reflectJSONSchema(typ.Elem(), visiting),
+		})
+
+	case reflect.Struct:
+		if visiting[typ] {
+			return map[string]any{}
+		}
+
+		visiting[typ] = true
+		defer delete(visiting, typ)
+
+		properties := map[string]any{}
+		required := []string{}
+		reflectStructFields(typ, visiting, properties, &required)
+
+		schema := map[string]any{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		return schema
+
+	default:
+		return map[string]any{}
+	}
+}
+
+func reflectStructFields(typ reflect.Type, visiting map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs have their fields promoted
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				reflectStructFields(fieldType, visiting, properties, required)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := reflectJSONSchema(field.Type, visiting)
+		if hasTagOption(opts, "string") {
+			schema = map[string]any{"type": "string"}
+		}
+
+		properties[name] = schema
+
+		if !hasTagOption(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+
+		if opt == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nullable allows null in addition to the schema's type. Schemas which allow
+// any value are left alone.
+func nullable(schema map[string]any) map[string]any {
+	typ, ok := schema["type"].(string)
+	if !ok {
+		return schema
+	}
+
+	schema["type"] = []string{typ, "null"}
+
+	return schema
+}
@@ -17,6 +17,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/slicefuncs"
 
 	"github.com/xeipuuv/gojsonschema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Logger inherits the context logger and reports RPC request success/failure.
@@ -61,6 +64,58 @@ func Logger() MiddlewareFunc {
 	}
 }
 
+// Metrics records the number and duration of RPC requests, by method, version
+// and status. The status is "ok", or the code of the error the request failed
+// with. Metrics should be used before Logger, so it sees the errors Logger
+// rewrites.
+func Metrics() MiddlewareFunc {
+	meter := otel.Meter("github.com/0xdeafcafe/bloefish/libraries/crpc")
+
+	requests, err := meter.Int64Counter(
+		"rpc.server.requests",
+		metric.WithDescription("Number of RPC requests handled"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	duration, err := meter.Float64Histogram(
+		"rpc.server.duration",
+		metric.WithDescription("Duration of RPC requests, including the whole stream for streamed responses"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(res http.ResponseWriter, req *Request) error {
+			ctx := req.Context()
+
+			tStart := time.Now()
+			err := next(res, req)
+			elapsed := time.Since(tStart)
+
+			status := "ok"
+			if err != nil {
+				status = coerceError(err).Code
+			}
+
+			attributes := metric.WithAttributes(
+				attribute.String("rpc.method", req.Method),
+				attribute.String("rpc.version", req.Version),
+				attribute.String("rpc.status", status),
+			)
+
+			requests.Add(ctx, 1, attributes)
+			duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), attributes)
+
+			return err
+		}
+	}
+}
+
 // Validate buffers the JSON body and applies a JSON Schema validation.
 func Validate(schema *gojsonschema.Schema) MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
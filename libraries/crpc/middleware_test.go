@@ -0,0 +1,66 @@
+package crpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/matryer/is"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetrics(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(ctx)
+
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(provider)
+	defer otel.SetMeterProvider(previous)
+
+	rpc := NewServer(UnsafeNoAuthentication)
+	rpc.Use(Metrics())
+	rpc.Register("foo", "preview", nil, makeRPCCall("called foo!"))
+	rpc.Register("bar", "preview", nil, func(_ context.Context) error {
+		return cher.New("bar_failed", nil)
+	})
+
+	for _, path := range []string{"/preview/foo", "/preview/foo", "/preview/bar"} {
+		r, _ := http.NewRequestWithContext(ctx, http.MethodPost, path, nil)
+		rpc.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	var rm metricdata.ResourceMetrics
+	is.NoErr(reader.Collect(ctx, &rm))
+	is.Equal(len(rm.ScopeMetrics), 1)
+
+	counts := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			is.Equal(m.Name, "rpc.server.requests")
+
+			for _, point := range data.DataPoints {
+				method, _ := point.Attributes.Value(attribute.Key("rpc.method"))
+				status, _ := point.Attributes.Value(attribute.Key("rpc.status"))
+				counts[method.AsString()+"/"+status.AsString()] = point.Value
+			}
+		case metricdata.Histogram[float64]:
+			is.Equal(m.Name, "rpc.server.duration")
+			is.Equal(len(data.DataPoints), 2)
+		}
+	}
+
+	is.Equal(counts, map[string]int64{
+		"foo/ok":         2,
+		"bar/bar_failed": 1,
+	})
+}
@@ -47,6 +47,12 @@ func (rw *responseWriter) Write(bytes []byte) (int, error) {
 	return rw.ResponseWriter.Write(bytes)
 }
 
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController can
+// flush streamed responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Telemetry returns a middleware handler that wraps subsequent middleware/handlers and logs
 // request information AFTER the request has completed. It also injects a request-scoped
 // logger on the context which can be set, read and updated using clog lib
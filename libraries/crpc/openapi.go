@@ -0,0 +1,160 @@
+package crpc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification documents are
+// generated for.
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIDocument is the subset of an OpenAPI document needed to describe crpc
+// servers.
+type OpenAPIDocument struct {
+	OpenAPI string                      `json:"openapi"`
+	Info    OpenAPIInfo                 `json:"info"`
+	Tags    []*OpenAPITag               `json:"tags,omitempty"`
+	Paths   map[string]*OpenAPIPathItem `json:"paths"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPITag struct {
+	Name string `json:"name"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIPathItem struct {
+	Servers []*OpenAPIServer  `json:"servers,omitempty"`
+	Post    *OpenAPIOperation `json:"post"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema any `json:"schema"`
+}
+
+const openAPIJSONContentType = "application/json"
+
+// NewOpenAPIDocument returns an empty OpenAPI document, ready to have services
+// added to it.
+func NewOpenAPIDocument(title, version string) *OpenAPIDocument {
+	return &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:   title,
+			Version: version,
+		},
+		Paths: map[string]*OpenAPIPathItem{},
+	}
+}
+
+// AddService adds every method in the description to the document, under a tag
+// named after the service. baseURL is where the server is mounted, and each
+// method is added as a path of `/<version>/<method>` relative to it.
+func (d *OpenAPIDocument) AddService(name, baseURL string, desc *Description) error {
+	d.Tags = append(d.Tags, &OpenAPITag{Name: name})
+
+	errorSchema := ReflectJSONSchema(reflect.TypeOf(cher.E{}))
+
+	for _, version := range desc.Versions {
+		for _, method := range version.Methods {
+			path := fmt.Sprintf("/%s/%s", version.Version, method.Name)
+			if _, ok := d.Paths[path]; ok {
+				return fmt.Errorf("path %s is defined by more than one service", path)
+			}
+
+			operation := &OpenAPIOperation{
+				OperationID: fmt.Sprintf("%s.%s.%s", name, strings.ReplaceAll(version.Version, "-", ""), method.Name),
+				Tags:        []string{name},
+				Deprecated:  version.Version == VersionPreview,
+				Responses: map[string]*OpenAPIResponse{
+					"default": {
+						Description: "Error",
+						Content: map[string]*OpenAPIMediaType{
+							openAPIJSONContentType: {Schema: errorSchema},
+						},
+					},
+				},
+			}
+
+			if version.Version == VersionPreview {
+				operation.Description = "This method is experimental and may change or be withdrawn without notice."
+			} else if method.RegisteredVersion != version.Version {
+				operation.Description = fmt.Sprintf("Unchanged since version %s.", method.RegisteredVersion)
+			}
+
+			if method.RequestSchema != nil {
+				operation.RequestBody = &OpenAPIRequestBody{
+					Required: true,
+					Content: map[string]*OpenAPIMediaType{
+						openAPIJSONContentType: {Schema: method.RequestSchema},
+					},
+				}
+			}
+
+			if method.Streaming {
+				operation.Responses["200"] = &OpenAPIResponse{
+					Description: "Stream of events, ended by a done or error frame",
+					Content: map[string]*OpenAPIMediaType{
+						ContentTypeNDJSON: {Schema: map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"event": method.ResponseSchema,
+								"error": errorSchema,
+								"done":  map[string]any{"type": "boolean"},
+							},
+						}},
+						ContentTypeEventStream: {Schema: map[string]any{"type": "string"}},
+					},
+				}
+			} else if method.ResponseSchema != nil {
+				operation.Responses["200"] = &OpenAPIResponse{
+					Description: "Success",
+					Content: map[string]*OpenAPIMediaType{
+						openAPIJSONContentType: {Schema: method.ResponseSchema},
+					},
+				}
+			} else {
+				operation.Responses["204"] = &OpenAPIResponse{
+					Description: "Success",
+				}
+			}
+
+			d.Paths[path] = &OpenAPIPathItem{
+				Servers: []*OpenAPIServer{{URL: baseURL}},
+				Post:    operation,
+			}
+		}
+	}
+
+	return nil
+}
@@ -35,6 +35,10 @@ type Request struct {
 	BrowserOrigin string
 
 	originalRequest *http.Request
+
+	// stream is set once a streaming handler begins, so errors returned after
+	// the response has started can be written to the stream instead.
+	stream *streamWriter
 }
 
 func (r *Request) Context() context.Context {
@@ -71,6 +75,13 @@ type WrappedFunc struct {
 	Handler           HandlerFunc
 	HasRequestInput   bool
 	HasResponseOutput bool
+	HasStreamOutput   bool
+
+	// RequestType and ResponseType are the underlying types of the handler's
+	// request and response, if it has them. For streaming handlers,
+	// ResponseType is the type of each event.
+	RequestType  reflect.Type
+	ResponseType reflect.Type
 }
 
 var (
@@ -85,6 +96,12 @@ var (
 // func(ctx context.Context, request *T) (err error)
 // func(ctx context.Context) (response *T, err error)
 // func(ctx context.Context) (err error)
+//
+// Streaming handlers are passed a send function, which writes each event to
+// the response as it happens:
+//
+// func(ctx context.Context, request *T, send func(event *T) error) (err error)
+// func(ctx context.Context, send func(event *T) error) (err error)
 func Wrap(fn any) (*WrappedFunc, error) {
 	ctx := context.Background()
 
@@ -105,6 +122,25 @@ func Wrap(fn any) (*WrappedFunc, error) {
 
 	inputCount := fnType.NumIn()
 	outputCount := fnType.NumOut()
+
+	// the send function is always the last input, leave it out of the count so
+	// the request is handled the same way as a non-streaming handler
+	var sendType, eventType reflect.Type
+	if inputCount > 1 && fnType.In(inputCount-1).Kind() == reflect.Func {
+		sendType = fnType.In(inputCount - 1)
+		inputCount--
+
+		var err error
+		eventType, err = checkSendType(ctx, sendType)
+		if err != nil {
+			return nil, err
+		}
+
+		if outputCount != 1 {
+			return nil, merr.New(ctx, "fn_streaming_output_params_invalid", merr.M{"count": outputCount})
+		}
+	}
+
 	if inputCount < 1 || inputCount > 2 {
 		return nil, merr.New(ctx, "fn_input_params_invalid", merr.M{"count": inputCount})
 	} else if outputCount < 1 || outputCount > 2 {
@@ -121,7 +157,7 @@ func Wrap(fn any) (*WrappedFunc, error) {
 
 	// resolve function parameter pointers to underlying type for use with
 	// reflect.New (which will return pointers).
-	var reqType reflect.Type
+	var reqType, resType reflect.Type
 	var hasResponseOutput bool
 
 	if inputCount == 2 {
@@ -139,7 +175,9 @@ func Wrap(fn any) (*WrappedFunc, error) {
 	if outputCount == 2 {
 		hasResponseOutput = true
 
-		err := checkResponseType(ctx, fnType.Out(0))
+		resType = fnType.Out(0)
+
+		err := checkResponseType(ctx, resType)
 		if err != nil {
 			return nil, err
 		}
@@ -174,13 +212,28 @@ func Wrap(fn any) (*WrappedFunc, error) {
 			inputs = []reflect.Value{ctxVal, reqVal}
 		}
 
+		var stream *streamWriter
+		if sendType != nil {
+			stream = newStreamWriter(w, req)
+			inputs = append(inputs, reflect.MakeFunc(sendType, func(args []reflect.Value) []reflect.Value {
+				errVal := reflect.New(errorType).Elem()
+				if err := stream.Send(args[0].Interface()); err != nil {
+					errVal.Set(reflect.ValueOf(err))
+				}
+
+				return []reflect.Value{errVal}
+			}))
+		}
+
 		res := fnValue.Call(inputs)
 
 		if errVal := res[len(res)-1]; !errVal.IsNil() {
 			return errVal.Interface().(error) //nolint:forcetypeassert // we checked the type above
 		}
 
-		if len(res) == 1 {
+		if stream != nil {
+			return stream.Close()
+		} else if len(res) == 1 {
 			w.WriteHeader(http.StatusNoContent)
 		} else if len(res) == 2 {
 			enc := json.NewEncoder(w)
@@ -198,13 +251,35 @@ func Wrap(fn any) (*WrappedFunc, error) {
 		return nil
 	}
 
+	if eventType != nil {
+		resType = eventType
+	}
+
 	return &WrappedFunc{
 		Handler:           handler,
 		HasRequestInput:   reqType != nil,
 		HasResponseOutput: hasResponseOutput,
+		HasStreamOutput:   sendType != nil,
+		RequestType:       reqType,
+		ResponseType:      resType,
 	}, nil
 }
 
+// checkSendType ensures a streaming handler's send function matches
+// func(event *T) error, and returns the event type.
+func checkSendType(ctx context.Context, typ reflect.Type) (reflect.Type, error) {
+	if typ.NumIn() != 1 || typ.NumOut() != 1 || typ.Out(0) != errorType {
+		return nil, merr.New(ctx, "fn_send_invalid", merr.M{"type": typ})
+	}
+
+	eventType := typ.In(0)
+	if eventType.Kind() != reflect.Ptr || eventType.Elem().Kind() != reflect.Struct {
+		return nil, merr.New(ctx, "fn_send_event_not_struct_pointer", merr.M{"type": eventType})
+	}
+
+	return eventType, nil
+}
+
 func checkResponseType(ctx context.Context, typ reflect.Type) error {
 	switch typ.Kind() { //nolint:exhaustive // we only accept a few types
 	case reflect.Ptr:
@@ -250,6 +325,10 @@ func MustWrap(fn any) *WrappedFunc {
 type wrappedHandler struct {
 	v  string
 	fn HandlerFunc
+
+	// description is used to describe the method through the discovery
+	// endpoint
+	description *methodDescription
 }
 
 // Server is an HTTP-compatible crpc handler.
@@ -325,13 +404,17 @@ func (s *Server) Register(method, version string, schema gojsonschema.JSONLoader
 		}
 	}
 
-	s.RegisterFunc(method, version, schema, &wrapped.Handler, middleware...)
+	s.registerFunc(method, version, schema, &wrapped.Handler, wrapped, middleware...)
 }
 
 // RegisterFunc associates a method name and version with a HandlerFunc,
 // and optional middleware. This function is not thread safe and must be run in
 // serial if called multiple times.
 func (s *Server) RegisterFunc(method, version string, schema gojsonschema.JSONLoader, fn *HandlerFunc, middleware ...MiddlewareFunc) {
+	s.registerFunc(method, version, schema, fn, nil, middleware...)
+}
+
+func (s *Server) registerFunc(method, version string, schema gojsonschema.JSONLoader, fn *HandlerFunc, wrapped *WrappedFunc, middleware ...MiddlewareFunc) {
 	if s.registeredVersionMethods == nil {
 		s.registeredVersionMethods = make(map[string]map[string]*wrappedHandler)
 	}
@@ -361,6 +444,11 @@ func (s *Server) RegisterFunc(method, version string, schema gojsonschema.JSONLo
 	if fn == nil {
 		s.setRoute(version, method, nil)
 	} else {
+		description, err := newMethodDescription(schema, wrapped)
+		if err != nil {
+			panic(fmt.Sprintf("failed to describe %s: %s", method, err))
+		}
+
 		if schema != nil {
 			compiledSchema, err := gojsonschema.NewSchemaLoader().Compile(schema)
 			if err != nil {
@@ -383,7 +471,11 @@ func (s *Server) RegisterFunc(method, version string, schema gojsonschema.JSONLo
 			fn = &p
 		}
 
-		s.setRoute(version, method, &wrappedHandler{version, *fn})
+		s.setRoute(version, method, &wrappedHandler{
+			v:           version,
+			fn:          *fn,
+			description: description,
+		})
 	}
 
 	s.buildRoutes()
@@ -554,6 +646,11 @@ func appendInfraEndpointStatus(w http.ResponseWriter, requestedVersion, resolved
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if r.URL.Path == DescribePath {
+		s.serveDescribe(w, r)
+		return
+	}
+
 	if strings.ToUpper(r.Method) != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -584,7 +681,16 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeError(ctx, w, s.Serve(w, req))
+	err := s.Serve(w, req)
+
+	// once a stream has started the status code has been sent, so the error
+	// can only be sent as the final event
+	if req.stream != nil && req.stream.Started() {
+		req.stream.CloseWithError(ctx, err)
+		return
+	}
+
+	s.writeError(ctx, w, err)
 }
 
 // expRequestPath only matches HTTP Paths formed of /<version date>/<method name>
@@ -607,6 +713,19 @@ func (s *Server) writeError(ctx context.Context, w http.ResponseWriter, err erro
 		return
 	}
 
+	body := coerceError(err)
+
+	w.WriteHeader(body.StatusCode())
+
+	werr := json.NewEncoder(w).Encode(body)
+	if werr != nil {
+		mlog.Warn(ctx, merr.New(ctx, "crpc_write_error_failed", nil, werr))
+	}
+}
+
+// coerceError converts an error returned by a handler into the body sent to
+// the client. Errors which aren't safe to expose become unknown errors.
+func coerceError(err error) cher.E {
 	var body cher.E
 
 	if err, ok := errfuncs.As[cher.E](err); ok {
@@ -632,10 +751,5 @@ func (s *Server) writeError(ctx context.Context, w http.ResponseWriter, err erro
 		body = cher.New(cher.Unknown, nil)
 	}
 
-	w.WriteHeader(body.StatusCode())
-
-	werr := json.NewEncoder(w).Encode(body)
-	if werr != nil {
-		mlog.Warn(ctx, merr.New(ctx, "crpc_write_error_failed", nil, werr))
-	}
+	return body
 }
@@ -70,6 +70,18 @@ func TestWrap(t *testing.T) {
 			"OutputNotPointer", func(ctx context.Context) (out testOutput, err error) { return },
 			"response_type_invalid",
 		},
+		{
+			"SendInvalid", func(ctx context.Context, send func(*testOutput)) error { return nil },
+			"fn_send_invalid",
+		},
+		{
+			"SendEventNotPointer", func(ctx context.Context, send func(testOutput) error) error { return nil },
+			"fn_send_event_not_struct_pointer",
+		},
+		{
+			"StreamingWithOutput", func(ctx context.Context, send func(*testOutput) error) (*testOutput, error) { return nil, nil },
+			"fn_streaming_output_params_invalid",
+		},
 	}
 
 	for _, test := range tests {
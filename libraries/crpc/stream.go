@@ -0,0 +1,274 @@
+package crpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/merr"
+	"github.com/0xdeafcafe/bloefish/libraries/mlog"
+)
+
+const (
+	// ContentTypeNDJSON is the content type of streams written as newline
+	// delimited JSON. Each line is a StreamFrame.
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// ContentTypeEventStream is the content type of streams written as
+	// server-sent events. Events are sent as `message`, and the stream ends with
+	// either a `done` or `error` event.
+	ContentTypeEventStream = "text/event-stream"
+)
+
+// StreamFrame is a single line of a newline delimited JSON stream. Exactly one
+// of the fields is set. A stream which ends without a Done or Error frame was
+// interrupted.
+type StreamFrame struct {
+	Event json.RawMessage `json:"event,omitempty"`
+	Error *cher.E         `json:"error,omitempty"`
+	Done  bool            `json:"done,omitempty"`
+}
+
+// streamWriter writes the events of a streaming handler to the response. The
+// response isn't started until the first event is sent, so errors returned
+// before then are written as normal.
+type streamWriter struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	req *Request
+	sse bool
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+func newStreamWriter(w http.ResponseWriter, req *Request) *streamWriter {
+	stream := &streamWriter{
+		w:   w,
+		rc:  http.NewResponseController(w),
+		req: req,
+		sse: strings.Contains(req.originalRequest.Header.Get("Accept"), ContentTypeEventStream),
+	}
+
+	req.stream = stream
+
+	return stream
+}
+
+// Started returns true once the response has been started, after which the
+// status code can no longer be changed.
+func (s *streamWriter) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// Send writes an event to the stream and flushes it to the client.
+func (s *streamWriter) Send(event any) error {
+	if err := s.req.Context().Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return merr.New(s.req.Context(), "stream_closed", nil)
+	}
+
+	return s.writeFrame("message", &StreamFrame{Event: data})
+}
+
+// Close ends the stream with a done frame.
+func (s *streamWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+
+	return s.writeFrame("done", &StreamFrame{Done: true})
+}
+
+// CloseWithError ends the stream with an error frame, or a done frame if err is
+// nil.
+func (s *streamWriter) CloseWithError(ctx context.Context, err error) {
+	if err == nil {
+		if err := s.Close(); err != nil {
+			mlog.Debug(ctx, merr.New(ctx, "crpc_write_stream_done_failed", nil, err))
+		}
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+
+	// there is nobody left to tell
+	if ctx.Err() != nil {
+		return
+	}
+
+	body := coerceError(err)
+	if werr := s.writeFrame("error", &StreamFrame{Error: &body}); werr != nil {
+		mlog.Warn(ctx, merr.New(ctx, "crpc_write_stream_error_failed", nil, werr))
+	}
+}
+
+func (s *streamWriter) writeFrame(eventName string, frame *StreamFrame) error {
+	if !s.started {
+		s.started = true
+
+		contentType := ContentTypeNDJSON
+		if s.sse {
+			contentType = ContentTypeEventStream
+		}
+
+		s.w.Header().Set("Content-Type", contentType)
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	var err error
+	if s.sse {
+		err = writeEventStreamFrame(s.w, eventName, frame)
+	} else {
+		err = json.NewEncoder(s.w).Encode(frame)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+func writeEventStreamFrame(w io.Writer, eventName string, frame *StreamFrame) error {
+	var data []byte
+
+	switch {
+	case frame.Error != nil:
+		var err error
+		data, err = json.Marshal(frame.Error)
+		if err != nil {
+			return err
+		}
+	case frame.Done:
+		data = []byte("{}")
+	default:
+		data = frame.Event
+	}
+
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventName, data)
+	return err
+}
+
+// Stream iterates over the events of a streaming method. It must be closed once
+// the caller is done with it.
+type Stream struct {
+	method, version string
+
+	body    io.ReadCloser
+	decoder *json.Decoder
+
+	current json.RawMessage
+	done    bool
+	err     error
+}
+
+// Next advances the stream to the next event, returning false once the stream
+// has ended or failed. Err must be checked afterwards.
+func (s *Stream) Next() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	var frame StreamFrame
+	if err := s.decoder.Decode(&frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			s.err = ClientTransportError{s.method, s.version, "stream ended unexpectedly", nil}
+		} else {
+			s.err = ClientTransportError{s.method, s.version, "could not read stream", err}
+		}
+
+		return false
+	}
+
+	switch {
+	case frame.Error != nil:
+		s.err = *frame.Error
+		return false
+	case frame.Done:
+		s.done = true
+		return false
+	default:
+		s.current = frame.Event
+		return true
+	}
+}
+
+// Decode unmarshals the current event into dst.
+func (s *Stream) Decode(dst any) error {
+	if err := json.Unmarshal(s.current, dst); err != nil {
+		return ClientTransportError{s.method, s.version, "could not unmarshal", err}
+	}
+
+	return nil
+}
+
+// Err returns the error which ended the stream, if any. Errors sent by the
+// server are returned as cher.E.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close closes the underlying response, cancelling the stream on the server if
+// it has not yet ended.
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
+
+// Receive reads each event from the stream and passes it to fn, stopping at the
+// first error. The stream is closed once Receive returns.
+func Receive[T any](stream *Stream, fn func(event *T) error) error {
+	defer stream.Close()
+
+	for stream.Next() {
+		event := new(T)
+		if err := stream.Decode(event); err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return stream.Err()
+}
@@ -0,0 +1,139 @@
+package crpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/matryer/is"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type streamTestRequest struct {
+	Count int `json:"count"`
+}
+
+type streamTestEvent struct {
+	Index int `json:"index"`
+}
+
+func newStreamTestServer() *Server {
+	schema := gojsonschema.NewStringLoader(`{"type": "object"}`)
+
+	rpc := NewServer(UnsafeNoAuthentication)
+	rpc.Register("count", "2019-01-01", schema, func(ctx context.Context, req *streamTestRequest, send func(*streamTestEvent) error) error {
+		if req.Count < 0 {
+			return cher.New("negative_count", nil)
+		}
+
+		for i := range req.Count {
+			if err := send(&streamTestEvent{Index: i}); err != nil {
+				return err
+			}
+		}
+
+		if req.Count == 2 {
+			return cher.New("count_failed", nil)
+		}
+
+		return nil
+	})
+
+	return rpc
+}
+
+func TestWrapStreaming(t *testing.T) {
+	is := is.New(t)
+
+	wrapped, err := Wrap(func(context.Context, *streamTestRequest, func(*streamTestEvent) error) error { return nil })
+	is.NoErr(err)
+	is.True(wrapped.HasRequestInput)
+	is.True(wrapped.HasStreamOutput)
+	is.True(!wrapped.HasResponseOutput)
+	is.Equal("streamTestEvent", wrapped.ResponseType.Elem().Name())
+
+	wrapped, err = Wrap(func(context.Context, func(*streamTestEvent) error) error { return nil })
+	is.NoErr(err)
+	is.True(!wrapped.HasRequestInput)
+	is.True(wrapped.HasStreamOutput)
+
+	desc := newStreamTestServer().Describe()
+	is.True(desc.Versions[0].Methods[0].Streaming)
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	rec := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/2019-01-01/count", strings.NewReader(`{"count": 3}`))
+	newStreamTestServer().ServeHTTP(rec, r)
+
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal(ContentTypeNDJSON, rec.Header().Get("Content-Type"))
+	is.Equal("{\"event\":{\"index\":0}}\n{\"event\":{\"index\":1}}\n{\"event\":{\"index\":2}}\n{\"done\":true}\n", rec.Body.String())
+}
+
+func TestStreamEventStream(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	rec := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/2019-01-01/count", strings.NewReader(`{"count": 2}`))
+	r.Header.Set("Accept", ContentTypeEventStream)
+	newStreamTestServer().ServeHTTP(rec, r)
+
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal(ContentTypeEventStream, rec.Header().Get("Content-Type"))
+	is.Equal("event: message\ndata: {\"index\":0}\n\nevent: message\ndata: {\"index\":1}\n\nevent: error\ndata: {\"code\":\"count_failed\"}\n\n", rec.Body.String())
+}
+
+func TestStreamErrorBeforeFirstEvent(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	rec := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/2019-01-01/count", strings.NewReader(`{"count": -1}`))
+	newStreamTestServer().ServeHTTP(rec, r)
+
+	is.Equal(http.StatusBadRequest, rec.Code)
+	is.Equal("application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	is.Equal("{\"code\":\"negative_count\"}\n", rec.Body.String())
+}
+
+func TestClientDoStream(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	svr := httptest.NewServer(newStreamTestServer())
+	defer svr.Close()
+
+	client := NewClient(ctx, svr.URL, nil)
+
+	stream, err := client.DoStream(ctx, "count", "2019-01-01", &streamTestRequest{Count: 3})
+	is.NoErr(err)
+
+	var indexes []int
+	is.NoErr(Receive(stream, func(event *streamTestEvent) error {
+		indexes = append(indexes, event.Index)
+		return nil
+	}))
+	is.Equal([]int{0, 1, 2}, indexes)
+
+	stream, err = client.DoStream(ctx, "count", "2019-01-01", &streamTestRequest{Count: 2})
+	is.NoErr(err)
+
+	indexes = nil
+	err = Receive(stream, func(event *streamTestEvent) error {
+		indexes = append(indexes, event.Index)
+		return nil
+	})
+	is.Equal([]int{0, 1}, indexes)
+	is.Equal("count_failed", err.(cher.E).Code) //nolint:errorlint,forcetypeassert // required for test
+
+	_, err = client.DoStream(ctx, "count", "2019-01-01", &streamTestRequest{Count: -1})
+	is.Equal("negative_count", err.(cher.E).Code) //nolint:errorlint,forcetypeassert // required for test
+}
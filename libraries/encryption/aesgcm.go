@@ -0,0 +1,78 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of the keys used with AESGCM, for AES-256.
+const KeySize = 32
+
+// ErrInvalidCiphertext is returned when a ciphertext wasn't encrypted with the
+// key, or has been tampered with.
+var ErrInvalidCiphertext = errors.New("encryption: invalid ciphertext")
+
+// ParseKey decodes a base64 encoded key, as generated by
+// `openssl rand -base64 32`.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: failed to decode key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption: key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// AESGCM encrypts and authenticates values with AES-GCM. Each value is
+// encrypted with a random nonce, which is prepended to the ciphertext.
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption: key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESGCM{aead: aead}, nil
+}
+
+func (c *AESGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption: failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *AESGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func newTestKey(fill byte) []byte {
+	return bytes.Repeat([]byte{fill}, KeySize)
+}
+
+func TestParseKey(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		is := is.New(t)
+
+		key, err := ParseKey(base64.StdEncoding.EncodeToString(newTestKey(1)))
+		is.NoErr(err)
+		is.Equal(key, newTestKey(1))
+	})
+
+	t.Run("WrongSize", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+		is.True(err != nil)
+	})
+
+	t.Run("NotBase64", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := ParseKey("not base64!")
+		is.True(err != nil)
+	})
+}
+
+func TestAESGCM(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		is := is.New(t)
+
+		c, err := NewAESGCM(newTestKey(1))
+		is.NoErr(err)
+
+		ciphertext, err := c.Encrypt([]byte("sk-secret"))
+		is.NoErr(err)
+		is.True(!bytes.Contains(ciphertext, []byte("sk-secret")))
+
+		plaintext, err := c.Decrypt(ciphertext)
+		is.NoErr(err)
+		is.Equal(string(plaintext), "sk-secret")
+	})
+
+	t.Run("RandomNonce", func(t *testing.T) {
+		is := is.New(t)
+
+		c, err := NewAESGCM(newTestKey(1))
+		is.NoErr(err)
+
+		first, err := c.Encrypt([]byte("sk-secret"))
+		is.NoErr(err)
+		second, err := c.Encrypt([]byte("sk-secret"))
+		is.NoErr(err)
+
+		is.True(!bytes.Equal(first, second))
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		is := is.New(t)
+
+		c, err := NewAESGCM(newTestKey(1))
+		is.NoErr(err)
+		other, err := NewAESGCM(newTestKey(2))
+		is.NoErr(err)
+
+		ciphertext, err := c.Encrypt([]byte("sk-secret"))
+		is.NoErr(err)
+
+		_, err = other.Decrypt(ciphertext)
+		is.Equal(err, ErrInvalidCiphertext)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		is := is.New(t)
+
+		c, err := NewAESGCM(newTestKey(1))
+		is.NoErr(err)
+
+		ciphertext, err := c.Encrypt([]byte("sk-secret"))
+		is.NoErr(err)
+		ciphertext[len(ciphertext)-1] ^= 0xff
+
+		_, err = c.Decrypt(ciphertext)
+		is.Equal(err, ErrInvalidCiphertext)
+	})
+
+	t.Run("TooShort", func(t *testing.T) {
+		is := is.New(t)
+
+		c, err := NewAESGCM(newTestKey(1))
+		is.NoErr(err)
+
+		_, err = c.Decrypt([]byte{1, 2, 3})
+		is.Equal(err, ErrInvalidCiphertext)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := NewAESGCM([]byte("short"))
+		is.True(err != nil)
+	})
+}
@@ -69,6 +69,36 @@ func (c *Client) Do(ctx context.Context, method, path string, params url.Values,
 
 // DoWithHeaders executes an HTTP request against the configured server with custom headers.
 func (c *Client) DoWithHeaders(ctx context.Context, method, path string, headers http.Header, params url.Values, src, dst any, requestModifiers ...func(r *http.Request)) error {
+	res, err := c.send(ctx, method, path, headers, params, src, requestModifiers...)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	return handleResponse(res, method, path, dst)
+}
+
+// Stream executes an HTTP request against the configured server, and returns
+// the response without reading the body, so it can be consumed as it arrives.
+// Error responses are handled the same way as Do. The caller must close the
+// response body.
+func (c *Client) Stream(ctx context.Context, method, path string, headers http.Header, src any, requestModifiers ...func(r *http.Request)) (*http.Response, error) {
+	res, err := c.send(ctx, method, path, headers, nil, src, requestModifiers...)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+
+		return nil, handleResponse(res, method, path, nil)
+	}
+
+	return res, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, headers http.Header, params url.Values, src any, requestModifiers ...func(r *http.Request)) (*http.Response, error) {
 	fullPath := pathlib.Join("/", c.Prefix, path)
 	req := &http.Request{
 		Method: method,
@@ -98,25 +128,23 @@ func (c *Client) DoWithHeaders(ctx context.Context, method, path string, headers
 
 	err := setRequestBody(req, src)
 	if err != nil {
-		return ClientRequestError{"could not marshal", err}
+		return nil, ClientRequestError{"could not marshal", err}
 	}
 
 	res, err := c.Client.Do(req.WithContext(ctx))
 	if err != nil {
 		if netErr, ok := errfuncs.As[net.Error](err); ok {
 			if netErr.Timeout() {
-				return cher.New(cher.RequestTimeout, cher.M{"method": method, "path": fullPath, "host": c.Host, "scheme": c.Scheme, "timeout_error": netErr.Error()})
+				return nil, cher.New(cher.RequestTimeout, cher.M{"method": method, "path": fullPath, "host": c.Host, "scheme": c.Scheme, "timeout_error": netErr.Error()})
 			}
 
-			return ClientTransportError{method, path, "request failed", netErr}
+			return nil, ClientTransportError{method, path, "request failed", netErr}
 		}
 
-		return ClientTransportError{method, path, "unknown error", err}
+		return nil, ClientTransportError{method, path, "unknown error", err}
 	}
 
-	defer res.Body.Close()
-
-	return handleResponse(res, method, path, dst)
+	return res, nil
 }
 
 func setRequestBody(req *http.Request, src any) error {
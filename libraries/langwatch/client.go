@@ -1,10 +1,14 @@
 package langwatch
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 )
 
 const (
@@ -16,11 +20,15 @@ type client struct {
 	endpointURL string
 	apiKey      string
 	httpClient  *http.Client
+	tracer      *LangWatchTracer
 }
 
 type Client interface {
-	AddEvaluation(ctx context.Context) error
-	Evaluate(ctx context.Context) error
+	// AddEvaluation records the result of an evaluation run outside of
+	// LangWatch against the trace in the context.
+	AddEvaluation(ctx context.Context, evaluation *Evaluation) error
+	// Evaluate runs one of LangWatch's evaluators and returns its result.
+	Evaluate(ctx context.Context, params EvaluateParams) (*EvaluationResult, error)
 }
 
 func NewClient(opts ...ClientOption) Client {
@@ -28,6 +36,7 @@ func NewClient(opts ...ClientOption) Client {
 		endpointURL: defaultEndpointURL,
 		apiKey:      os.Getenv(defaultAPIKeyEnvironmentVariableName),
 		httpClient:  &http.Client{},
+		tracer:      Tracer("github.com/0xdeafcafe/bloefish/libraries/langwatch"),
 	}
 
 	for _, opt := range opts {
@@ -37,10 +46,76 @@ func NewClient(opts ...ClientOption) Client {
 	return c
 }
 
-func (c *client) AddEvaluation(ctx context.Context) error {
-	return errors.New("not implemented")
+// APIError is returned when LangWatch responds with an error.
+type APIError struct {
+	StatusCode int
+	Message    string
 }
 
-func (c *client) Evaluate(ctx context.Context) error {
-	return errors.New("not implemented")
+func (e *APIError) Error() string {
+	return fmt.Sprintf("langwatch API error (%d): %s", e.StatusCode, e.Message)
+}
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (c *client) post(ctx context.Context, path string, src, dst any) error {
+	body, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.endpointURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Auth-Token", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return c.handleErrorResponse(resp)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (c *client) handleErrorResponse(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read error response: %w", err)
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+
+	var errResp errorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		switch {
+		case errResp.Message != "":
+			apiErr.Message = errResp.Message
+		case errResp.Error != "":
+			apiErr.Message = errResp.Error
+		}
+	}
+
+	return apiErr
 }
@@ -0,0 +1,263 @@
+package langwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordedRequest struct {
+	Method    string
+	Path      string
+	AuthToken string
+	Body      map[string]any
+}
+
+func newTestServer(t *testing.T, status int, response string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded.Method = r.Method
+		recorded.Path = r.URL.Path
+		recorded.AuthToken = r.Header.Get("X-Auth-Token")
+
+		if err := json.NewDecoder(r.Body).Decode(&recorded.Body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, recorded
+}
+
+func newTraceContext() context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:  trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	}))
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Run("Passed", func(t *testing.T) {
+		is := is.New(t)
+
+		server, recorded := newTestServer(t, http.StatusOK, `{
+			"status": "processed",
+			"passed": true,
+			"score": 1,
+			"details": "no PII found",
+			"cost": {"currency": "USD", "amount": 0.001}
+		}`)
+
+		c := NewClient(WithEndpointURL(server.URL), WithAPIKey("test-key"))
+
+		result, err := c.Evaluate(context.Background(), EvaluateParams{
+			Evaluator: "presidio/pii_detection",
+			Data: EvaluationData{
+				Input: "What's the weather like?",
+			},
+			Settings: map[string]any{
+				"min_threshold": 0.5,
+			},
+			AsGuardrail: true,
+		})
+		is.NoErr(err)
+
+		is.Equal(recorded.Method, http.MethodPost)
+		is.Equal(recorded.Path, "/api/evaluations/presidio/pii_detection/evaluate")
+		is.Equal(recorded.AuthToken, "test-key")
+		is.Equal(recorded.Body["name"], "presidio/pii_detection")
+		is.Equal(recorded.Body["as_guardrail"], true)
+		is.Equal(recorded.Body["data"], map[string]any{"input": "What's the weather like?"})
+		is.Equal(recorded.Body["settings"], map[string]any{"min_threshold": 0.5})
+
+		is.Equal(result.Status, EvaluationStatusProcessed)
+		is.True(result.Passed != nil && *result.Passed)
+		is.True(result.Score != nil && *result.Score == 1)
+		is.True(result.Details != nil && *result.Details == "no PII found")
+		is.Equal(result.Cost, &EvaluationCost{Currency: "USD", Amount: 0.001})
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		is := is.New(t)
+
+		server, _ := newTestServer(t, http.StatusOK, `{
+			"status": "processed",
+			"passed": false,
+			"details": "jailbreak attempt detected"
+		}`)
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		result, err := c.Evaluate(context.Background(), EvaluateParams{
+			Evaluator: "azure/jailbreak",
+			Name:      "Jailbreak guardrail",
+		})
+		is.NoErr(err)
+
+		is.Equal(result.Status, EvaluationStatusProcessed)
+		is.True(result.Passed != nil && !*result.Passed)
+	})
+
+	t.Run("EvaluatorError", func(t *testing.T) {
+		is := is.New(t)
+
+		server, _ := newTestServer(t, http.StatusOK, `{
+			"status": "error",
+			"error_type": "ValueError",
+			"details": "input is required"
+		}`)
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		result, err := c.Evaluate(context.Background(), EvaluateParams{
+			Evaluator: "presidio/pii_detection",
+		})
+		is.NoErr(err)
+
+		is.Equal(result.Status, EvaluationStatusError)
+		is.True(result.ErrorType != nil && *result.ErrorType == "ValueError")
+		is.True(result.Passed == nil)
+	})
+
+	t.Run("TraceContext", func(t *testing.T) {
+		is := is.New(t)
+
+		server, recorded := newTestServer(t, http.StatusOK, `{"status": "skipped"}`)
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		_, err := c.Evaluate(newTraceContext(), EvaluateParams{
+			Evaluator: "presidio/pii_detection",
+		})
+		is.NoErr(err)
+
+		is.Equal(recorded.Body["trace_id"], "0102030405060708090a0b0c0d0e0f10")
+		is.True(recorded.Body["span_id"] != nil)
+	})
+
+	t.Run("APIError", func(t *testing.T) {
+		is := is.New(t)
+
+		server, _ := newTestServer(t, http.StatusUnauthorized, `{"message": "Invalid auth token."}`)
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		_, err := c.Evaluate(context.Background(), EvaluateParams{
+			Evaluator: "presidio/pii_detection",
+		})
+
+		var apiErr *APIError
+		is.True(errors.As(err, &apiErr))
+		is.Equal(apiErr.StatusCode, http.StatusUnauthorized)
+		is.Equal(apiErr.Message, "Invalid auth token.")
+	})
+
+	t.Run("APIErrorNotJSON", func(t *testing.T) {
+		is := is.New(t)
+
+		server, _ := newTestServer(t, http.StatusBadGateway, "bad gateway\n")
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		_, err := c.Evaluate(context.Background(), EvaluateParams{
+			Evaluator: "presidio/pii_detection",
+		})
+
+		var apiErr *APIError
+		is.True(errors.As(err, &apiErr))
+		is.Equal(apiErr.StatusCode, http.StatusBadGateway)
+		is.Equal(apiErr.Message, "bad gateway")
+	})
+}
+
+func TestAddEvaluation(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		is := is.New(t)
+
+		server, recorded := newTestServer(t, http.StatusOK, `{"message": "Trace received successfully."}`)
+
+		c := NewClient(WithEndpointURL(server.URL+"/"), WithAPIKey("test-key"))
+
+		passed := true
+		startedAt := time.UnixMilli(1700000000000)
+
+		err := c.AddEvaluation(newTraceContext(), &Evaluation{
+			Name:        "Tone check",
+			Type:        "custom/tone",
+			IsGuardrail: false,
+			Passed:      &passed,
+			StartedAt:   startedAt,
+			FinishedAt:  startedAt.Add(time.Second),
+		})
+		is.NoErr(err)
+
+		is.Equal(recorded.Path, "/api/collector")
+		is.Equal(recorded.AuthToken, "test-key")
+		is.Equal(recorded.Body["trace_id"], "0102030405060708090a0b0c0d0e0f10")
+		is.Equal(recorded.Body["spans"], []any{})
+
+		evaluations, ok := recorded.Body["evaluations"].([]any)
+		is.True(ok)
+		is.Equal(len(evaluations), 1)
+
+		evaluation := evaluations[0].(map[string]any)
+		is.Equal(evaluation["span_id"], "0102030405060708")
+		is.Equal(evaluation["name"], "Tone check")
+		is.Equal(evaluation["type"], "custom/tone")
+		is.Equal(evaluation["is_guardrail"], false)
+		is.Equal(evaluation["status"], "processed")
+		is.Equal(evaluation["passed"], true)
+		is.Equal(evaluation["error"], nil)
+		is.Equal(evaluation["timestamps"], map[string]any{
+			"started_at":  float64(1700000000000),
+			"finished_at": float64(1700000001000),
+		})
+
+		evaluationID, _ := evaluation["evaluation_id"].(string)
+		is.Equal(len(evaluationID), len("eval_")+24)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		is := is.New(t)
+
+		server, recorded := newTestServer(t, http.StatusOK, `{}`)
+
+		c := NewClient(WithEndpointURL(server.URL))
+
+		err := c.AddEvaluation(newTraceContext(), &Evaluation{
+			Name:  "Tone check",
+			Error: errors.New("model unavailable"),
+		})
+		is.NoErr(err)
+
+		evaluation := recorded.Body["evaluations"].([]any)[0].(map[string]any)
+		is.Equal(evaluation["status"], "error")
+		is.Equal(evaluation["error"], map[string]any{
+			"has_error":  true,
+			"message":    "model unavailable",
+			"stacktrace": []any{},
+		})
+	})
+
+	t.Run("NoTrace", func(t *testing.T) {
+		is := is.New(t)
+
+		c := NewClient(WithEndpointURL("http://127.0.0.1:0"))
+
+		err := c.AddEvaluation(context.Background(), &Evaluation{Name: "Tone check"})
+		is.Equal(err, ErrNoTrace)
+	})
+}
@@ -0,0 +1,229 @@
+package langwatch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNoTrace is returned when an evaluation is added without a trace in the
+// context to add it to.
+var ErrNoTrace = errors.New("langwatch: no trace in context")
+
+type EvaluationStatus string
+
+const (
+	EvaluationStatusProcessed EvaluationStatus = "processed"
+	EvaluationStatusSkipped   EvaluationStatus = "skipped"
+	EvaluationStatusError     EvaluationStatus = "error"
+)
+
+// EvaluationData is what an evaluator looks at. Evaluators only use the
+// fields relevant to them, for example a PII check only needs the input or
+// output it's checking.
+type EvaluationData struct {
+	Input          string   `json:"input,omitempty"`
+	Output         string   `json:"output,omitempty"`
+	ExpectedOutput string   `json:"expected_output,omitempty"`
+	Contexts       []string `json:"contexts,omitempty"`
+}
+
+type EvaluateParams struct {
+	// Evaluator is the slug of the evaluator to run, such as
+	// "presidio/pii_detection", or of an evaluator set up in LangWatch.
+	Evaluator string
+	// Name is shown in LangWatch, and defaults to the evaluator slug.
+	Name     string
+	Data     EvaluationData
+	Settings map[string]any
+	// AsGuardrail marks the evaluation as a guardrail, whose result decides
+	// whether the application carries on.
+	AsGuardrail bool
+}
+
+type EvaluationCost struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+type EvaluationResult struct {
+	Status EvaluationStatus `json:"status"`
+	// Passed is only set by evaluators which pass or fail, rather than score.
+	Passed  *bool           `json:"passed"`
+	Score   *float64        `json:"score"`
+	Label   *string         `json:"label"`
+	Details *string         `json:"details"`
+	Cost    *EvaluationCost `json:"cost"`
+
+	// ErrorType is set when Status is EvaluationStatusError, with the reason in
+	// Details.
+	ErrorType *string `json:"error_type"`
+}
+
+// Evaluation is the result of an evaluation which was run outside of
+// LangWatch.
+type Evaluation struct {
+	Name        string
+	Type        string
+	IsGuardrail bool
+
+	Status  EvaluationStatus
+	Passed  *bool
+	Score   *float64
+	Label   *string
+	Details *string
+	Error   error
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+type evaluateRequest struct {
+	TraceID     string         `json:"trace_id,omitempty"`
+	SpanID      string         `json:"span_id,omitempty"`
+	Name        string         `json:"name"`
+	Data        EvaluationData `json:"data"`
+	Settings    map[string]any `json:"settings,omitempty"`
+	AsGuardrail bool           `json:"as_guardrail"`
+}
+
+type collectorRequest struct {
+	TraceID     string                 `json:"trace_id"`
+	Spans       []any                  `json:"spans"`
+	Evaluations []*collectorEvaluation `json:"evaluations"`
+}
+
+type collectorEvaluation struct {
+	EvaluationID string           `json:"evaluation_id"`
+	SpanID       string           `json:"span_id,omitempty"`
+	Name         string           `json:"name"`
+	Type         string           `json:"type,omitempty"`
+	IsGuardrail  bool             `json:"is_guardrail"`
+	Status       EvaluationStatus `json:"status"`
+	Passed       *bool            `json:"passed"`
+	Score        *float64         `json:"score"`
+	Label        *string          `json:"label"`
+	Details      *string          `json:"details"`
+	Error        *collectorError  `json:"error"`
+	Timestamps   struct {
+		StartedAtUnix  *int64 `json:"started_at,omitempty"`
+		FinishedAtUnix *int64 `json:"finished_at,omitempty"`
+	} `json:"timestamps"`
+}
+
+type collectorError struct {
+	HasError bool     `json:"has_error"`
+	Message  string   `json:"message"`
+	Stack    []string `json:"stacktrace"`
+}
+
+func (c *client) Evaluate(ctx context.Context, params EvaluateParams) (*EvaluationResult, error) {
+	if params.Name == "" {
+		params.Name = params.Evaluator
+	}
+
+	ctx, span := c.tracer.Start(ctx, params.Name)
+	defer span.End()
+
+	if params.AsGuardrail {
+		span.SetType(SpanTypeGuardrail)
+	} else {
+		span.SetType(SpanTypeEvaluation)
+	}
+	span.RecordInput(params.Data)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	req := &evaluateRequest{
+		Name:        params.Name,
+		Data:        params.Data,
+		Settings:    params.Settings,
+		AsGuardrail: params.AsGuardrail,
+	}
+	if spanContext.IsValid() {
+		req.TraceID = spanContext.TraceID().String()
+		req.SpanID = spanContext.SpanID().String()
+	}
+
+	var result *EvaluationResult
+	if err := c.post(ctx, "/api/evaluations/"+params.Evaluator+"/evaluate", req, &result); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	span.RecordOutput(result)
+	if result.Status == EvaluationStatusError {
+		span.SetStatus(codes.Error, "evaluation failed")
+	}
+
+	return result, nil
+}
+
+func (c *client) AddEvaluation(ctx context.Context, evaluation *Evaluation) error {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ErrNoTrace
+	}
+
+	evaluationID, err := newEvaluationID()
+	if err != nil {
+		return err
+	}
+
+	e := &collectorEvaluation{
+		EvaluationID: evaluationID,
+		SpanID:       spanContext.SpanID().String(),
+		Name:         evaluation.Name,
+		Type:         evaluation.Type,
+		IsGuardrail:  evaluation.IsGuardrail,
+		Status:       evaluation.Status,
+		Passed:       evaluation.Passed,
+		Score:        evaluation.Score,
+		Label:        evaluation.Label,
+		Details:      evaluation.Details,
+	}
+
+	if e.Status == "" {
+		e.Status = EvaluationStatusProcessed
+	}
+
+	if evaluation.Error != nil {
+		e.Status = EvaluationStatusError
+		e.Error = &collectorError{
+			HasError: true,
+			Message:  evaluation.Error.Error(),
+			Stack:    []string{},
+		}
+	}
+
+	if !evaluation.StartedAt.IsZero() {
+		startedAt := evaluation.StartedAt.UnixMilli()
+		e.Timestamps.StartedAtUnix = &startedAt
+	}
+	if !evaluation.FinishedAt.IsZero() {
+		finishedAt := evaluation.FinishedAt.UnixMilli()
+		e.Timestamps.FinishedAtUnix = &finishedAt
+	}
+
+	return c.post(ctx, "/api/collector", &collectorRequest{
+		TraceID:     spanContext.TraceID().String(),
+		Spans:       []any{},
+		Evaluations: []*collectorEvaluation{e},
+	}, nil)
+}
+
+func newEvaluationID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate evaluation id: %w", err)
+	}
+
+	return "eval_" + hex.EncodeToString(b), nil
+}
@@ -21,3 +21,11 @@ func (t *LangWatchTracer) Start(ctx context.Context, name string, opts ...trace.
 	ctx, span := t.tracer.Start(ctx, name, opts...)
 	return ctx, &Span{span}
 }
+
+// TracerFromProvider returns a LangWatchTracer which starts spans with the
+// given provider, rather than the global one.
+func TracerFromProvider(provider trace.TracerProvider, name string, options ...trace.TracerOption) *LangWatchTracer {
+	return &LangWatchTracer{
+		tracer: provider.Tracer(name, options...),
+	}
+}
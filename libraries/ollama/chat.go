@@ -12,6 +12,9 @@ import (
 type NewStreamingChatParams struct {
 	Messages []Message `json:"messages"`
 	Model    string    `json:"model"`
+
+	// Format constrains the response to the given JSON schema.
+	Format json.RawMessage `json:"format,omitempty"`
 }
 
 func (c *client) NewStreamingChat(ctx context.Context, params NewStreamingChatParams) (*StreamingChatIterator, error) {
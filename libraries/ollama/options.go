@@ -1,5 +1,7 @@
 package ollama
 
+import "net/http"
+
 type ClientOption func(*client)
 
 func WithEndpointURL(endpoint string) ClientOption {
@@ -7,3 +9,12 @@ func WithEndpointURL(endpoint string) ClientOption {
 		c.endpointURL = endpoint
 	}
 }
+
+// WithTransport sets the http.RoundTripper requests to Ollama are sent
+// through, such as one which traces them.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *client) {
+		c.httpClient.Transport = transport
+		c.streamClient.Transport = transport
+	}
+}
@@ -0,0 +1,61 @@
+package otelollama
+
+import (
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// config is used to configure the transport.
+type config struct {
+	tracerProvider oteltrace.TracerProvider
+	propagators    propagation.TextMapPropagator
+	recordInput    bool
+	recordOutput   bool
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a tracer.
+// If none is specified, the global provider is used.
+func WithTracerProvider(provider oteltrace.TracerProvider) Option {
+	return optionFunc(func(c *config) {
+		c.tracerProvider = provider
+	})
+}
+
+// WithPropagators specifies propagators to use for injecting the trace
+// context into requests to Ollama. If none are specified, global ones will be
+// used.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return optionFunc(func(c *config) {
+		c.propagators = propagators
+	})
+}
+
+// WithCaptureInput enables recording the messages or prompt sent to the model
+// under the `langwatch.input` attribute.
+// Be cautious with sensitive data.
+func WithCaptureInput() Option {
+	return optionFunc(func(c *config) {
+		c.recordInput = true
+	})
+}
+
+// WithCaptureOutput enables recording the model's response, accumulated from
+// the stream if the response is streamed, under the `langwatch.output`
+// attribute.
+// Be cautious with sensitive data.
+func WithCaptureOutput() Option {
+	return optionFunc(func(c *config) {
+		c.recordOutput = true
+	})
+}
@@ -0,0 +1,366 @@
+package otelollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
+)
+
+const (
+	tracerName             = "github.com/0xdeafcafe/bloefish/libraries/otelollama"
+	instrumentationVersion = "0.0.1"
+)
+
+const (
+	AttributeOllamaTotalDuration      = attribute.Key("ollama.duration.total")
+	AttributeOllamaLoadDuration       = attribute.Key("ollama.duration.load")
+	AttributeOllamaPromptEvalDuration = attribute.Key("ollama.duration.prompt_eval")
+	AttributeOllamaEvalDuration       = attribute.Key("ollama.duration.eval")
+)
+
+// genAISystemOllama isn't one of the systems defined by the semantic
+// conventions, so it's defined here.
+var genAISystemOllama = semconv.GenAISystemKey.String("ollama")
+
+// operations are the Ollama endpoints which are traced, and the GenAI
+// operation each of them is. Requests to other endpoints, such as listing
+// models, are passed through untraced.
+var operations = map[string]attribute.KeyValue{
+	"/api/chat":     semconv.GenAIOperationNameChat,
+	"/api/generate": semconv.GenAIOperationNameTextCompletion,
+}
+
+// request is the part of a chat or generate request which is traced.
+type request struct {
+	Model    string          `json:"model"`
+	Messages json.RawMessage `json:"messages"`
+	Prompt   string          `json:"prompt"`
+	Stream   *bool           `json:"stream"`
+	Options  struct {
+		Temperature *float64 `json:"temperature"`
+		TopP        *float64 `json:"top_p"`
+		TopK        *float64 `json:"top_k"`
+		NumPredict  *int     `json:"num_predict"`
+		Seed        *int     `json:"seed"`
+	} `json:"options"`
+}
+
+// responseEvent is a single line of a chat or generate response. Responses
+// which aren't streamed are a single event.
+type responseEvent struct {
+	Model   string `json:"model"`
+	Message *struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Response string `json:"response"`
+	Error    string `json:"error"`
+
+	// The following are only set when done = true
+	Done               bool   `json:"done"`
+	DoneReason         string `json:"done_reason"`
+	TotalDuration      int64  `json:"total_duration"`
+	LoadDuration       int64  `json:"load_duration"`
+	PromptEvalCount    int    `json:"prompt_eval_count"`
+	PromptEvalDuration int64  `json:"prompt_eval_duration"`
+	EvalCount          int    `json:"eval_count"`
+	EvalDuration       int64  `json:"eval_duration"`
+}
+
+type transport struct {
+	base   http.RoundTripper
+	cfg    config
+	tracer *langwatch.LangWatchTracer
+}
+
+// NewTransport wraps an http.RoundTripper so the chat and generate requests
+// sent through it to Ollama are traced. If base is nil, http.DefaultTransport
+// is used.
+func NewTransport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	cfg := config{}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	return &transport{
+		base: base,
+		cfg:  cfg,
+		tracer: langwatch.TracerFromProvider(
+			cfg.tracerProvider,
+			tracerName,
+			trace.WithInstrumentationVersion(instrumentationVersion),
+			trace.WithSchemaURL(semconv.SchemaURL),
+		),
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation, ok := operations[req.URL.Path]
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), "ollama."+operation.Value.AsString(),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddressKey.String(req.URL.Hostname()),
+			semconv.URLPathKey.String(req.URL.Path),
+			genAISystemOllama,
+			operation,
+		),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	span.SetType(langwatch.SpanTypeLLM)
+
+	// Ollama streams unless it's told not to
+	streaming := true
+	if req.Body != nil && req.Body != http.NoBody {
+		reqBody, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+
+			return nil, err
+		}
+
+		// Important!: We need to restore the body so the base transport can read
+		// it
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+		var reqData request
+		if err := json.Unmarshal(reqBody, &reqData); err == nil {
+			t.setRequestAttributes(span, operation, &reqData)
+			if reqData.Stream != nil {
+				streaming = *reqData.Stream
+			}
+		} else {
+			log.Default().Printf("Failed to parse Ollama request body JSON: %v", err)
+		}
+	}
+	span.SetAttributes(langwatch.AttributeLangWatchStreaming.Bool(streaming))
+
+	req = req.Clone(ctx)
+	t.cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	startedAt := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+
+	// The span is ended once the response has been read, so streamed
+	// responses are traced until they finish
+	resp.Body = &responseBody{
+		ReadCloser:   resp.Body,
+		span:         span,
+		recordOutput: t.cfg.recordOutput,
+		startedAt:    startedAt,
+	}
+
+	return resp, nil
+}
+
+func (t *transport) setRequestAttributes(span *langwatch.Span, operation attribute.KeyValue, reqData *request) {
+	if reqData.Model != "" {
+		span.SetRequestModel(reqData.Model)
+		span.SetName(fmt.Sprintf("ollama.%s.%s", operation.Value.AsString(), reqData.Model))
+	}
+	if reqData.Options.Temperature != nil {
+		span.SetAttributes(semconv.GenAIRequestTemperature(*reqData.Options.Temperature))
+	}
+	if reqData.Options.TopP != nil {
+		span.SetAttributes(semconv.GenAIRequestTopP(*reqData.Options.TopP))
+	}
+	if reqData.Options.TopK != nil {
+		span.SetAttributes(semconv.GenAIRequestTopK(*reqData.Options.TopK))
+	}
+	if reqData.Options.NumPredict != nil {
+		span.SetAttributes(semconv.GenAIRequestMaxTokens(*reqData.Options.NumPredict))
+	}
+	if reqData.Options.Seed != nil {
+		span.SetAttributes(semconv.GenAIRequestSeed(*reqData.Options.Seed))
+	}
+
+	if !t.cfg.recordInput {
+		return
+	}
+
+	if len(reqData.Messages) > 0 {
+		span.RecordInput(reqData.Messages)
+	} else if reqData.Prompt != "" {
+		span.RecordInputString(reqData.Prompt)
+	}
+}
+
+// responseBody reads the events of a response as the client reads them, and
+// ends the span once the response has been read or closed.
+type responseBody struct {
+	io.ReadCloser
+
+	span         *langwatch.Span
+	recordOutput bool
+
+	buf          []byte
+	output       strings.Builder
+	model        string
+	startedAt    time.Time
+	firstTokenAt *time.Time
+
+	endOnce sync.Once
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.write(p[:n])
+	}
+
+	if err == io.EOF {
+		b.end(nil)
+	} else if err != nil {
+		b.end(err)
+	}
+
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end(nil)
+
+	return err
+}
+
+// write processes every complete line read so far.
+func (b *responseBody) write(p []byte) {
+	b.buf = append(b.buf, p...)
+
+	for {
+		i := bytes.IndexByte(b.buf, '\n')
+		if i < 0 {
+			return
+		}
+
+		b.processLine(b.buf[:i])
+		b.buf = b.buf[i+1:]
+	}
+}
+
+func (b *responseBody) processLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var event responseEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		log.Default().Printf("Failed to parse Ollama response event JSON: %v", err)
+		return
+	}
+
+	if event.Error != "" {
+		b.span.SetStatus(codes.Error, event.Error)
+		return
+	}
+
+	if event.Model != "" && b.model == "" {
+		b.model = event.Model
+		b.span.SetResponseModel(event.Model)
+	}
+
+	content := event.Response
+	if event.Message != nil {
+		content = event.Message.Content
+	}
+	if content != "" {
+		if b.firstTokenAt == nil {
+			now := time.Now()
+			b.firstTokenAt = &now
+		}
+		if b.recordOutput {
+			b.output.WriteString(content)
+		}
+	}
+
+	if !event.Done {
+		return
+	}
+
+	attributes := []attribute.KeyValue{
+		semconv.GenAIUsageInputTokens(event.PromptEvalCount),
+		semconv.GenAIUsageOutputTokens(event.EvalCount),
+		AttributeOllamaTotalDuration.Float64(time.Duration(event.TotalDuration).Seconds()),
+		AttributeOllamaLoadDuration.Float64(time.Duration(event.LoadDuration).Seconds()),
+		AttributeOllamaPromptEvalDuration.Float64(time.Duration(event.PromptEvalDuration).Seconds()),
+		AttributeOllamaEvalDuration.Float64(time.Duration(event.EvalDuration).Seconds()),
+	}
+	if event.DoneReason != "" {
+		attributes = append(attributes, semconv.GenAIResponseFinishReasons(event.DoneReason))
+	}
+
+	b.span.SetAttributes(attributes...)
+}
+
+func (b *responseBody) end(err error) {
+	b.endOnce.Do(func() {
+		// Responses which aren't streamed don't always end in a new line
+		b.processLine(b.buf)
+		b.buf = nil
+
+		if err != nil {
+			b.span.RecordError(err)
+			b.span.SetStatus(codes.Error, err.Error())
+		}
+
+		if b.recordOutput && b.output.Len() > 0 {
+			b.span.RecordOutputString(b.output.String())
+		}
+
+		timestamps := langwatch.SpanTimestamps{
+			StartedAtUnix:  b.startedAt.UnixMilli(),
+			FinishedAtUnix: time.Now().UnixMilli(),
+		}
+		if b.firstTokenAt != nil {
+			firstTokenAt := b.firstTokenAt.UnixMilli()
+			timestamps.FirstTokenAtUnix = &firstTokenAt
+		}
+		b.span.SetTimestamps(timestamps)
+
+		b.span.End()
+	})
+}
@@ -0,0 +1,217 @@
+package otelollama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
+)
+
+const streamedChatResponse = `{"model":"llama3.2","created_at":"2025-05-01T12:00:00Z","message":{"role":"assistant","content":"Hello"},"done":false}
+{"model":"llama3.2","created_at":"2025-05-01T12:00:00Z","message":{"role":"assistant","content":" there!"},"done":false}
+{"model":"llama3.2","created_at":"2025-05-01T12:00:01Z","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","total_duration":2000000000,"load_duration":500000000,"prompt_eval_count":12,"prompt_eval_duration":250000000,"eval_count":3,"eval_duration":1000000000}
+`
+
+const generateResponse = `{"model":"llama3.2","created_at":"2025-05-01T12:00:00Z","response":"Bonjour","done":true,"done_reason":"stop","total_duration":1000000000,"load_duration":0,"prompt_eval_count":8,"prompt_eval_duration":100000000,"eval_count":2,"eval_duration":500000000}`
+
+func setupTest(t *testing.T, handler http.HandlerFunc, opts ...Option) (*http.Client, string, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	opts = append([]Option{WithTracerProvider(provider)}, opts...)
+
+	return &http.Client{Transport: NewTransport(nil, opts...)}, server.URL, exporter
+}
+
+func doRequest(t *testing.T, client *http.Client, url, body string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(respBody)
+}
+
+func attributeMap(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func TestStreamedChat(t *testing.T) {
+	var receivedBody, traceParent string
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		traceParent = r.Header.Get("Traceparent")
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(streamedChatResponse))
+	}, WithPropagators(propagation.TraceContext{}), WithCaptureInput(), WithCaptureOutput())
+
+	reqBody := `{"model":"llama3.2","messages":[{"role":"user","content":"Hi"}],"options":{"temperature":0.5,"num_predict":128}}`
+	resp, respBody := doRequest(t, client, url+"/api/chat", reqBody)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, streamedChatResponse, respBody, "the response should be passed through untouched")
+	assert.Equal(t, reqBody, receivedBody, "the request should be passed through untouched")
+	assert.NotEmpty(t, traceParent, "the trace context should be injected")
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	attributes := attributeMap(span)
+
+	assert.Equal(t, "ollama.chat.llama3.2", span.Name)
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Equal(t, "ollama", attributes["gen_ai.system"].AsString())
+	assert.Equal(t, "chat", attributes["gen_ai.operation.name"].AsString())
+	assert.Equal(t, "llama3.2", attributes["gen_ai.request.model"].AsString())
+	assert.Equal(t, "llama3.2", attributes["gen_ai.response.model"].AsString())
+	assert.Equal(t, 0.5, attributes["gen_ai.request.temperature"].AsFloat64())
+	assert.Equal(t, int64(128), attributes["gen_ai.request.max_tokens"].AsInt64())
+	assert.Equal(t, []string{"stop"}, attributes["gen_ai.response.finish_reasons"].AsStringSlice())
+	assert.Equal(t, int64(12), attributes["gen_ai.usage.input_tokens"].AsInt64())
+	assert.Equal(t, int64(3), attributes["gen_ai.usage.output_tokens"].AsInt64())
+	assert.Equal(t, 2.0, attributes[AttributeOllamaTotalDuration].AsFloat64())
+	assert.Equal(t, 0.5, attributes[AttributeOllamaLoadDuration].AsFloat64())
+	assert.Equal(t, 0.25, attributes[AttributeOllamaPromptEvalDuration].AsFloat64())
+	assert.Equal(t, 1.0, attributes[AttributeOllamaEvalDuration].AsFloat64())
+	assert.True(t, attributes[langwatch.AttributeLangWatchStreaming].AsBool())
+	assert.Equal(t, "llm", attributes[langwatch.AttributeLangWatchSpanType].AsString())
+
+	var input struct {
+		Type  string `json:"type"`
+		Value []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(attributes[langwatch.AttributeLangWatchInput].AsString()), &input))
+	require.Len(t, input.Value, 1)
+	assert.Equal(t, "Hi", input.Value[0].Content)
+
+	assert.JSONEq(t, `{"type":"text","value":"Hello there!"}`, attributes[langwatch.AttributeLangWatchOutput].AsString())
+
+	var timestamps struct {
+		Value langwatch.SpanTimestamps `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(attributes[langwatch.AttributeLangWatchTimestamps].AsString()), &timestamps))
+	assert.NotNil(t, timestamps.Value.FirstTokenAtUnix)
+}
+
+func TestGenerateWithoutStreaming(t *testing.T) {
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(generateResponse))
+	}, WithCaptureInput(), WithCaptureOutput())
+
+	_, respBody := doRequest(t, client, url+"/api/generate", `{"model":"llama3.2","prompt":"Translate hello to French","stream":false}`)
+	assert.Equal(t, generateResponse, respBody)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	attributes := attributeMap(spans[0])
+
+	assert.Equal(t, "ollama.text_completion.llama3.2", spans[0].Name)
+	assert.Equal(t, "text_completion", attributes["gen_ai.operation.name"].AsString())
+	assert.False(t, attributes[langwatch.AttributeLangWatchStreaming].AsBool())
+	assert.Equal(t, int64(8), attributes["gen_ai.usage.input_tokens"].AsInt64())
+	assert.Equal(t, int64(2), attributes["gen_ai.usage.output_tokens"].AsInt64())
+	assert.JSONEq(t, `{"type":"text","value":"Translate hello to French"}`, attributes[langwatch.AttributeLangWatchInput].AsString())
+	assert.JSONEq(t, `{"type":"text","value":"Bonjour"}`, attributes[langwatch.AttributeLangWatchOutput].AsString())
+}
+
+func TestContentNotCapturedByDefault(t *testing.T) {
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(streamedChatResponse))
+	})
+
+	doRequest(t, client, url+"/api/chat", `{"model":"llama3.2","messages":[{"role":"user","content":"Hi"}]}`)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	attributes := attributeMap(spans[0])
+	assert.NotContains(t, attributes, langwatch.AttributeLangWatchInput)
+	assert.NotContains(t, attributes, langwatch.AttributeLangWatchOutput)
+	assert.Equal(t, int64(3), attributes["gen_ai.usage.output_tokens"].AsInt64())
+}
+
+func TestErrorResponse(t *testing.T) {
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"llama9\" not found, try pulling it first"}`))
+	})
+
+	resp, _ := doRequest(t, client, url+"/api/chat", `{"model":"llama9","messages":[]}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, `model "llama9" not found, try pulling it first`, spans[0].Status.Description)
+	assert.Equal(t, int64(http.StatusNotFound), attributeMap(spans[0])["http.response.status_code"].AsInt64())
+}
+
+func TestSpanEndedWhenClosedEarly(t *testing.T) {
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(streamedChatResponse))
+	})
+
+	req, err := http.NewRequest(http.MethodPost, url+"/api/chat", strings.NewReader(`{"model":"llama3.2"}`))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Len(t, exporter.GetSpans(), 1)
+}
+
+func TestUntracedEndpoint(t *testing.T) {
+	client, url, exporter := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	})
+
+	req, err := http.NewRequest(http.MethodGet, url+"/api/tags", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, exporter.GetSpans())
+}
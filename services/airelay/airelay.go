@@ -1,11 +1,31 @@
 package airelay
 
-import "context"
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// ErrCodeResponseFormatViolation is returned when a model's response doesn't
+	// match the requested response format. Each schema violation is included as
+	// a reason.
+	ErrCodeResponseFormatViolation = "response_format_violation"
+)
 
 type Service interface {
 	ListSupported(ctx context.Context) (*ListSupportedResponse, error)
 	InvokeConversationMessage(ctx context.Context, req *InvokeConversationMessageRequest) (*InvokeConversationMessageResponse, error)
 	InvokeStreamingConversationMessage(ctx context.Context, req *InvokeStreamingConversationMessageRequest) (*InvokeStreamingConversationMessageResponse, error)
+	StreamConversationMessage(ctx context.Context, req *InvokeConversationMessageRequest, send func(*StreamConversationMessageEvent) error) error
+
+	CreateProvider(ctx context.Context, req *CreateProviderRequest) (*CreateProviderResponse, error)
+	ListProviders(ctx context.Context) (*ListProvidersResponse, error)
+	UpdateProvider(ctx context.Context, req *UpdateProviderRequest) (*UpdateProviderResponse, error)
+	DeleteProvider(ctx context.Context, req *DeleteProviderRequest) error
+	TestProvider(ctx context.Context, req *TestProviderRequest) error
 }
 
 type ActorType string
@@ -20,6 +40,34 @@ type Actor struct {
 	Identifier string    `json:"identifier"`
 }
 
+type ProviderType string
+
+const (
+	ProviderTypeOpenAI ProviderType = "open_ai"
+	ProviderTypeOllama ProviderType = "ollama"
+	// ProviderTypeWhisperCPP providers can only be used for transcription.
+	ProviderTypeWhisperCPP ProviderType = "whisper_cpp"
+)
+
+type Provider struct {
+	ID   string       `json:"id"`
+	Type ProviderType `json:"type"`
+	Name string       `json:"name"`
+
+	HasAPIKey      bool             `json:"has_api_key"`
+	OrganizationID string           `json:"organization_id"`
+	EndpointURL    string           `json:"endpoint_url"`
+	Models         []*ProviderModel `json:"models"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ProviderModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 type ListSupportedResponse struct {
 	Models []*ListSupportedResponseModel `json:"models"`
 }
@@ -37,6 +85,7 @@ type InvokeConversationMessageRequest struct {
 	Owner          *Actor                                          `json:"owner"`
 	Messages       []*InvokeConversationMessageRequestMessage      `json:"messages"`
 	AIRelayOptions *InvokeConversationMessageRequestAIRelayOptions `json:"ai_relay_options"`
+	ResponseFormat *ResponseFormat                                 `json:"response_format,omitempty"`
 }
 
 type InvokeConversationMessageRequestMessage struct {
@@ -50,8 +99,20 @@ type InvokeConversationMessageRequestAIRelayOptions struct {
 	ModelID    string `json:"model_id"`
 }
 
+// ResponseFormat requests structured output, constraining the response to a
+// JSON schema.
+type ResponseFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
 type InvokeConversationMessageResponse struct {
-	MessageContent string `json:"message_content"`
+	MessageContent   string `json:"message_content"`
+	ReasoningContent string `json:"reasoning_content"`
+
+	// FirstTokenAt is when the model produced its first content or reasoning
+	// token, nil if it produced nothing.
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 type InvokeStreamingConversationMessageRequest struct {
 	ConversationID     string                                          `json:"conversation_id"`
@@ -60,8 +121,62 @@ type InvokeStreamingConversationMessageRequest struct {
 	Owner              *Actor                                          `json:"owner"`
 	Messages           []*InvokeConversationMessageRequestMessage      `json:"messages"`
 	AIRelayOptions     *InvokeConversationMessageRequestAIRelayOptions `json:"ai_relay_options"`
+	ResponseFormat     *ResponseFormat                                 `json:"response_format,omitempty"`
 }
 
 type InvokeStreamingConversationMessageResponse struct {
-	MessageContent string `json:"message_content"`
+	MessageContent   string `json:"message_content"`
+	ReasoningContent string `json:"reasoning_content"`
+
+	// FirstTokenAt is when the model produced its first content or reasoning
+	// token, nil if it produced nothing.
+	FirstTokenAt *time.Time `json:"first_token_at"`
+}
+
+// StreamConversationMessageEvent is a fragment of a streamed response. Only one
+// of the fields is set per event.
+type StreamConversationMessageEvent struct {
+	MessageContent   string `json:"message_content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+type CreateProviderRequest struct {
+	ProviderID     string           `json:"provider_id"`
+	Type           ProviderType     `json:"type"`
+	Name           string           `json:"name"`
+	APIKey         string           `json:"api_key"`
+	OrganizationID string           `json:"organization_id"`
+	EndpointURL    string           `json:"endpoint_url"`
+	Models         []*ProviderModel `json:"models"`
+}
+
+type CreateProviderResponse struct {
+	Provider
+}
+
+type ListProvidersResponse struct {
+	Providers []*Provider `json:"providers"`
+}
+
+type UpdateProviderRequest struct {
+	ProviderID string `json:"provider_id"`
+	Name       string `json:"name"`
+
+	// APIKey is left as it is when nil, and removed when empty.
+	APIKey         *string          `json:"api_key"`
+	OrganizationID string           `json:"organization_id"`
+	EndpointURL    string           `json:"endpoint_url"`
+	Models         []*ProviderModel `json:"models"`
+}
+
+type UpdateProviderResponse struct {
+	Provider
+}
+
+type DeleteProviderRequest struct {
+	ProviderID string `json:"provider_id"`
+}
+
+type TestProviderRequest struct {
+	ProviderID string `json:"provider_id"`
 }
@@ -3,7 +3,10 @@ package app
 import (
 	"context"
 
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
 	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
 	"github.com/0xdeafcafe/bloefish/services/conversation"
 	"github.com/0xdeafcafe/bloefish/services/fileupload"
@@ -41,12 +44,36 @@ For all messages that are not related to generating titles, you must end with th
 `
 
 	systemTitleInstructionMessage = `
+`
+
+	systemStructuredOutputInstructionMessage = `
+You are a generic AI assistant named "Bloefish", being called by a program that needs machine-readable output.
+
+Respond only with a single JSON value that matches the JSON schema you have been given. Do not include any other text, explanations, Markdown formatting or code fences.
 `
 )
 
 type App struct {
 	Relay *relay.Client
 
+	ProviderRepository ports.ProviderRepository
+	SeedRepository     ports.SeedRepository
+
+	ChatMetrics *ChatMetrics
+
+	// Auditor records every invocation of a model. Invocations aren't audited
+	// if it's nil.
+	Auditor *Auditor
+
+	// Guardrails are LangWatch evaluators run on each chat's input and output.
+	Langwatch  langwatch.Client
+	Guardrails []*models.Guardrail
+
+	// TranscriptionProviderID and TranscriptionModelID are used to transcribe
+	// audio files. Audio files aren't transcribed if no provider is set.
+	TranscriptionProviderID string
+	TranscriptionModelID    string
+
 	ConversationService conversation.Service
 	FileUploadService   fileupload.Service
 	StreamService       stream.Service
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+const defaultAuditRedactionReplacement = "[REDACTED]"
+
+var auditFields = []models.AuditField{
+	models.AuditFieldActor,
+	models.AuditFieldMessages,
+	models.AuditFieldOutput,
+	models.AuditFieldReasoning,
+	models.AuditFieldError,
+}
+
+// Auditor records every invocation of a model to the audit repository, after
+// redacting it. A nil Auditor records nothing.
+type Auditor struct {
+	repository ports.AuditRepository
+	rules      []*auditRedactionRule
+}
+
+type auditRedactionRule struct {
+	fields      []models.AuditField
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func NewAuditor(repository ports.AuditRepository, rules []*models.AuditRedactionRule) (*Auditor, error) {
+	compiledRules := make([]*auditRedactionRule, len(rules))
+	for i, rule := range rules {
+		for _, field := range rule.Fields {
+			if !slices.Contains(auditFields, field) {
+				return nil, fmt.Errorf("redaction rule %d has unknown field %q", i, field)
+			}
+		}
+		if rule.Pattern == "" && len(rule.Fields) == 0 {
+			return nil, fmt.Errorf("redaction rule %d needs a pattern or fields", i)
+		}
+
+		compiledRule := &auditRedactionRule{
+			fields:      rule.Fields,
+			replacement: rule.Replacement,
+		}
+		if len(compiledRule.fields) == 0 {
+			compiledRule.fields = auditFields
+		}
+		if compiledRule.replacement == "" {
+			compiledRule.replacement = defaultAuditRedactionReplacement
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redaction rule %d has an invalid pattern: %w", i, err)
+			}
+
+			compiledRule.pattern = pattern
+		}
+
+		compiledRules[i] = compiledRule
+	}
+
+	return &Auditor{
+		repository: repository,
+		rules:      compiledRules,
+	}, nil
+}
+
+type auditCommand struct {
+	Owner          *models.Actor
+	ConversationID string
+	MessageID      string
+	ProviderID     string
+	ModelID        string
+	Messages       []*models.AuditMessage
+	StartedAt      time.Time
+}
+
+// record redacts and records an invocation. Failing to record it is logged
+// rather than returned, so chats keep working when the audit sink doesn't.
+func (a *Auditor) record(ctx context.Context, cmd *auditCommand, chatStream relay.ChatStreamIterator, err error) {
+	if a == nil {
+		return
+	}
+
+	entry := &models.AuditEntry{
+		ID:             ksuid.Generate(ctx, "auditentry").String(),
+		Actor:          cmd.Owner,
+		ConversationID: cmd.ConversationID,
+		MessageID:      cmd.MessageID,
+		ProviderID:     cmd.ProviderID,
+		ModelID:        cmd.ModelID,
+		Messages:       make([]*models.AuditMessage, len(cmd.Messages)),
+		StartedAt:      cmd.StartedAt,
+		FinishedAt:     time.Now(),
+	}
+
+	// Messages are copied, as redacting them mustn't change the command
+	for i, message := range cmd.Messages {
+		entry.Messages[i] = &models.AuditMessage{
+			Role:    message.Role,
+			Content: message.Content,
+			Files:   make([]*models.AuditFile, len(message.Files)),
+		}
+		for j, file := range message.Files {
+			copied := *file
+			entry.Messages[i].Files[j] = &copied
+		}
+	}
+
+	if chatStream != nil {
+		entry.Output = chatStream.Content()
+		entry.Reasoning = chatStream.Reasoning()
+
+		if usage := chatStream.Usage(); usage != nil {
+			entry.Usage = &models.AuditUsage{
+				PromptTokens:     usage.PromptTokens,
+				CompletionTokens: usage.CompletionTokens,
+			}
+		}
+	}
+
+	if err != nil {
+		cErr := cher.Coerce(err)
+		entry.Error = &cErr
+	}
+
+	a.redact(entry)
+
+	// The entry is still recorded if the request was cancelled part way through
+	if err := a.repository.Record(context.WithoutCancel(ctx), entry); err != nil {
+		clog.Get(ctx).WithError(err).Warn("failed to record audit entry")
+	}
+}
+
+func (a *Auditor) redact(entry *models.AuditEntry) {
+	for _, rule := range a.rules {
+		for _, field := range rule.fields {
+			switch field {
+			case models.AuditFieldActor:
+				if entry.Actor != nil {
+					actor := *entry.Actor
+					actor.Identifier = rule.redact(actor.Identifier)
+					entry.Actor = &actor
+				}
+			case models.AuditFieldMessages:
+				for _, message := range entry.Messages {
+					message.Content = rule.redact(message.Content)
+					for _, file := range message.Files {
+						file.Name = rule.redact(file.Name)
+					}
+				}
+			case models.AuditFieldOutput:
+				entry.Output = rule.redact(entry.Output)
+			case models.AuditFieldReasoning:
+				entry.Reasoning = rule.redact(entry.Reasoning)
+			case models.AuditFieldError:
+				if entry.Error != nil {
+					entry.Error.Meta = rule.redactMeta(entry.Error.Meta)
+				}
+			}
+		}
+	}
+}
+
+func (r *auditRedactionRule) redact(value string) string {
+	if value == "" {
+		return value
+	}
+	if r.pattern == nil {
+		return r.replacement
+	}
+
+	return r.pattern.ReplaceAllString(value, r.replacement)
+}
+
+// redactMeta redacts the string values of error metadata, which can include
+// content such as the details of a failed guardrail.
+func (r *auditRedactionRule) redactMeta(meta cher.M) cher.M {
+	if meta == nil {
+		return nil
+	}
+
+	redacted := make(cher.M, len(meta))
+	for key, value := range meta {
+		switch value := value.(type) {
+		case string:
+			redacted[key] = r.redact(value)
+		default:
+			if r.pattern == nil && value != nil {
+				redacted[key] = r.replacement
+			} else {
+				redacted[key] = value
+			}
+		}
+	}
+
+	return redacted
+}
+
+// audit wraps a chat stream so the invocation can be recorded with finish
+// once the caller is done with it.
+func (a *Auditor) audit(cmd *auditCommand, chatStream relay.ChatStreamIterator) relay.ChatStreamIterator {
+	if a == nil {
+		return chatStream
+	}
+
+	return &auditedChatStreamIterator{
+		ChatStreamIterator: chatStream,
+
+		cmd: cmd,
+	}
+}
+
+// finish records the invocation of an audited chat stream. It's called with
+// the error the invocation failed with, if any, rather than relying on the
+// stream being read to the end, so invocations abandoned part way through are
+// still recorded.
+func (a *Auditor) finish(ctx context.Context, chatStream relay.ChatStreamIterator, err error) {
+	audited, ok := chatStream.(*auditedChatStreamIterator)
+	if !ok {
+		return
+	}
+
+	a.record(ctx, audited.cmd, audited.ChatStreamIterator, err)
+}
+
+type auditedChatStreamIterator struct {
+	relay.ChatStreamIterator
+
+	cmd *auditCommand
+}
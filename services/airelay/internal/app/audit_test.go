@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+type recordingAuditRepository struct {
+	entries []*models.AuditEntry
+}
+
+func (r *recordingAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// finishedChatStream is a chat stream which has already been read to the end.
+type finishedChatStream struct {
+	content   string
+	reasoning string
+	usage     *relay.Usage
+}
+
+func (s *finishedChatStream) Next() bool                      { return false }
+func (s *finishedChatStream) Current() *relay.ChatStreamEvent { return nil }
+func (s *finishedChatStream) Content() string                 { return s.content }
+func (s *finishedChatStream) Reasoning() string               { return s.reasoning }
+func (s *finishedChatStream) Usage() *relay.Usage             { return s.usage }
+func (s *finishedChatStream) Err() error                      { return nil }
+
+func newTestAuditCommand() *auditCommand {
+	sha256 := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	return &auditCommand{
+		Owner: &models.Actor{
+			Type:       models.ActorTypeUser,
+			Identifier: "jane@example.com",
+		},
+		ConversationID: "conversation_1",
+		MessageID:      "message_1",
+		ProviderID:     "open_ai",
+		ModelID:        "gpt-4o",
+		Messages: []*models.AuditMessage{
+			{Role: "system", Content: "Be helpful"},
+			{
+				Role:    "user",
+				Content: "Email jane@example.com the report",
+				Files: []*models.AuditFile{{
+					ID:       "file_1",
+					Name:     "jane@example.com.pdf",
+					MIMEType: "application/pdf",
+					Size:     4,
+					SHA256:   &sha256,
+				}},
+			},
+		},
+		StartedAt: time.Now(),
+	}
+}
+
+func TestNewAuditor(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Rules []*models.AuditRedactionRule
+
+		Valid bool
+	}{
+		{"NoRules", nil, true},
+		{"Pattern", []*models.AuditRedactionRule{{Pattern: `\d+`}}, true},
+		{"Fields", []*models.AuditRedactionRule{{Fields: []models.AuditField{models.AuditFieldOutput}}}, true},
+		{"UnknownField", []*models.AuditRedactionRule{{Fields: []models.AuditField{"usage"}, Pattern: `\d+`}}, false},
+		{"InvalidPattern", []*models.AuditRedactionRule{{Pattern: `(`}}, false},
+		{"NoPatternOrFields", []*models.AuditRedactionRule{{Replacement: "x"}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := NewAuditor(&recordingAuditRepository{}, test.Rules)
+			is.Equal(err == nil, test.Valid)
+		})
+	}
+}
+
+func TestAuditorRecord(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WithoutRules", func(t *testing.T) {
+		is := is.New(t)
+		repository := &recordingAuditRepository{}
+		auditor, err := NewAuditor(repository, nil)
+		is.NoErr(err)
+
+		cmd := newTestAuditCommand()
+		auditor.record(ctx, cmd, &finishedChatStream{
+			content:   "Sent",
+			reasoning: "Thinking",
+			usage:     &relay.Usage{PromptTokens: 10, CompletionTokens: 2},
+		}, nil)
+
+		is.Equal(len(repository.entries), 1)
+		entry := repository.entries[0]
+
+		is.True(entry.ID != "")
+		is.Equal(entry.Actor, cmd.Owner)
+		is.Equal(entry.ConversationID, "conversation_1")
+		is.Equal(len(entry.Messages), 2)
+		is.Equal(entry.Messages[0].Content, "Be helpful")
+		is.Equal(entry.Messages[1].Content, "Email jane@example.com the report")
+		is.Equal(entry.Messages[1].Files, cmd.Messages[1].Files) // files are recorded by reference
+		is.Equal(entry.Output, "Sent")
+		is.Equal(entry.Reasoning, "Thinking")
+		is.Equal(entry.Usage, &models.AuditUsage{PromptTokens: 10, CompletionTokens: 2})
+		is.Equal(entry.Error, nil)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		is := is.New(t)
+		repository := &recordingAuditRepository{}
+		auditor, err := NewAuditor(repository, nil)
+		is.NoErr(err)
+
+		auditor.record(ctx, newTestAuditCommand(), nil, cher.New("guardrail_failed", nil))
+
+		is.Equal(len(repository.entries), 1)
+		is.Equal(repository.entries[0].Error.Code, "guardrail_failed")
+		is.Equal(repository.entries[0].Output, "") // invocations which failed before streaming have no output
+	})
+
+	t.Run("Redaction", func(t *testing.T) {
+		is := is.New(t)
+		repository := &recordingAuditRepository{}
+		auditor, err := NewAuditor(repository, []*models.AuditRedactionRule{
+			// Every field
+			{Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`},
+			// A whole field, with a custom replacement
+			{Fields: []models.AuditField{models.AuditFieldReasoning}, Replacement: "[HIDDEN]"},
+		})
+		is.NoErr(err)
+
+		cmd := newTestAuditCommand()
+		auditor.record(ctx, cmd, &finishedChatStream{
+			content:   "I've emailed jane@example.com",
+			reasoning: "jane is the user",
+		}, cher.New("guardrail_failed", cher.M{
+			"details":  "found jane@example.com",
+			"attempts": 2,
+		}))
+
+		is.Equal(len(repository.entries), 1)
+		entry := repository.entries[0]
+
+		is.Equal(entry.Actor.Identifier, "[REDACTED]")
+		is.Equal(entry.Messages[0].Content, "Be helpful")
+		is.Equal(entry.Messages[1].Content, "Email [REDACTED] the report")
+		is.Equal(entry.Messages[1].Files[0].Name, "[REDACTED]")
+		is.Equal(entry.Messages[1].Files[0].ID, "file_1") // file references aren't redacted
+		is.Equal(entry.Output, "I've emailed [REDACTED]")
+		is.Equal(entry.Reasoning, "[HIDDEN]")
+		is.Equal(entry.Error.Meta["details"], "found [REDACTED]")
+		is.Equal(entry.Error.Meta["attempts"], 2) // patterns only apply to strings
+
+		is.Equal(cmd.Owner.Identifier, "jane@example.com")                     // the command isn't redacted
+		is.Equal(cmd.Messages[1].Content, "Email jane@example.com the report") // the command isn't redacted
+		is.Equal(cmd.Messages[1].Files[0].Name, "jane@example.com.pdf")        // the command isn't redacted
+	})
+
+	t.Run("WholeErrorMeta", func(t *testing.T) {
+		is := is.New(t)
+		repository := &recordingAuditRepository{}
+		auditor, err := NewAuditor(repository, []*models.AuditRedactionRule{
+			{Fields: []models.AuditField{models.AuditFieldError}},
+		})
+		is.NoErr(err)
+
+		auditor.record(ctx, newTestAuditCommand(), nil, cher.New("guardrail_failed", cher.M{
+			"details":  "found jane@example.com",
+			"attempts": 2,
+			"missing":  nil,
+		}))
+
+		meta := repository.entries[0].Error.Meta
+		is.Equal(meta["details"], "[REDACTED]")
+		is.Equal(meta["attempts"], "[REDACTED]")
+		is.Equal(meta["missing"], nil)
+	})
+
+	t.Run("NilAuditor", func(t *testing.T) {
+		var auditor *Auditor
+
+		auditor.record(ctx, newTestAuditCommand(), nil, nil)
+		auditor.finish(ctx, auditor.audit(newTestAuditCommand(), &finishedChatStream{}), nil)
+	})
+}
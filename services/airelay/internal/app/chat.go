@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/openai/openai-go"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+type newChatStreamCommand struct {
+	ConversationID string
+	MessageID      string
+	Owner          *airelay.Actor
+	Messages       []*airelay.InvokeConversationMessageRequestMessage
+	AIRelayOptions *airelay.InvokeConversationMessageRequestAIRelayOptions
+	ResponseFormat *airelay.ResponseFormat
+}
+
+func (a *App) newChatStream(ctx context.Context, cmd *newChatStreamCommand) (relay.ChatStreamIterator, error) {
+	fileIDs := []string{}
+	for _, msg := range cmd.Messages {
+		fileIDs = append(fileIDs, msg.FileIDs...)
+	}
+
+	downloadedFiles, err := a.downloadFiles(ctx, cmd.Owner, fileIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]relay.Message, len(cmd.Messages))
+	auditMessages := make([]*models.AuditMessage, len(cmd.Messages))
+	for i, msg := range cmd.Messages {
+		fileContent := ""
+		auditFiles := make([]*models.AuditFile, 0, len(msg.FileIDs))
+
+		if len(msg.FileIDs) > 0 {
+			for _, fileID := range msg.FileIDs {
+				file := downloadedFiles[fileID]
+				if file == nil {
+					return nil, cher.New("file_missing_from_downloads", cher.M{
+						"file_id":         fileID,
+						"downloads_count": len(downloadedFiles),
+						"message_index":   i,
+					})
+				}
+
+				auditFiles = append(auditFiles, &models.AuditFile{
+					ID:       file.ID,
+					Name:     file.Name,
+					MIMEType: file.MIMEType,
+					Size:     file.Size,
+					SHA256:   file.SHA256,
+				})
+
+				if file.DeletedAt != nil {
+					fileContent += fmt.Sprintf("\n\nFile name: %s\nThis file has since been deleted, so its content is no longer available.", file.Name)
+					continue
+				}
+
+				if isAudioFile(&file.File) {
+					if file.Transcript == nil {
+						fileContent += fmt.Sprintf("\n\nFile name: %s\nThis audio file couldn't be transcribed, so its content isn't available.", file.Name)
+						continue
+					}
+
+					fileContent += fmt.Sprintf("\n\nFile name: %s\nAudio transcript:\n%s", file.Name, file.Transcript.Text)
+					continue
+				}
+
+				fileContent += fmt.Sprintf("\n\nFile name: %s\nFile content:\n%s", file.Name, string(file.Content))
+			}
+		}
+
+		switch msg.Owner.Type {
+		case airelay.ActorTypeBot:
+			messages[i] = relay.Message{
+				Role:    relay.RoleAssistant,
+				Content: msg.Content + fileContent,
+			}
+		case airelay.ActorTypeUser:
+			messages[i] = relay.Message{
+				Role:    relay.RoleUser,
+				Content: msg.Content + fileContent,
+			}
+		}
+
+		auditMessages[i] = &models.AuditMessage{
+			Role:    string(messages[i].Role),
+			Content: msg.Content,
+			Files:   auditFiles,
+		}
+	}
+
+	auditCmd := &auditCommand{
+		ConversationID: cmd.ConversationID,
+		MessageID:      cmd.MessageID,
+		ProviderID:     cmd.AIRelayOptions.ProviderID,
+		ModelID:        cmd.AIRelayOptions.ModelID,
+		Messages:       auditMessages,
+		StartedAt:      time.Now(),
+	}
+	if cmd.Owner != nil {
+		auditCmd.Owner = &models.Actor{
+			Type:       models.ActorType(cmd.Owner.Type),
+			Identifier: cmd.Owner.Identifier,
+		}
+	}
+
+	// Earlier messages were checked when they were sent, so only the latest
+	// message from the user needs checking
+	var input string
+	for _, msg := range slices.Backward(messages) {
+		if msg.Role == relay.RoleUser {
+			input = msg.Content
+			break
+		}
+	}
+
+	if err := a.runGuardrails(ctx, models.GuardrailStageInput, langwatch.EvaluationData{Input: input}); err != nil {
+		a.Auditor.record(ctx, auditCmd, nil, err)
+
+		return nil, err
+	}
+
+	// The regular system instructions ask for markdown and a sign-off, which
+	// would break structured output.
+	systemInstruction := systemInstructionMessage
+	var responseFormat *relay.ResponseFormat
+	if cmd.ResponseFormat != nil {
+		systemInstruction = systemStructuredOutputInstructionMessage
+		responseFormat = &relay.ResponseFormat{
+			Name:   cmd.ResponseFormat.Name,
+			Schema: cmd.ResponseFormat.Schema,
+		}
+	}
+
+	messages = append([]relay.Message{{
+		Role:    relay.RoleSystem,
+		Content: systemInstruction,
+	}}, messages...)
+
+	// Invocations which reach the model are audited with the system
+	// instructions they were sent with
+	auditCmd.Messages = append([]*models.AuditMessage{{
+		Role:    string(relay.RoleSystem),
+		Content: systemInstruction,
+	}}, auditMessages...)
+
+	chatStream, err := a.Relay.With(cmd.AIRelayOptions.ProviderID).NewChatStream(ctx, relay.ChatStreamParams{
+		ModelID:        cmd.AIRelayOptions.ModelID,
+		Messages:       messages,
+		IncludeUsage:   true,
+		ResponseFormat: responseFormat,
+	})
+	if err != nil {
+		if errors.Is(err, relay.ErrRequiredProviderMissing) || errors.Is(err, relay.ErrChatUnsupported) {
+			err = cher.New("unsupported_ai_provider", cher.M{
+				"provider_id": cmd.AIRelayOptions.ProviderID,
+			})
+		}
+
+		a.Auditor.record(ctx, auditCmd, nil, err)
+
+		return nil, err
+	}
+
+	chatStream = a.ChatMetrics.instrument(ctx, cmd.AIRelayOptions.ProviderID, cmd.AIRelayOptions.ModelID, chatStream)
+
+	return a.Auditor.audit(auditCmd, a.guard(ctx, input, chatStream)), nil
+}
+
+func coerceChatStreamError(err error, aiRelayOptions *airelay.InvokeConversationMessageRequestAIRelayOptions) cher.E {
+	var apierr *openai.Error
+	if errors.As(err, &apierr) {
+		switch apierr.StatusCode {
+		case http.StatusNotFound:
+			return cher.New("ai_model_not_found", cher.M{
+				"model_id": aiRelayOptions.ModelID,
+			})
+		}
+	}
+
+	return cher.Coerce(err)
+}
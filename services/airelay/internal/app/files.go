@@ -29,7 +29,9 @@ func (a *App) downloadFiles(ctx context.Context, owner *airelay.Actor, fileIDs [
 			Type:       fileupload.ActorType(owner.Type),
 			Identifier: owner.Identifier,
 		},
-		AllowDeleted:     false,
+		// Files deleted since they were sent are still part of the
+		// conversation, they just can't be read any more
+		AllowDeleted:     true,
 		IncludeAccessURL: true,
 	})
 	if err != nil {
@@ -46,6 +48,16 @@ func (a *App) downloadFiles(ctx context.Context, owner *airelay.Actor, fileIDs [
 	mu := sync.Mutex{}
 
 	for _, file := range files.Files {
+		// Deleted files can't be read, and audio files which have already
+		// been transcribed don't need to be
+		if file.DeletedAt != nil || (isAudioFile(file) && file.Transcript != nil) {
+			mu.Lock()
+			downloadedFiles[file.ID] = &downloadedFile{File: *file}
+			mu.Unlock()
+
+			continue
+		}
+
 		egGroup.Go(func() error {
 			req, err := http.NewRequestWithContext(egCtx, http.MethodGet, *file.PresignedAccessURL, nil)
 			if err != nil {
@@ -63,12 +75,19 @@ func (a *App) downloadFiles(ctx context.Context, owner *airelay.Actor, fileIDs [
 				return err
 			}
 
-			mu.Lock()
-			defer mu.Unlock()
-			downloadedFiles[file.ID] = &downloadedFile{
+			downloaded := &downloadedFile{
 				File:    *file,
 				Content: content,
 			}
+			if isAudioFile(file) {
+				if err := a.transcribeFile(egCtx, downloaded); err != nil {
+					return err
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			downloadedFiles[file.ID] = downloaded
 
 			return nil
 		})
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"slices"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/libraries/errfuncs"
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+const errGuardrailFailed = "guardrail_failed"
+
+func isGuardrailFailure(err error) bool {
+	cErr, ok := errfuncs.As[cher.E](err)
+	return ok && cErr.Code == errGuardrailFailed
+}
+
+func (a *App) hasGuardrails(stage models.GuardrailStage) bool {
+	return slices.ContainsFunc(a.Guardrails, func(guardrail *models.Guardrail) bool {
+		return slices.Contains(guardrail.Stages, stage)
+	})
+}
+
+func (a *App) hasBlockingGuardrails(stage models.GuardrailStage) bool {
+	return slices.ContainsFunc(a.Guardrails, func(guardrail *models.Guardrail) bool {
+		return guardrail.Blocking && slices.Contains(guardrail.Stages, stage)
+	})
+}
+
+// runGuardrails runs the guardrails for a stage of a chat, and returns a
+// guardrail_failed error if a blocking guardrail doesn't pass. Guardrails which
+// can't be evaluated are logged and let the chat through, so chats keep
+// working when LangWatch doesn't.
+func (a *App) runGuardrails(ctx context.Context, stage models.GuardrailStage, data langwatch.EvaluationData) error {
+	for _, guardrail := range a.Guardrails {
+		if !slices.Contains(guardrail.Stages, stage) {
+			continue
+		}
+
+		log := clog.Get(ctx).
+			WithField("guardrail", guardrail.Name).
+			WithField("guardrail_stage", stage)
+
+		result, err := a.Langwatch.Evaluate(ctx, langwatch.EvaluateParams{
+			Evaluator:   guardrail.Evaluator,
+			Name:        guardrail.Name,
+			Data:        data,
+			Settings:    guardrail.Settings,
+			AsGuardrail: guardrail.Blocking,
+		})
+		if err != nil {
+			log.WithError(err).Warn("failed to run guardrail")
+			continue
+		}
+
+		switch {
+		case result.Status == langwatch.EvaluationStatusError:
+			if result.Details != nil {
+				log = log.WithField("details", *result.Details)
+			}
+
+			log.Warn("guardrail failed to evaluate")
+		case guardrail.Blocking && result.Status == langwatch.EvaluationStatusProcessed && result.Passed != nil && !*result.Passed:
+			meta := cher.M{
+				"guardrail": guardrail.Name,
+				"stage":     stage,
+			}
+			if result.Details != nil {
+				meta["details"] = *result.Details
+			}
+
+			return cher.New(errGuardrailFailed, meta)
+		}
+	}
+
+	return nil
+}
+
+// guard wraps a chat stream so the output guardrails are run once it has
+// finished. A failed guardrail is returned by the stream's Err. When a blocking
+// guardrail could fail the answer, it's held back until the guardrails have
+// passed, so nothing which is then blocked is ever sent.
+func (a *App) guard(ctx context.Context, input string, chatStream relay.ChatStreamIterator) relay.ChatStreamIterator {
+	if !a.hasGuardrails(models.GuardrailStageOutput) {
+		return chatStream
+	}
+
+	return &guardedChatStreamIterator{
+		ChatStreamIterator: chatStream,
+
+		ctx:   ctx,
+		app:   a,
+		input: input,
+		hold:  a.hasBlockingGuardrails(models.GuardrailStageOutput),
+	}
+}
+
+type guardedChatStreamIterator struct {
+	relay.ChatStreamIterator
+
+	ctx   context.Context
+	app   *App
+	input string
+	hold  bool
+
+	current *relay.ChatStreamEvent
+	checked bool
+	err     error
+}
+
+func (i *guardedChatStreamIterator) Next() bool {
+	for i.ChatStreamIterator.Next() {
+		event := i.ChatStreamIterator.Current()
+		if !i.hold {
+			i.current = event
+
+			return true
+		}
+
+		// Guardrails only check the answer, so reasoning is still streamed as
+		// it arrives
+		if event.Reasoning != "" {
+			i.current = &relay.ChatStreamEvent{Reasoning: event.Reasoning}
+
+			return true
+		}
+	}
+
+	if i.checked || i.ChatStreamIterator.Err() != nil {
+		return false
+	}
+
+	i.checked = true
+	i.err = i.app.runGuardrails(i.ctx, models.GuardrailStageOutput, langwatch.EvaluationData{
+		Input:  i.input,
+		Output: i.Content(),
+	})
+	if i.err != nil || !i.hold {
+		return false
+	}
+
+	// The answer which was held back is released all at once
+	content := i.Content()
+	if content == "" {
+		return false
+	}
+
+	i.current = &relay.ChatStreamEvent{
+		Content: content,
+		Done:    true,
+	}
+
+	return true
+}
+
+func (i *guardedChatStreamIterator) Current() *relay.ChatStreamEvent {
+	return i.current
+}
+
+func (i *guardedChatStreamIterator) Err() error {
+	if err := i.ChatStreamIterator.Err(); err != nil {
+		return err
+	}
+
+	return i.err
+}
@@ -2,32 +2,50 @@ package app
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/services/airelay"
 )
 
-func (a *App) InvokeConversationMessage(ctx context.Context, req *airelay.InvokeConversationMessageRequest) (*airelay.InvokeConversationMessageResponse, error) {
-	return nil, fmt.Errorf("not implemented")
-	// chatCompletionMessages, err := a.prepareOpenAIChatCompletionMessages(ctx, req.AIRelayOptions, req.Messages)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to prepare OpenAI chat completion messages: %w", err)
-	// }
-
-	// completion, err := a.OpenAI.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-	// 	Messages: openai.F(chatCompletionMessages),
-	// 	Seed:     openai.Int(1),
-	// 	Model:    openai.F(req.AIRelayOptions.ModelID),
-	// })
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to create completion: %w", err)
-	// }
-
-	// if len(completion.Choices) == 0 {
-	// 	return nil, fmt.Errorf("no choices in completion")
-	// }
-
-	// return &airelay.InvokeConversationMessageResponse{
-	// 	MessageContent: completion.Choices[0].Message.Content,
-	// }, nil
+func (a *App) InvokeConversationMessage(ctx context.Context, req *airelay.InvokeConversationMessageRequest) (resp *airelay.InvokeConversationMessageResponse, err error) {
+	responseFormatSchema, err := compileResponseFormat(req.ResponseFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	chatStream, err := a.newChatStream(ctx, &newChatStreamCommand{
+		ConversationID: req.ConversationID,
+		MessageID:      req.MessageID,
+		Owner:          req.Owner,
+		Messages:       req.Messages,
+		AIRelayOptions: req.AIRelayOptions,
+		ResponseFormat: req.ResponseFormat,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { a.Auditor.finish(ctx, chatStream, err) }()
+
+	// Drain the stream, the iterator accumulates the content for us
+	var firstTokenAt *time.Time
+	for chatStream.Next() {
+		if event := chatStream.Current(); firstTokenAt == nil && (event.Content != "" || event.Reasoning != "") {
+			now := time.Now()
+			firstTokenAt = &now
+		}
+	}
+
+	if err := chatStream.Err(); err != nil {
+		return nil, coerceChatStreamError(err, req.AIRelayOptions)
+	}
+
+	if err := validateResponseFormat(responseFormatSchema, req.ResponseFormat, chatStream.Content()); err != nil {
+		return nil, err
+	}
+
+	return &airelay.InvokeConversationMessageResponse{
+		MessageContent:   chatStream.Content(),
+		ReasoningContent: chatStream.Reasoning(),
+		FirstTokenAt:     firstTokenAt,
+	}, nil
 }
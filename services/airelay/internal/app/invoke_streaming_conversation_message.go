@@ -2,93 +2,72 @@ package app
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"net/http"
 	"strings"
-
-	"github.com/openai/openai-go"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/services/airelay"
-	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
 	"github.com/0xdeafcafe/bloefish/services/stream"
 )
 
-func (a *App) InvokeStreamingConversationMessage(ctx context.Context, req *airelay.InvokeStreamingConversationMessageRequest) (*airelay.InvokeStreamingConversationMessageResponse, error) {
-	fileIDs := []string{}
-	for _, msg := range req.Messages {
-		fileIDs = append(fileIDs, msg.FileIDs...)
-	}
-
-	downloadedFiles, err := a.downloadFiles(ctx, req.Owner, fileIDs)
+func (a *App) InvokeStreamingConversationMessage(ctx context.Context, req *airelay.InvokeStreamingConversationMessageRequest) (resp *airelay.InvokeStreamingConversationMessageResponse, err error) {
+	responseFormatSchema, err := compileResponseFormat(req.ResponseFormat)
 	if err != nil {
 		return nil, err
 	}
 
-	messages := make([]relay.Message, len(req.Messages))
-	for i, msg := range req.Messages {
-		fileContent := ""
-
-		if len(msg.FileIDs) > 0 {
-			for _, fileID := range msg.FileIDs {
-				file := downloadedFiles[fileID]
-				if file == nil {
-					return nil, cher.New("file_missing_from_downloads", cher.M{
-						"file_id":         fileID,
-						"downloads_count": len(downloadedFiles),
-						"message_index":   i,
-					})
-				}
-
-				fileContent += fmt.Sprintf("\n\nFile name: %s\nFile content:\n%s", file.Name, string(file.Content))
-			}
-		}
-
-		switch msg.Owner.Type {
-		case airelay.ActorTypeBot:
-			messages[i] = relay.Message{
-				Role:    relay.RoleAssistant,
-				Content: msg.Content + fileContent,
-			}
-		case airelay.ActorTypeUser:
-			messages[i] = relay.Message{
-				Role:    relay.RoleUser,
-				Content: msg.Content + fileContent,
-			}
-		}
-	}
-
-	messages = append([]relay.Message{{
-		Role:    relay.RoleSystem,
-		Content: systemInstructionMessage,
-	}}, messages...)
-
-	chatStream, err := a.Relay.With(req.AIRelayOptions.ProviderID).NewChatStream(ctx, relay.ChatStreamParams{
-		ModelID:      req.AIRelayOptions.ModelID,
-		Messages:     messages,
-		IncludeUsage: true,
+	chatStream, err := a.newChatStream(ctx, &newChatStreamCommand{
+		ConversationID: req.ConversationID,
+		MessageID:      req.MessageID,
+		Owner:          req.Owner,
+		Messages:       req.Messages,
+		AIRelayOptions: req.AIRelayOptions,
+		ResponseFormat: req.ResponseFormat,
 	})
 	if err != nil {
-		if errors.Is(err, relay.ErrRequiredProviderMissing) {
-			return nil, cher.New("unsupported_ai_provider", cher.M{
-				"provider_id": req.AIRelayOptions.ProviderID,
-			})
+		if isGuardrailFailure(err) {
+			if err := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
+				ChannelID: req.StreamingChannelID,
+				Error:     cher.Coerce(err),
+			}); err != nil {
+				return nil, fmt.Errorf("failed to send error message: %w", err)
+			}
 		}
 
 		return nil, err
 	}
+	defer func() { a.Auditor.finish(ctx, chatStream, err) }()
 
 	iterationCount := 0
-	var contentBuffer strings.Builder
+	var contentBuffer, reasoningBuffer strings.Builder
+	var firstTokenAt *time.Time
 
 	for chatStream.Next() {
 		iterationCount++
 		event := chatStream.Current()
 
+		if firstTokenAt == nil && (event.Content != "" || event.Reasoning != "") {
+			now := time.Now()
+			firstTokenAt = &now
+		}
+
 		if event.Content != "" {
 			contentBuffer.WriteString(event.Content)
 		}
+		if event.Reasoning != "" {
+			reasoningBuffer.WriteString(event.Reasoning)
+		}
+
+		if iterationCount%10 == 0 && reasoningBuffer.Len() > 0 {
+			if err := a.StreamService.SendReasoningFragment(ctx, &stream.SendReasoningFragmentRequest{
+				ChannelID:        req.StreamingChannelID,
+				ReasoningContent: reasoningBuffer.String(),
+			}); err != nil {
+				return nil, fmt.Errorf("failed to send reasoning fragment chunk: %w", err)
+			}
+			reasoningBuffer.Reset()
+		}
 
 		if iterationCount%10 == 0 && contentBuffer.Len() > 0 {
 			if err := a.StreamService.SendMessageFragment(ctx, &stream.SendMessageFragmentRequest{
@@ -101,6 +80,15 @@ func (a *App) InvokeStreamingConversationMessage(ctx context.Context, req *airel
 		}
 	}
 
+	if reasoningBuffer.Len() > 0 {
+		if err := a.StreamService.SendReasoningFragment(ctx, &stream.SendReasoningFragmentRequest{
+			ChannelID:        req.StreamingChannelID,
+			ReasoningContent: reasoningBuffer.String(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to send final reasoning fragment: %w", err)
+		}
+	}
+
 	if contentBuffer.Len() > 0 {
 		if err := a.StreamService.SendMessageFragment(ctx, &stream.SendMessageFragmentRequest{
 			ChannelID:      req.StreamingChannelID,
@@ -111,20 +99,7 @@ func (a *App) InvokeStreamingConversationMessage(ctx context.Context, req *airel
 	}
 
 	if err := chatStream.Err(); err != nil {
-		var coercedError cher.E
-
-		var apierr *openai.Error
-		if errors.As(err, &apierr) {
-			switch apierr.StatusCode {
-			case http.StatusNotFound:
-				coercedError = cher.New("ai_model_not_found", cher.M{
-					"model_id": req.AIRelayOptions.ModelID,
-				})
-			}
-		}
-		if coercedError.Code == "" {
-			coercedError = cher.Coerce(err)
-		}
+		coercedError := coerceChatStreamError(err, req.AIRelayOptions)
 
 		if err := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
 			ChannelID: req.StreamingChannelID,
@@ -136,7 +111,20 @@ func (a *App) InvokeStreamingConversationMessage(ctx context.Context, req *airel
 		return nil, coercedError
 	}
 
+	if err := validateResponseFormat(responseFormatSchema, req.ResponseFormat, chatStream.Content()); err != nil {
+		if err := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
+			ChannelID: req.StreamingChannelID,
+			Error:     cher.Coerce(err),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to send error message: %w", err)
+		}
+
+		return nil, err
+	}
+
 	return &airelay.InvokeStreamingConversationMessageResponse{
-		MessageContent: chatStream.Content(),
+		MessageContent:   chatStream.Content(),
+		ReasoningContent: chatStream.Reasoning(),
+		FirstTokenAt:     firstTokenAt,
 	}, nil
 }
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ChatMetrics records how many tokens models use and how quickly they respond,
+// per provider and model.
+type ChatMetrics struct {
+	timeToFirstToken metric.Float64Histogram
+	tokensPerSecond  metric.Float64Histogram
+	tokens           metric.Int64Counter
+}
+
+func NewChatMetrics() (*ChatMetrics, error) {
+	meter := otel.Meter("github.com/0xdeafcafe/bloefish/services/airelay")
+
+	timeToFirstToken, err := meter.Float64Histogram(
+		"airelay.chat.time_to_first_token",
+		metric.WithDescription("Time from starting a chat to the model sending its first token"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tokensPerSecond, err := meter.Float64Histogram(
+		"airelay.chat.tokens_per_second",
+		metric.WithDescription("Rate the model generated completion tokens at, after its first token"),
+		metric.WithUnit("{token}/s"),
+		metric.WithExplicitBucketBoundaries(1, 5, 10, 20, 40, 60, 80, 100, 150, 200, 300),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := meter.Int64Counter(
+		"airelay.chat.tokens",
+		metric.WithDescription("Number of tokens used by chats, by token type"),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChatMetrics{
+		timeToFirstToken: timeToFirstToken,
+		tokensPerSecond:  tokensPerSecond,
+		tokens:           tokens,
+	}, nil
+}
+
+// instrument wraps a chat stream so its metrics are recorded as it's read.
+func (m *ChatMetrics) instrument(ctx context.Context, providerID, modelID string, chatStream relay.ChatStreamIterator) relay.ChatStreamIterator {
+	return &instrumentedChatStreamIterator{
+		ChatStreamIterator: chatStream,
+
+		ctx:     ctx,
+		metrics: m,
+		attributes: []attribute.KeyValue{
+			attribute.String("provider_id", providerID),
+			attribute.String("model_id", modelID),
+		},
+
+		startedAt: time.Now(),
+	}
+}
+
+type instrumentedChatStreamIterator struct {
+	relay.ChatStreamIterator
+
+	ctx        context.Context
+	metrics    *ChatMetrics
+	attributes []attribute.KeyValue
+
+	startedAt    time.Time
+	firstTokenAt time.Time
+	finished     bool
+}
+
+func (i *instrumentedChatStreamIterator) Next() bool {
+	if i.ChatStreamIterator.Next() {
+		if event := i.Current(); i.firstTokenAt.IsZero() && (event.Content != "" || event.Reasoning != "") {
+			i.firstTokenAt = time.Now()
+			i.metrics.timeToFirstToken.Record(i.ctx, i.firstTokenAt.Sub(i.startedAt).Seconds(), metric.WithAttributes(i.attributes...))
+		}
+
+		return true
+	}
+
+	if !i.finished {
+		i.finished = true
+		i.recordUsage()
+	}
+
+	return false
+}
+
+func (i *instrumentedChatStreamIterator) recordUsage() {
+	usage := i.Usage()
+	if usage == nil {
+		return
+	}
+
+	i.metrics.tokens.Add(i.ctx, usage.PromptTokens, metric.WithAttributes(append(i.attributes, attribute.String("token_type", "input"))...))
+	i.metrics.tokens.Add(i.ctx, usage.CompletionTokens, metric.WithAttributes(append(i.attributes, attribute.String("token_type", "output"))...))
+
+	// Failed chats would skew the rate, as would chats which never started
+	// generating
+	if i.Err() != nil || i.firstTokenAt.IsZero() || usage.CompletionTokens == 0 {
+		return
+	}
+
+	if elapsed := time.Since(i.firstTokenAt).Seconds(); elapsed > 0 {
+		i.metrics.tokensPerSecond.Record(i.ctx, float64(usage.CompletionTokens)/elapsed, metric.WithAttributes(i.attributes...))
+	}
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"context"
+
+	oaiClient "github.com/openai/openai-go"
+	openaiOption "github.com/openai/openai-go/option"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	ollamaClient "github.com/0xdeafcafe/bloefish/libraries/ollama"
+	"github.com/0xdeafcafe/bloefish/libraries/otelollama"
+	"github.com/0xdeafcafe/bloefish/libraries/otelopenai"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay/providers/ollama"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay/providers/openai"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay/providers/whispercpp"
+)
+
+const providersSeedName = "providers"
+
+func (a *App) CreateProvider(ctx context.Context, req *airelay.CreateProviderRequest) (*airelay.CreateProviderResponse, error) {
+	providerModels := make([]*models.CreateProviderCommandModel, len(req.Models))
+	for i, model := range req.Models {
+		providerModels[i] = &models.CreateProviderCommandModel{
+			ID:   model.ID,
+			Name: model.Name,
+		}
+	}
+
+	provider, err := a.ProviderRepository.Create(ctx, &models.CreateProviderCommand{
+		ID:             req.ProviderID,
+		Type:           models.ProviderType(req.Type),
+		Name:           req.Name,
+		APIKey:         req.APIKey,
+		OrganizationID: req.OrganizationID,
+		EndpointURL:    req.EndpointURL,
+		Models:         providerModels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Relay.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &airelay.CreateProviderResponse{
+		Provider: *mapProvider(provider),
+	}, nil
+}
+
+func (a *App) ListProviders(ctx context.Context) (*airelay.ListProvidersResponse, error) {
+	providers, err := a.ProviderRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &airelay.ListProvidersResponse{
+		Providers: make([]*airelay.Provider, len(providers)),
+	}
+	for i, provider := range providers {
+		resp.Providers[i] = mapProvider(provider)
+	}
+
+	return resp, nil
+}
+
+func (a *App) UpdateProvider(ctx context.Context, req *airelay.UpdateProviderRequest) (*airelay.UpdateProviderResponse, error) {
+	existing, err := a.ProviderRepository.Get(ctx, req.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The type can't be changed, so the requirements create_provider's schema
+	// has for each type are checked against the existing provider
+	invalid := false
+	switch existing.Type {
+	case models.ProviderTypeOpenAI:
+		invalid = (req.APIKey != nil && *req.APIKey == "") || len(req.Models) == 0
+	case models.ProviderTypeOllama, models.ProviderTypeWhisperCPP:
+		invalid = req.EndpointURL == ""
+	}
+	if invalid {
+		return nil, cher.New("invalid_provider_configuration", cher.M{
+			"provider_id": existing.ID,
+			"type":        existing.Type,
+		})
+	}
+
+	providerModels := make([]*models.CreateProviderCommandModel, len(req.Models))
+	for i, model := range req.Models {
+		providerModels[i] = &models.CreateProviderCommandModel{
+			ID:   model.ID,
+			Name: model.Name,
+		}
+	}
+
+	provider, err := a.ProviderRepository.Update(ctx, &models.UpdateProviderCommand{
+		ID:             req.ProviderID,
+		Name:           req.Name,
+		APIKey:         req.APIKey,
+		OrganizationID: req.OrganizationID,
+		EndpointURL:    req.EndpointURL,
+		Models:         providerModels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Relay.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &airelay.UpdateProviderResponse{
+		Provider: *mapProvider(provider),
+	}, nil
+}
+
+func (a *App) DeleteProvider(ctx context.Context, req *airelay.DeleteProviderRequest) error {
+	if err := a.ProviderRepository.Delete(ctx, req.ProviderID); err != nil {
+		return err
+	}
+
+	return a.Relay.Reload(ctx)
+}
+
+func (a *App) TestProvider(ctx context.Context, req *airelay.TestProviderRequest) error {
+	provider, err := a.ProviderRepository.Get(ctx, req.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	relayProvider, err := newRelayProvider(provider)
+	if err != nil {
+		return err
+	}
+
+	if err := relayProvider.Ping(ctx); err != nil {
+		return cher.New("provider_test_failed", cher.M{"provider_id": provider.ID}, cher.Coerce(err))
+	}
+
+	return nil
+}
+
+// SeedProviders creates the given providers the first time the service boots.
+// It is used to carry over providers configured through the environment, and
+// is only done once so deleted providers don't come back on the next boot.
+func (a *App) SeedProviders(ctx context.Context, cmds []*models.CreateProviderCommand) error {
+	seeded, err := a.SeedRepository.HasSeeded(ctx, providersSeedName)
+	if err != nil {
+		return err
+	}
+	if seeded {
+		return nil
+	}
+
+	// Registries from before seeding was recorded were seeded when they were
+	// empty
+	existing, err := a.ProviderRepository.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		for _, cmd := range cmds {
+			if _, err := a.ProviderRepository.Create(ctx, cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := a.SeedRepository.MarkSeeded(ctx, providersSeedName); err != nil {
+		return err
+	}
+
+	return a.Relay.Reload(ctx)
+}
+
+// NewRelayProviderLoader returns a relay.ProviderLoader which builds relay
+// providers from the persisted provider registry.
+func NewRelayProviderLoader(providerRepository ports.ProviderRepository) relay.ProviderLoader {
+	return func(ctx context.Context) ([]relay.Provider, error) {
+		providers, err := providerRepository.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		relayProviders := make([]relay.Provider, 0, len(providers))
+		for _, provider := range providers {
+			relayProvider, err := newRelayProvider(provider)
+			if err != nil {
+				clog.Get(ctx).WithError(err).WithField("provider_id", provider.ID).Warn("skipping provider which could not be built")
+				continue
+			}
+
+			relayProviders = append(relayProviders, relayProvider)
+		}
+
+		return relayProviders, nil
+	}
+}
+
+func newRelayProvider(provider *models.Provider) (relay.Provider, error) {
+	if provider.APIKeyError != nil {
+		return nil, provider.APIKeyError
+	}
+
+	metadata := relay.ProviderMetadata{
+		ProviderID: relay.ProviderID(provider.ID),
+		Name:       provider.Name,
+	}
+
+	switch provider.Type {
+	case models.ProviderTypeOpenAI:
+		opts := []openaiOption.RequestOption{
+			openaiOption.WithAPIKey(provider.APIKey),
+			openaiOption.WithMiddleware(otelopenai.Middleware(
+				"openai",
+				otelopenai.WithCaptureInput(),
+				otelopenai.WithCaptureOutput(),
+			)),
+		}
+		if provider.OrganizationID != "" {
+			opts = append(opts, openaiOption.WithOrganization(provider.OrganizationID))
+		}
+		if provider.EndpointURL != "" {
+			opts = append(opts, openaiOption.WithBaseURL(provider.EndpointURL))
+		}
+
+		providerModels := make([]openai.Model, len(provider.Models))
+		for i, model := range provider.Models {
+			providerModels[i] = openai.Model{
+				ID:   model.ID,
+				Name: model.Name,
+			}
+		}
+
+		return openai.NewProvider(
+			oaiClient.NewClient(opts...),
+			openai.WithModels(providerModels),
+			openai.WithMetadata(metadata),
+		), nil
+
+	case models.ProviderTypeOllama:
+		return ollama.NewProvider(
+			ollamaClient.NewClient(
+				ollamaClient.WithEndpointURL(provider.EndpointURL),
+				ollamaClient.WithTransport(otelollama.NewTransport(
+					nil,
+					otelollama.WithCaptureInput(),
+					otelollama.WithCaptureOutput(),
+				)),
+			),
+			ollama.WithMetadata(metadata),
+		), nil
+
+	case models.ProviderTypeWhisperCPP:
+		return whispercpp.NewProvider(
+			provider.EndpointURL,
+			whispercpp.WithMetadata(metadata),
+		), nil
+	}
+
+	return nil, cher.New("unsupported_provider_type", cher.M{
+		"provider_id": provider.ID,
+		"type":        provider.Type,
+	})
+}
+
+func mapProvider(provider *models.Provider) *airelay.Provider {
+	providerModels := make([]*airelay.ProviderModel, len(provider.Models))
+	for i, model := range provider.Models {
+		providerModels[i] = &airelay.ProviderModel{
+			ID:   model.ID,
+			Name: model.Name,
+		}
+	}
+
+	return &airelay.Provider{
+		ID:   provider.ID,
+		Type: airelay.ProviderType(provider.Type),
+		Name: provider.Name,
+
+		HasAPIKey:      provider.APIKey != "" || provider.APIKeyError != nil,
+		OrganizationID: provider.OrganizationID,
+		EndpointURL:    provider.EndpointURL,
+		Models:         providerModels,
+
+		CreatedAt: provider.CreatedAt,
+		UpdatedAt: provider.UpdatedAt,
+	}
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+)
+
+const (
+	auditFilePrefix = "audit-"
+	auditFileSuffix = ".jsonl"
+)
+
+type fileAudit struct {
+	directory string
+	retention time.Duration
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+// NewFileAudit records audit entries as JSON lines, in a file per day (UTC)
+// in the directory. Files are removed once every entry in them is older than
+// the retention, or kept forever if the retention is zero.
+func NewFileAudit(directory string, retention time.Duration) (ports.AuditRepository, error) {
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	return &fileAudit{
+		directory: directory,
+		retention: retention,
+	}, nil
+}
+
+func (f *fileAudit) Record(ctx context.Context, entry *models.AuditEntry) error {
+	// Content is kept as it was sent, rather than escaped for HTML
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.rotate(time.Now().UTC()); err != nil {
+		return err
+	}
+
+	_, err := f.file.Write(line.Bytes())
+	return err
+}
+
+// rotate opens the file for the current day, and removes any files which have
+// passed the retention when the day changes.
+func (f *fileAudit) rotate(now time.Time) error {
+	day := now.Format(time.DateOnly)
+	if f.file != nil && f.day == day {
+		return nil
+	}
+
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf("failed to close audit file: %w", err)
+		}
+		f.file = nil
+	}
+
+	file, err := os.OpenFile(
+		filepath.Join(f.directory, auditFilePrefix+day+auditFileSuffix),
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY,
+		0o600,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	f.day = day
+	f.file = file
+
+	return f.prune(now)
+}
+
+func (f *fileAudit) prune(now time.Time) error {
+	if f.retention <= 0 {
+		return nil
+	}
+
+	dirEntries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return fmt.Errorf("failed to list audit files: %w", err)
+	}
+
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasPrefix(name, auditFilePrefix) || !strings.HasSuffix(name, auditFileSuffix) {
+			continue
+		}
+
+		day, err := time.Parse(time.DateOnly, strings.TrimSuffix(strings.TrimPrefix(name, auditFilePrefix), auditFileSuffix))
+		if err != nil {
+			continue
+		}
+
+		// The newest entry in a file is from the end of its day
+		if day.AddDate(0, 0, 1).Add(f.retention).After(now) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(f.directory, name)); err != nil {
+			return fmt.Errorf("failed to remove audit file: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+)
+
+func newTestFileAudit(t *testing.T, retention time.Duration) (*fileAudit, string) {
+	t.Helper()
+
+	directory := t.TempDir()
+	repository, err := NewFileAudit(directory, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit := repository.(*fileAudit)
+	t.Cleanup(func() {
+		if audit.file != nil {
+			audit.file.Close()
+		}
+	})
+
+	return audit, directory
+}
+
+func listAuditDirectory(is *is.I, directory string) []string {
+	dirEntries, err := os.ReadDir(directory)
+	is.NoErr(err)
+
+	names := make([]string, len(dirEntries))
+	for i, dirEntry := range dirEntries {
+		names[i] = dirEntry.Name()
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func createAuditDirectoryFiles(is *is.I, directory string, names ...string) {
+	for _, name := range names {
+		is.NoErr(os.WriteFile(filepath.Join(directory, name), []byte("{}\n"), 0o600))
+	}
+}
+
+func TestFileAuditRecord(t *testing.T) {
+	is := is.New(t)
+	audit, directory := newTestFileAudit(t, 0)
+
+	entries := []*models.AuditEntry{
+		{ID: "auditentry_1", Output: "<b>bold</b> & more"},
+		{ID: "auditentry_2", Messages: []*models.AuditMessage{{Role: "user", Content: "hi"}}},
+	}
+	for _, entry := range entries {
+		is.NoErr(audit.Record(context.Background(), entry))
+	}
+
+	name := "audit-" + time.Now().UTC().Format(time.DateOnly) + ".jsonl"
+	is.Equal(listAuditDirectory(is, directory), []string{name})
+
+	file, err := os.Open(filepath.Join(directory, name))
+	is.NoErr(err)
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	is.NoErr(scanner.Err())
+	is.Equal(len(lines), 2) // an entry per line
+
+	is.True(strings.Contains(lines[0], "<b>bold</b> & more")) // content isn't escaped for html
+
+	var second models.AuditEntry
+	is.NoErr(json.Unmarshal([]byte(lines[1]), &second))
+	is.Equal(second.ID, "auditentry_2")
+	is.Equal(second.Messages[0].Content, "hi")
+}
+
+func TestFileAuditPrune(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Retention", func(t *testing.T) {
+		is := is.New(t)
+		audit, directory := newTestFileAudit(t, 2*24*time.Hour)
+
+		createAuditDirectoryFiles(is, directory,
+			"audit-2025-12-01.jsonl",
+			"audit-2026-01-07.jsonl", // its last entry expired at the start of today
+			"audit-2026-01-08.jsonl", // its last entry expires at the end of today
+			"audit-2026-01-09.jsonl",
+			"audit-invalid.jsonl",
+			"notes.txt",
+		)
+		is.NoErr(os.Mkdir(filepath.Join(directory, "audit-2025-01-01.jsonl"), 0o700))
+
+		is.NoErr(audit.rotate(now))
+
+		is.Equal(listAuditDirectory(is, directory), []string{
+			"audit-2025-01-01.jsonl", // directories are left alone
+			"audit-2026-01-08.jsonl",
+			"audit-2026-01-09.jsonl",
+			"audit-2026-01-10.jsonl",
+			"audit-invalid.jsonl", // files which aren't audit files are left alone
+			"notes.txt",
+		})
+	})
+
+	t.Run("NoRetention", func(t *testing.T) {
+		is := is.New(t)
+		audit, directory := newTestFileAudit(t, 0)
+
+		createAuditDirectoryFiles(is, directory, "audit-2000-01-01.jsonl")
+
+		is.NoErr(audit.rotate(now))
+
+		is.Equal(listAuditDirectory(is, directory), []string{
+			"audit-2000-01-01.jsonl", // entries are kept forever
+			"audit-2026-01-10.jsonl",
+		})
+	})
+
+	t.Run("OnlyWhenTheDayChanges", func(t *testing.T) {
+		is := is.New(t)
+		audit, directory := newTestFileAudit(t, 24*time.Hour)
+
+		is.NoErr(audit.rotate(now))
+		createAuditDirectoryFiles(is, directory, "audit-2026-01-01.jsonl")
+
+		is.NoErr(audit.rotate(now.Add(time.Hour)))
+		is.Equal(listAuditDirectory(is, directory), []string{
+			"audit-2026-01-01.jsonl", // not pruned until the next day
+			"audit-2026-01-10.jsonl",
+		})
+
+		is.NoErr(audit.rotate(now.Add(24 * time.Hour)))
+		is.Equal(listAuditDirectory(is, directory), []string{
+			"audit-2026-01-10.jsonl",
+			"audit-2026-01-11.jsonl",
+		})
+		is.Equal(audit.day, "2026-01-11")
+	})
+}
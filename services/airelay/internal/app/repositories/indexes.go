@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the repositories rely on. Creating an index
+// which already exists is a no-op, so this is run on every startup.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"audit_entries": {
+			{
+				Keys: bson.D{{Key: "expire_at", Value: 1}},
+				// Each entry holds the date it expires at, so changing the
+				// retention doesn't need the index to be rebuilt
+				Options: options.Index().
+					SetName("expire_at").
+					SetExpireAfterSeconds(0),
+			},
+			{
+				Keys: bson.D{
+					{Key: "conversation_id", Value: 1},
+					{Key: "started_at", Value: 1},
+				},
+				Options: options.Index().SetName("conversation_id_started_at"),
+			},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create %s indexes: %w", collection, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+)
+
+type mgoAudit struct {
+	c         *mongo.Collection
+	retention time.Duration
+}
+
+// NewMgoAudit records audit entries in the audit_entries collection. Entries
+// are removed by a TTL index once they're older than the retention, or kept
+// forever if the retention is zero.
+func NewMgoAudit(db *mongo.Database, retention time.Duration) ports.AuditRepository {
+	return &mgoAudit{
+		c:         db.Collection("audit_entries"),
+		retention: retention,
+	}
+}
+
+func (m *mgoAudit) Record(ctx context.Context, entry *models.AuditEntry) error {
+	var actor bson.M
+	if entry.Actor != nil {
+		actor = bson.M{
+			"type":       entry.Actor.Type,
+			"identifier": entry.Actor.Identifier,
+		}
+	}
+
+	messages := make([]bson.M, len(entry.Messages))
+	for i, message := range entry.Messages {
+		files := make([]bson.M, len(message.Files))
+		for j, file := range message.Files {
+			files[j] = bson.M{
+				"id":        file.ID,
+				"name":      file.Name,
+				"mime_type": file.MIMEType,
+				"size":      file.Size,
+				"sha256":    file.SHA256,
+			}
+		}
+
+		messages[i] = bson.M{
+			"role":    message.Role,
+			"content": message.Content,
+			"files":   files,
+		}
+	}
+
+	var usage bson.M
+	if entry.Usage != nil {
+		usage = bson.M{
+			"prompt_tokens":     entry.Usage.PromptTokens,
+			"completion_tokens": entry.Usage.CompletionTokens,
+		}
+	}
+
+	// The TTL index ignores documents without a date to expire at
+	var expireAt *time.Time
+	if m.retention > 0 {
+		at := entry.FinishedAt.Add(m.retention)
+		expireAt = &at
+	}
+
+	_, err := m.c.InsertOne(ctx, bson.M{
+		"_id":             entry.ID,
+		"actor":           actor,
+		"conversation_id": entry.ConversationID,
+		"message_id":      entry.MessageID,
+		"provider_id":     entry.ProviderID,
+		"model_id":        entry.ModelID,
+
+		"messages":  messages,
+		"output":    entry.Output,
+		"reasoning": entry.Reasoning,
+		"usage":     usage,
+		"error":     entry.Error,
+
+		"started_at":  entry.StartedAt,
+		"finished_at": entry.FinishedAt,
+		"expire_at":   expireAt,
+	})
+
+	return err
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/encryption"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+)
+
+type persistedProvider struct {
+	ID   string `bson:"_id"`
+	Type string `bson:"type"`
+	Name string `bson:"name"`
+
+	// APIKey is only set on providers created before API keys were
+	// encrypted, until they're migrated
+	APIKey          string `bson:"api_key"`
+	EncryptedAPIKey []byte `bson:"encrypted_api_key"`
+	OrganizationID  string `bson:"organization_id"`
+	EndpointURL     string `bson:"endpoint_url"`
+	Models          []struct {
+		ID   string `bson:"id"`
+		Name string `bson:"name"`
+	} `bson:"models"`
+
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt *time.Time `bson:"updated_at"`
+}
+
+type mgoProvider struct {
+	c      *mongo.Collection
+	cipher *encryption.AESGCM
+}
+
+// NewMgoProvider stores providers with their API keys encrypted by the cipher.
+// Without a cipher, providers with API keys can't be created or read.
+func NewMgoProvider(db *mongo.Database, cipher *encryption.AESGCM) ports.ProviderRepository {
+	return &mgoProvider{
+		c:      db.Collection("providers"),
+		cipher: cipher,
+	}
+}
+
+func (m *mgoProvider) Create(ctx context.Context, cmd *models.CreateProviderCommand) (*models.Provider, error) {
+	providerModels := make([]bson.M, len(cmd.Models))
+	for i, model := range cmd.Models {
+		providerModels[i] = bson.M{
+			"id":   model.ID,
+			"name": model.Name,
+		}
+	}
+
+	encryptedAPIKey, err := m.encryptAPIKey(cmd.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.c.InsertOne(ctx, bson.M{
+		"_id":  cmd.ID,
+		"type": cmd.Type,
+		"name": cmd.Name,
+
+		"encrypted_api_key": encryptedAPIKey,
+		"organization_id":   cmd.OrganizationID,
+		"endpoint_url":      cmd.EndpointURL,
+		"models":            providerModels,
+
+		"created_at": time.Now(),
+		"updated_at": nil,
+	}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, cher.New("provider_already_exists", cher.M{"provider_id": cmd.ID})
+		}
+
+		return nil, err
+	}
+
+	return m.Get(ctx, cmd.ID)
+}
+
+func (m *mgoProvider) Get(ctx context.Context, providerID string) (*models.Provider, error) {
+	var provider *persistedProvider
+	if err := m.c.FindOne(ctx, bson.M{"_id": providerID}).Decode(&provider); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, cher.New("provider_not_found", cher.M{"provider_id": providerID})
+		}
+
+		return nil, err
+	}
+
+	return m.toDomainModel(provider), nil
+}
+
+func (m *mgoProvider) Update(ctx context.Context, cmd *models.UpdateProviderCommand) (*models.Provider, error) {
+	providerModels := make([]bson.M, len(cmd.Models))
+	for i, model := range cmd.Models {
+		providerModels[i] = bson.M{
+			"id":   model.ID,
+			"name": model.Name,
+		}
+	}
+
+	set := bson.M{
+		"name":            cmd.Name,
+		"organization_id": cmd.OrganizationID,
+		"endpoint_url":    cmd.EndpointURL,
+		"models":          providerModels,
+		"updated_at":      time.Now(),
+	}
+	update := bson.M{"$set": set}
+
+	if cmd.APIKey != nil {
+		encryptedAPIKey, err := m.encryptAPIKey(*cmd.APIKey)
+		if err != nil {
+			return nil, err
+		}
+
+		set["encrypted_api_key"] = encryptedAPIKey
+		update["$unset"] = bson.M{"api_key": ""}
+	}
+
+	result, err := m.c.UpdateOne(ctx, bson.M{"_id": cmd.ID}, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, cher.New("provider_not_found", cher.M{"provider_id": cmd.ID})
+	}
+
+	return m.Get(ctx, cmd.ID)
+}
+
+func (m *mgoProvider) List(ctx context.Context) ([]*models.Provider, error) {
+	cursor, err := m.c.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var persistedProviders []*persistedProvider
+	if err := cursor.All(ctx, &persistedProviders); err != nil {
+		return nil, err
+	}
+
+	providers := make([]*models.Provider, len(persistedProviders))
+	for i, persistedProvider := range persistedProviders {
+		providers[i] = m.toDomainModel(persistedProvider)
+	}
+
+	return providers, nil
+}
+
+// Delete removes the provider outright rather than soft deleting it, as the
+// document holds provider credentials which shouldn't outlive the provider.
+func (m *mgoProvider) Delete(ctx context.Context, providerID string) error {
+	result, err := m.c.DeleteOne(ctx, bson.M{"_id": providerID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return cher.New("provider_not_found", cher.M{"provider_id": providerID})
+	}
+
+	return nil
+}
+
+// encryptAPIKey returns nil for providers without an API key.
+func (m *mgoProvider) encryptAPIKey(apiKey string) ([]byte, error) {
+	if apiKey == "" {
+		return nil, nil
+	}
+	if m.cipher == nil {
+		return nil, cher.New("provider_encryption_key_missing", nil)
+	}
+
+	return m.cipher.Encrypt([]byte(apiKey))
+}
+
+func (m *mgoProvider) decryptAPIKey(p *persistedProvider) (string, error) {
+	if len(p.EncryptedAPIKey) == 0 {
+		return p.APIKey, nil
+	}
+	if m.cipher == nil {
+		return "", cher.New("provider_encryption_key_missing", cher.M{"provider_id": p.ID})
+	}
+
+	apiKey, err := m.cipher.Decrypt(p.EncryptedAPIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt api key of provider %s: %w", p.ID, err)
+	}
+
+	return string(apiKey), nil
+}
+
+// toDomainModel doesn't fail when the API key can't be decrypted, so one
+// provider with a bad key doesn't stop the others from being read. The error
+// is recorded on the provider instead.
+func (m *mgoProvider) toDomainModel(p *persistedProvider) *models.Provider {
+	apiKey, err := m.decryptAPIKey(p)
+
+	provider := p.ToDomainModel(apiKey)
+	provider.APIKeyError = err
+
+	return provider
+}
+
+func (p *persistedProvider) ToDomainModel(apiKey string) *models.Provider {
+	providerModels := make([]*models.ProviderModel, len(p.Models))
+	for i, model := range p.Models {
+		providerModels[i] = &models.ProviderModel{
+			ID:   model.ID,
+			Name: model.Name,
+		}
+	}
+
+	return &models.Provider{
+		ID:   p.ID,
+		Type: models.ProviderType(p.Type),
+		Name: p.Name,
+
+		APIKey:         apiKey,
+		OrganizationID: p.OrganizationID,
+		EndpointURL:    p.EndpointURL,
+		Models:         providerModels,
+
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
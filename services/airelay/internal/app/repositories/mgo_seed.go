@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
+)
+
+type mgoSeed struct {
+	c *mongo.Collection
+}
+
+func NewMgoSeed(db *mongo.Database) ports.SeedRepository {
+	return &mgoSeed{c: db.Collection("seeds")}
+}
+
+func (m *mgoSeed) HasSeeded(ctx context.Context, name string) (bool, error) {
+	if err := m.c.FindOne(ctx, bson.M{"_id": name}).Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (m *mgoSeed) MarkSeeded(ctx context.Context, name string) error {
+	_, err := m.c.UpdateOne(ctx, bson.M{"_id": name}, bson.M{
+		"$setOnInsert": bson.M{"seeded_at": time.Now()},
+	}, options.Update().SetUpsert(true))
+
+	return err
+}
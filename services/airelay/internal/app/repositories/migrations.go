@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/0xdeafcafe/bloefish/libraries/encryption"
+)
+
+// Migrate updates documents written by older versions of the service. Each
+// migration only touches documents which still need it, so this is run on
+// every startup.
+func Migrate(ctx context.Context, db *mongo.Database, cipher *encryption.AESGCM) error {
+	// Provider API keys used to be stored in plaintext. They can only be
+	// encrypted once an encryption key is configured.
+	if cipher == nil {
+		return nil
+	}
+
+	providers := db.Collection("providers")
+
+	cursor, err := providers.Find(ctx, bson.M{
+		"api_key": bson.M{"$type": "string", "$ne": ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find plaintext provider api keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var provider persistedProvider
+		if err := cursor.Decode(&provider); err != nil {
+			return err
+		}
+
+		encryptedAPIKey, err := cipher.Encrypt([]byte(provider.APIKey))
+		if err != nil {
+			return err
+		}
+
+		if _, err := providers.UpdateOne(ctx, bson.M{"_id": provider.ID}, bson.M{
+			"$set":   bson.M{"encrypted_api_key": encryptedAPIKey},
+			"$unset": bson.M{"api_key": ""},
+		}); err != nil {
+			return fmt.Errorf("failed to encrypt api key of provider %s: %w", provider.ID, err)
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/slicefuncs"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+// compileResponseFormat compiles the requested response format schema, so an
+// invalid schema is rejected before any tokens are spent on it.
+func compileResponseFormat(responseFormat *airelay.ResponseFormat) (*gojsonschema.Schema, error) {
+	if responseFormat == nil {
+		return nil, nil
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(responseFormat.Schema))
+	if err != nil {
+		return nil, cher.New("invalid_response_format_schema", cher.M{
+			"name":  responseFormat.Name,
+			"error": err.Error(),
+		})
+	}
+
+	return schema, nil
+}
+
+// validateResponseFormat checks the model's response against the requested
+// response format. Providers only constrain output on a best-effort basis, so
+// the response is never trusted.
+func validateResponseFormat(schema *gojsonschema.Schema, responseFormat *airelay.ResponseFormat, messageContent string) error {
+	if schema == nil {
+		return nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewStringLoader(messageContent))
+	if err != nil {
+		return cher.New(airelay.ErrCodeResponseFormatViolation, cher.M{
+			"name": responseFormat.Name,
+		}, cher.E{
+			Code: "invalid_json",
+			Meta: cher.M{"message": err.Error()},
+		})
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	return cher.New(airelay.ErrCodeResponseFormatViolation, cher.M{
+		"name": responseFormat.Name,
+	}, slicefuncs.Map(result.Errors(), func(err gojsonschema.ResultError) cher.E {
+		return cher.E{
+			Code: "schema_failure",
+			Meta: cher.M{
+				"field":   err.Field(),
+				"type":    err.Type(),
+				"message": err.Description(),
+			},
+		}
+	})...)
+}
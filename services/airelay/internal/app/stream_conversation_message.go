@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (a *App) StreamConversationMessage(ctx context.Context, req *airelay.InvokeConversationMessageRequest, send func(*airelay.StreamConversationMessageEvent) error) (err error) {
+	responseFormatSchema, err := compileResponseFormat(req.ResponseFormat)
+	if err != nil {
+		return err
+	}
+
+	chatStream, err := a.newChatStream(ctx, &newChatStreamCommand{
+		ConversationID: req.ConversationID,
+		MessageID:      req.MessageID,
+		Owner:          req.Owner,
+		Messages:       req.Messages,
+		AIRelayOptions: req.AIRelayOptions,
+		ResponseFormat: req.ResponseFormat,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { a.Auditor.finish(ctx, chatStream, err) }()
+
+	for chatStream.Next() {
+		event := chatStream.Current()
+
+		if event.Reasoning != "" {
+			if err := send(&airelay.StreamConversationMessageEvent{ReasoningContent: event.Reasoning}); err != nil {
+				return err
+			}
+		}
+
+		if event.Content != "" {
+			if err := send(&airelay.StreamConversationMessageEvent{MessageContent: event.Content}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := chatStream.Err(); err != nil {
+		return coerceChatStreamError(err, req.AIRelayOptions)
+	}
+
+	return validateResponseFormat(responseFormatSchema, req.ResponseFormat, chatStream.Content())
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func isAudioFile(file *fileupload.File) bool {
+	return strings.HasPrefix(file.MIMEType, "audio/")
+}
+
+// transcribeFile transcribes an audio file the first time it's used, and
+// caches the transcript on the file so it's only ever transcribed once. The
+// transcript is left empty if transcription isn't set up.
+func (a *App) transcribeFile(ctx context.Context, file *downloadedFile) error {
+	if a.TranscriptionProviderID == "" {
+		return nil
+	}
+
+	ctx = clog.Set(ctx, clog.Get(ctx).
+		WithField("file_id", file.ID).
+		WithField("transcription_provider_id", a.TranscriptionProviderID).
+		WithField("transcription_model_id", a.TranscriptionModelID),
+	)
+
+	transcription, err := a.Relay.With(a.TranscriptionProviderID).Transcribe(ctx, relay.TranscriptionParams{
+		ModelID:  a.TranscriptionModelID,
+		FileName: file.Name,
+		MIMEType: file.MIMEType,
+		Audio:    bytes.NewReader(file.Content),
+	})
+	if err != nil {
+		if errors.Is(err, relay.ErrRequiredProviderMissing) || errors.Is(err, relay.ErrTranscriptionUnsupported) {
+			clog.Get(ctx).WithError(err).Warn("transcription provider can't transcribe audio")
+			return nil
+		}
+
+		return cher.New("transcription_failed", cher.M{"file_id": file.ID}, cher.Coerce(err))
+	}
+
+	file.Transcript = &fileupload.FileTranscript{
+		Text:       transcription.Text,
+		ProviderID: a.TranscriptionProviderID,
+		ModelID:    a.TranscriptionModelID,
+	}
+
+	// The transcript can still be used if it couldn't be cached, it'll just be
+	// transcribed again next time
+	if err := a.FileUploadService.UpdateFileTranscript(ctx, &fileupload.UpdateFileTranscriptRequest{
+		FileID:     file.ID,
+		Text:       transcription.Text,
+		ProviderID: a.TranscriptionProviderID,
+		ModelID:    a.TranscriptionModelID,
+	}); err != nil {
+		clog.Get(ctx).WithError(err).Warn("failed to cache file transcript")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+)
+
+type AuditSink string
+
+const (
+	AuditSinkMongo AuditSink = "mongo"
+	AuditSinkFile  AuditSink = "file"
+)
+
+// AuditField is a part of an audit entry which redaction rules can be applied
+// to.
+type AuditField string
+
+const (
+	AuditFieldActor     AuditField = "actor"
+	AuditFieldMessages  AuditField = "messages"
+	AuditFieldOutput    AuditField = "output"
+	AuditFieldReasoning AuditField = "reasoning"
+	AuditFieldError     AuditField = "error"
+)
+
+// AuditEntry records a single invocation of a model, whether or not it
+// succeeded.
+type AuditEntry struct {
+	ID             string `json:"id"`
+	Actor          *Actor `json:"actor"`
+	ConversationID string `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	ProviderID     string `json:"provider_id"`
+	ModelID        string `json:"model_id"`
+
+	Messages  []*AuditMessage `json:"messages"`
+	Output    string          `json:"output"`
+	Reasoning string          `json:"reasoning"`
+	Usage     *AuditUsage     `json:"usage"`
+	Error     *cher.E         `json:"error"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// AuditMessage is a message sent to the model. Files sent with it are recorded
+// by reference rather than inlining their content, which would make entries
+// too large to store.
+type AuditMessage struct {
+	Role    string       `json:"role"`
+	Content string       `json:"content"`
+	Files   []*AuditFile `json:"files"`
+}
+
+type AuditFile struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	MIMEType string  `json:"mime_type"`
+	Size     int64   `json:"size"`
+	SHA256   *string `json:"sha256"`
+}
+
+type AuditUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// AuditRedactionRule redacts fields of audit entries before they're recorded.
+// Rules with a pattern replace each match in the fields, and rules without one
+// replace the fields entirely. Rules without fields apply to every field.
+type AuditRedactionRule struct {
+	Fields      []AuditField `json:"fields"`
+	Pattern     string       `json:"pattern"`
+	Replacement string       `json:"replacement"`
+}
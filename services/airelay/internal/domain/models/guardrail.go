@@ -0,0 +1,24 @@
+package models
+
+type GuardrailStage string
+
+const (
+	// GuardrailStageInput guardrails check the user's message before it's sent
+	// to the model.
+	GuardrailStageInput GuardrailStage = "input"
+	// GuardrailStageOutput guardrails check the model's response once it has
+	// finished.
+	GuardrailStageOutput GuardrailStage = "output"
+)
+
+// Guardrail is a LangWatch evaluator which is run on chats.
+type Guardrail struct {
+	Name      string           `json:"name"`
+	Evaluator string           `json:"evaluator"`
+	Stages    []GuardrailStage `json:"stages"`
+	Settings  map[string]any   `json:"settings"`
+
+	// Blocking guardrails fail chats which don't pass them. Other guardrails
+	// only record their result in LangWatch.
+	Blocking bool `json:"blocking"`
+}
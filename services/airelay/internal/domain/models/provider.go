@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+type ProviderType string
+
+const (
+	ProviderTypeOpenAI ProviderType = "open_ai"
+	ProviderTypeOllama ProviderType = "ollama"
+	// ProviderTypeWhisperCPP providers can only be used for transcription.
+	ProviderTypeWhisperCPP ProviderType = "whisper_cpp"
+)
+
+type Provider struct {
+	ID   string       `json:"id"`
+	Type ProviderType `json:"type"`
+	Name string       `json:"name"`
+
+	APIKey         string           `json:"api_key"`
+	OrganizationID string           `json:"organization_id"`
+	EndpointURL    string           `json:"endpoint_url"`
+	Models         []*ProviderModel `json:"models"`
+
+	// APIKeyError is set when the stored API key couldn't be decrypted, in
+	// which case APIKey is empty and the provider can't be used until its API
+	// key is replaced.
+	APIKeyError error `json:"-"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ProviderModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CreateProviderCommand struct {
+	ID   string
+	Type ProviderType
+	Name string
+
+	APIKey         string
+	OrganizationID string
+	EndpointURL    string
+	Models         []*CreateProviderCommandModel
+}
+
+type CreateProviderCommandModel struct {
+	ID   string
+	Name string
+}
+
+type UpdateProviderCommand struct {
+	ID   string
+	Name string
+
+	// APIKey is left as it is when nil, and removed when empty.
+	APIKey         *string
+	OrganizationID string
+	EndpointURL    string
+	Models         []*CreateProviderCommandModel
+}
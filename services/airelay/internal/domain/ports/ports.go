@@ -1,4 +1,26 @@
 package ports
 
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+)
+
 type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+}
+
+type ProviderRepository interface {
+	Create(ctx context.Context, cmd *models.CreateProviderCommand) (*models.Provider, error)
+	Get(ctx context.Context, providerID string) (*models.Provider, error)
+	Update(ctx context.Context, cmd *models.UpdateProviderCommand) (*models.Provider, error)
+	List(ctx context.Context) ([]*models.Provider, error)
+	Delete(ctx context.Context, providerID string) error
+}
+
+// SeedRepository records which seeds have been applied, so they're only
+// applied once.
+type SeedRepository interface {
+	HasSeeded(ctx context.Context, name string) (bool, error)
+	MarkSeeded(ctx context.Context, name string) error
 }
@@ -1,5 +1,7 @@
 package relay
 
+import "encoding/json"
+
 type Role string
 
 const (
@@ -35,20 +37,43 @@ func NewChatUserMessage(content string) Message {
 }
 
 type ChatStreamParams struct {
-	ThreadID     string
-	ModelID      string
-	Messages     []Message
-	IncludeUsage bool
+	ThreadID       string
+	ModelID        string
+	Messages       []Message
+	IncludeUsage   bool
+	ResponseFormat *ResponseFormat
+}
+
+// ResponseFormat asks the provider to constrain its response to a JSON schema.
+// Providers do this on a best-effort basis, so the response should still be
+// validated.
+type ResponseFormat struct {
+	Name   string
+	Schema json.RawMessage
 }
 
+// ChatStreamEvent is a single event in a chat stream. Reasoning is kept apart
+// from Content, so it can be shown to the user without becoming part of the
+// answer.
 type ChatStreamEvent struct {
-	Content string
-	Done    bool
+	Content   string
+	Reasoning string
+	Done      bool
+}
+
+// Usage is how many tokens a chat used.
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
 }
 
 type ChatStreamIterator interface {
 	Next() bool
 	Current() *ChatStreamEvent
 	Content() string
+	Reasoning() string
+	// Usage returns the tokens the chat used once the stream has finished, or
+	// nil if the provider didn't report them.
+	Usage() *Usage
 	Err() error
 }
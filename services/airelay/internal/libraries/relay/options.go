@@ -7,3 +7,11 @@ func WithProvider(provider Provider) ClientOption {
 		c.providers[provider.GetMetadata().ProviderID] = provider
 	}
 }
+
+// WithProviderLoader configures where the client loads its providers from
+// when Reload is called.
+func WithProviderLoader(loader ProviderLoader) ClientOption {
+	return func(c *Client) {
+		c.loader = loader
+	}
+}
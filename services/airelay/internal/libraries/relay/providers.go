@@ -8,18 +8,26 @@ import (
 type ProviderID string
 
 const (
-	ProviderIdOpenAI  ProviderID = "open_ai"
-	ProviderIdOllama  ProviderID = "ollama"
-	providerIdUnknown ProviderID = "unknown"
+	ProviderIdOpenAI ProviderID = "open_ai"
+	ProviderIdOllama ProviderID = "ollama"
+	// ProviderIdWhisperCPP is only used for transcription.
+	ProviderIdWhisperCPP ProviderID = "whisper_cpp"
+	providerIdUnknown    ProviderID = "unknown"
 )
 
 var (
-	ErrRequiredProviderMissing = errors.New("required provider is missing")
+	ErrRequiredProviderMissing  = errors.New("required provider is missing")
+	ErrChatUnsupported          = errors.New("provider does not support chat")
+	ErrTranscriptionUnsupported = errors.New("provider does not support transcription")
 )
 
 type Provider interface {
 	NewChatStream(ctx context.Context, params ChatStreamParams) (iter ChatStreamIterator, err error)
+	// Transcribe turns speech into text. Providers which can't return
+	// ErrTranscriptionUnsupported.
+	Transcribe(ctx context.Context, params TranscriptionParams) (*Transcription, error)
 	ListModels(ctx context.Context) ([]Model, error)
+	Ping(ctx context.Context) error
 	GetMetadata() ProviderMetadata
 }
 
@@ -38,10 +46,18 @@ func (p *unknownProvider) NewChatStream(context.Context, ChatStreamParams) (Chat
 	return nil, ErrRequiredProviderMissing
 }
 
+func (p *unknownProvider) Transcribe(context.Context, TranscriptionParams) (*Transcription, error) {
+	return nil, ErrRequiredProviderMissing
+}
+
 func (p *unknownProvider) ListModels(context.Context) ([]Model, error) {
 	return nil, ErrRequiredProviderMissing
 }
 
+func (p *unknownProvider) Ping(context.Context) error {
+	return ErrRequiredProviderMissing
+}
+
 func (p *unknownProvider) GetMetadata() ProviderMetadata {
 	return ProviderMetadata{
 		ProviderID: providerIdUnknown,
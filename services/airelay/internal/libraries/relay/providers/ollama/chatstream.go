@@ -2,6 +2,7 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net"
 
@@ -11,24 +12,76 @@ import (
 )
 
 type ollamaChatStreamIterator struct {
-	inner *ollama.StreamingChatIterator
+	inner    *ollama.StreamingChatIterator
+	splitter relay.ThinkTagSplitter
+	current  *relay.ChatStreamEvent
+	usage    *relay.Usage
+	complete bool
 }
 
 func (i *ollamaChatStreamIterator) Next() bool {
-	return i.inner.Next()
-}
+	if i.complete {
+		return false
+	}
+
+	if !i.inner.Next() {
+		i.complete = true
+
+		// Token counts are only sent with the final event, which ends the stream
+		if i.inner.IsComplete() {
+			if current := i.inner.Current(); current != nil {
+				i.usage = &relay.Usage{
+					PromptTokens:     int64(current.PromptEvalCount),
+					CompletionTokens: int64(current.EvalCount),
+				}
+			}
+		}
+
+		// Emit anything the splitter was holding back as a final event
+		content, reasoning := i.splitter.Flush()
+		if content == "" && reasoning == "" {
+			return false
+		}
+
+		i.current = &relay.ChatStreamEvent{
+			Content:   content,
+			Reasoning: reasoning,
+			Done:      true,
+		}
+
+		return true
+	}
 
-func (i *ollamaChatStreamIterator) Current() *relay.ChatStreamEvent {
 	current := i.inner.Current()
 
-	return &relay.ChatStreamEvent{
-		Content: current.Message.Content,
-		Done:    current.Done,
+	var content, reasoning string
+	if current.Message != nil {
+		content, reasoning = i.splitter.Write(current.Message.Content)
 	}
+
+	i.current = &relay.ChatStreamEvent{
+		Content:   content,
+		Reasoning: reasoning,
+		Done:      false,
+	}
+
+	return true
+}
+
+func (i *ollamaChatStreamIterator) Current() *relay.ChatStreamEvent {
+	return i.current
 }
 
 func (i *ollamaChatStreamIterator) Content() string {
-	return i.inner.Content()
+	return i.splitter.Content()
+}
+
+func (i *ollamaChatStreamIterator) Reasoning() string {
+	return i.splitter.Reasoning()
+}
+
+func (i *ollamaChatStreamIterator) Usage() *relay.Usage {
+	return i.usage
 }
 
 func (i *ollamaChatStreamIterator) Err() error {
@@ -44,9 +97,15 @@ func (p *Provider) NewChatStream(ctx context.Context, params relay.ChatStreamPar
 		}
 	}
 
+	var format json.RawMessage
+	if params.ResponseFormat != nil {
+		format = params.ResponseFormat.Schema
+	}
+
 	stream, err := p.client.NewStreamingChat(ctx, ollama.NewStreamingChatParams{
 		Model:    params.ModelID,
 		Messages: messages,
+		Format:   format,
 	})
 	if err != nil {
 		var opErr *net.OpError
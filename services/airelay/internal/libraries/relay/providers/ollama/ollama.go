@@ -1,25 +1,42 @@
 package ollama
 
 import (
+	"context"
+
 	ollamaClient "github.com/0xdeafcafe/bloefish/libraries/ollama"
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
 )
 
 func NewProvider(
 	ollamaClient ollamaClient.Client,
+	opts ...ProviderOption,
 ) relay.Provider {
-	return &Provider{
+	p := &Provider{
 		client: ollamaClient,
+		metadata: relay.ProviderMetadata{
+			ProviderID: relay.ProviderIdOllama,
+			Name:       "Ollama",
+		},
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
 }
 
 type Provider struct {
-	client ollamaClient.Client
+	client   ollamaClient.Client
+	metadata relay.ProviderMetadata
 }
 
 func (p *Provider) GetMetadata() relay.ProviderMetadata {
-	return relay.ProviderMetadata{
-		ProviderID: relay.ProviderIdOllama,
-		Name:       "Ollama",
-	}
+	return p.metadata
+}
+
+func (p *Provider) Ping(ctx context.Context) error {
+	_, err := p.client.ListRunningModels(ctx)
+
+	return err
 }
@@ -0,0 +1,11 @@
+package ollama
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+func (p *Provider) Transcribe(context.Context, relay.TranscriptionParams) (*relay.Transcription, error) {
+	return nil, relay.ErrTranscriptionUnsupported
+}
@@ -8,12 +8,15 @@ import (
 	"github.com/openai/openai-go"
 	oaiClient "github.com/openai/openai-go"
 	"github.com/openai/openai-go/packages/ssestream"
+	"github.com/openai/openai-go/shared"
 )
 
 type openAIChatStreamIterator struct {
 	inner    *ssestream.Stream[oaiClient.ChatCompletionChunk]
 	acc      oaiClient.ChatCompletionAccumulator
+	splitter relay.ThinkTagSplitter
 	current  *relay.ChatStreamEvent
+	usage    *relay.Usage
 	complete bool
 }
 
@@ -24,19 +27,45 @@ func (i *openAIChatStreamIterator) Next() bool {
 
 	if !i.inner.Next() {
 		i.complete = true
-		return false
+
+		// Emit anything the splitter was holding back as a final event
+		content, reasoning := i.splitter.Flush()
+		if content == "" && reasoning == "" {
+			return false
+		}
+
+		i.current = &relay.ChatStreamEvent{
+			Content:   content,
+			Reasoning: reasoning,
+			Done:      true,
+		}
+
+		return true
 	}
 
 	chunk := i.inner.Current()
 	i.acc.AddChunk(chunk)
 
-	if len(chunk.Choices) > 0 {
-		i.current = &relay.ChatStreamEvent{
-			Content: chunk.Choices[0].Delta.Content,
-			Done:    false,
+	if chunk.Usage.TotalTokens > 0 {
+		i.usage = &relay.Usage{
+			PromptTokens:     chunk.Usage.PromptTokens,
+			CompletionTokens: chunk.Usage.CompletionTokens,
 		}
 	}
 
+	// OpenAI models don't emit think tags, but OpenAI compatible endpoints
+	// serving reasoning models do. Usage chunks have no choices.
+	var content, reasoning string
+	if len(chunk.Choices) > 0 {
+		content, reasoning = i.splitter.Write(chunk.Choices[0].Delta.Content)
+	}
+
+	i.current = &relay.ChatStreamEvent{
+		Content:   content,
+		Reasoning: reasoning,
+		Done:      false,
+	}
+
 	return true
 }
 
@@ -45,7 +74,15 @@ func (i *openAIChatStreamIterator) Current() *relay.ChatStreamEvent {
 }
 
 func (i *openAIChatStreamIterator) Content() string {
-	return i.acc.Choices[0].Message.Content
+	return i.splitter.Content()
+}
+
+func (i *openAIChatStreamIterator) Reasoning() string {
+	return i.splitter.Reasoning()
+}
+
+func (i *openAIChatStreamIterator) Usage() *relay.Usage {
+	return i.usage
 }
 
 func (i *openAIChatStreamIterator) Err() error {
@@ -66,13 +103,26 @@ func (p *Provider) NewChatStream(ctx context.Context, params relay.ChatStreamPar
 		}
 	}
 
-	stream := p.client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+	completionParams := openai.ChatCompletionNewParams{
 		Messages: messages,
 		Model:    params.ModelID,
 		StreamOptions: oaiClient.ChatCompletionStreamOptionsParam{
 			IncludeUsage: openai.Bool(params.IncludeUsage),
 		},
-	})
+	}
+
+	if params.ResponseFormat != nil {
+		completionParams.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   params.ResponseFormat.Name,
+					Schema: params.ResponseFormat.Schema,
+				},
+			},
+		}
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, completionParams)
 
 	return &openAIChatStreamIterator{
 		inner: stream,
@@ -1,13 +1,16 @@
 package openai
 
 import (
+	"context"
+
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
 	oaiClient "github.com/openai/openai-go"
 )
 
 type Provider struct {
-	client oaiClient.Client
-	models []Model
+	client   oaiClient.Client
+	models   []Model
+	metadata relay.ProviderMetadata
 }
 
 func NewProvider(
@@ -17,6 +20,10 @@ func NewProvider(
 	p := &Provider{
 		client: oaiClient,
 		models: []Model{},
+		metadata: relay.ProviderMetadata{
+			ProviderID: relay.ProviderIdOpenAI,
+			Name:       "Open AI",
+		},
 	}
 
 	for _, opt := range opts {
@@ -27,8 +34,11 @@ func NewProvider(
 }
 
 func (p *Provider) GetMetadata() relay.ProviderMetadata {
-	return relay.ProviderMetadata{
-		ProviderID: relay.ProviderIdOpenAI,
-		Name:       "Open AI",
-	}
+	return p.metadata
+}
+
+func (p *Provider) Ping(ctx context.Context) error {
+	_, err := p.client.Models.List(ctx)
+
+	return err
 }
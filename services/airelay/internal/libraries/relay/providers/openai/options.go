@@ -1,5 +1,7 @@
 package openai
 
+import "github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+
 type ProviderOption func(*Provider)
 
 func WithModels(models []Model) ProviderOption {
@@ -7,3 +9,11 @@ func WithModels(models []Model) ProviderOption {
 		c.models = models
 	}
 }
+
+// WithMetadata overrides the default provider ID and name, allowing multiple
+// OpenAI compatible providers to be registered side by side.
+func WithMetadata(metadata relay.ProviderMetadata) ProviderOption {
+	return func(c *Provider) {
+		c.metadata = metadata
+	}
+}
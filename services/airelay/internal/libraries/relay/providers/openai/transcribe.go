@@ -0,0 +1,25 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+	oaiClient "github.com/openai/openai-go"
+)
+
+func (p *Provider) Transcribe(ctx context.Context, params relay.TranscriptionParams) (*relay.Transcription, error) {
+	transcription, err := p.client.Audio.Transcriptions.New(ctx, oaiClient.AudioTranscriptionNewParams{
+		// The file name is sent along with the audio, as the API uses its
+		// extension to work out the format
+		File:           oaiClient.File(params.Audio, params.FileName, params.MIMEType),
+		Model:          oaiClient.AudioModel(params.ModelID),
+		ResponseFormat: oaiClient.AudioResponseFormatJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &relay.Transcription{
+		Text: transcription.Text,
+	}, nil
+}
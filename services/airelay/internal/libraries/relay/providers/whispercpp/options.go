@@ -0,0 +1,13 @@
+package whispercpp
+
+import "github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+
+type ProviderOption func(*Provider)
+
+// WithMetadata overrides the default provider ID and name, allowing multiple
+// whisper.cpp servers to be registered side by side.
+func WithMetadata(metadata relay.ProviderMetadata) ProviderOption {
+	return func(c *Provider) {
+		c.metadata = metadata
+	}
+}
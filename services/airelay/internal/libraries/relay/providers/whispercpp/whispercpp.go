@@ -0,0 +1,131 @@
+package whispercpp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
+)
+
+// Provider transcribes audio with a whisper.cpp server, or anything else which
+// serves the same /inference endpoint. The server transcribes with whichever
+// model it was started with, so the model ID of a transcription is ignored.
+type Provider struct {
+	endpointURL string
+	client      *http.Client
+	metadata    relay.ProviderMetadata
+}
+
+func NewProvider(endpointURL string, opts ...ProviderOption) relay.Provider {
+	p := &Provider{
+		endpointURL: strings.TrimSuffix(endpointURL, "/"),
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		metadata: relay.ProviderMetadata{
+			ProviderID: relay.ProviderIdWhisperCPP,
+			Name:       "whisper.cpp",
+		},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) GetMetadata() relay.ProviderMetadata {
+	return p.metadata
+}
+
+func (p *Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpointURL+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("whisper.cpp server returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// ListModels returns no models, as whisper.cpp can't be chatted with.
+func (p *Provider) ListModels(ctx context.Context) ([]relay.Model, error) {
+	return []relay.Model{}, nil
+}
+
+func (p *Provider) NewChatStream(context.Context, relay.ChatStreamParams) (relay.ChatStreamIterator, error) {
+	return nil, relay.ErrChatUnsupported
+}
+
+type inferenceResponse struct {
+	Text  string `json:"text"`
+	Error string `json:"error"`
+}
+
+func (p *Provider) Transcribe(ctx context.Context, params relay.TranscriptionParams) (*relay.Transcription, error) {
+	// The form is streamed to the server, rather than buffering the audio
+	body, w := io.Pipe()
+	form := multipart.NewWriter(w)
+
+	go func() {
+		w.CloseWithError(writeInferenceForm(form, params))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpointURL+"/inference", body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var inference inferenceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&inference); err != nil {
+		return nil, fmt.Errorf("failed to decode whisper.cpp response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || inference.Error != "" {
+		return nil, fmt.Errorf("whisper.cpp server returned %s: %s", resp.Status, inference.Error)
+	}
+
+	return &relay.Transcription{
+		Text: strings.TrimSpace(inference.Text),
+	}, nil
+}
+
+func writeInferenceForm(form *multipart.Writer, params relay.TranscriptionParams) error {
+	if err := form.WriteField("response_format", "json"); err != nil {
+		return err
+	}
+
+	file, err := form.CreateFormFile("file", params.FileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, params.Audio); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
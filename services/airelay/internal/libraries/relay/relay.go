@@ -2,10 +2,22 @@ package relay
 
 import (
 	"context"
+	"sync"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
 )
 
+// ProviderLoader returns the full set of providers the client should route
+// requests to. It is called on every reload, and the result replaces the
+// previously loaded providers.
+type ProviderLoader func(ctx context.Context) ([]Provider, error)
+
 type Client struct {
-	providers map[ProviderID]Provider
+	loader ProviderLoader
+
+	providers   map[ProviderID]Provider
+	providersMu sync.RWMutex
 }
 
 func NewClient(opts ...ClientOption) *Client {
@@ -21,6 +33,9 @@ func NewClient(opts ...ClientOption) *Client {
 }
 
 func (c *Client) With(providerID string) Provider {
+	c.providersMu.RLock()
+	defer c.providersMu.RUnlock()
+
 	provider := c.providers[ProviderID(providerID)]
 	if provider == nil {
 		return newUnknownProvider()
@@ -31,7 +46,7 @@ func (c *Client) With(providerID string) Provider {
 
 func (c *Client) ListAllModels(ctx context.Context) ([]Model, error) {
 	var models []Model
-	for _, provider := range c.providers {
+	for _, provider := range c.snapshot() {
 		providerModels, err := provider.ListModels(ctx)
 		if err != nil {
 			return nil, err
@@ -42,3 +57,57 @@ func (c *Client) ListAllModels(ctx context.Context) ([]Model, error) {
 
 	return models, nil
 }
+
+// Reload swaps the registered providers for the ones returned by the
+// configured ProviderLoader. Clients without a loader are left untouched.
+func (c *Client) Reload(ctx context.Context) error {
+	if c.loader == nil {
+		return nil
+	}
+
+	providers, err := c.loader(ctx)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[ProviderID]Provider, len(providers))
+	for _, provider := range providers {
+		loaded[provider.GetMetadata().ProviderID] = provider
+	}
+
+	c.providersMu.Lock()
+	defer c.providersMu.Unlock()
+	c.providers = loaded
+
+	return nil
+}
+
+// Watch reloads the providers on the given interval until the context is
+// cancelled, so changes made by other instances are eventually picked up.
+func (c *Client) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reload(ctx); err != nil {
+				clog.Get(ctx).WithError(err).Warn("failed to reload relay providers")
+			}
+		}
+	}
+}
+
+func (c *Client) snapshot() []Provider {
+	c.providersMu.RLock()
+	defer c.providersMu.RUnlock()
+
+	providers := make([]Provider, 0, len(c.providers))
+	for _, provider := range c.providers {
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
@@ -0,0 +1,111 @@
+package relay
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// ThinkTagSplitter separates reasoning wrapped in <think> tags from the answer
+// content of a streamed response. Reasoning models emit these tags inline, and
+// a tag can be split across any number of fragments, so anything that could be
+// the start of a tag is held back until the next fragment arrives.
+type ThinkTagSplitter struct {
+	inThink        bool
+	pending        string
+	contentStarted bool
+
+	content   strings.Builder
+	reasoning strings.Builder
+}
+
+// Write consumes the next fragment of the response, and returns the answer
+// content and reasoning that can be emitted from it.
+func (s *ThinkTagSplitter) Write(fragment string) (content, reasoning string) {
+	var contentOut, reasoningOut strings.Builder
+
+	buf := s.pending + fragment
+	s.pending = ""
+
+	for buf != "" {
+		tag := thinkOpenTag
+		if s.inThink {
+			tag = thinkCloseTag
+		}
+
+		if idx := strings.Index(buf, tag); idx >= 0 {
+			s.emit(buf[:idx], &contentOut, &reasoningOut)
+			buf = buf[idx+len(tag):]
+			s.inThink = !s.inThink
+
+			continue
+		}
+
+		held := partialTagSuffixLength(buf, tag)
+		s.emit(buf[:len(buf)-held], &contentOut, &reasoningOut)
+		s.pending = buf[len(buf)-held:]
+
+		break
+	}
+
+	return contentOut.String(), reasoningOut.String()
+}
+
+// Flush emits anything held back waiting for a tag that never arrived.
+func (s *ThinkTagSplitter) Flush() (content, reasoning string) {
+	var contentOut, reasoningOut strings.Builder
+
+	s.emit(s.pending, &contentOut, &reasoningOut)
+	s.pending = ""
+
+	return contentOut.String(), reasoningOut.String()
+}
+
+// Content returns all answer content emitted so far.
+func (s *ThinkTagSplitter) Content() string {
+	return s.content.String()
+}
+
+// Reasoning returns all reasoning emitted so far.
+func (s *ThinkTagSplitter) Reasoning() string {
+	return s.reasoning.String()
+}
+
+func (s *ThinkTagSplitter) emit(text string, contentOut, reasoningOut *strings.Builder) {
+	if s.inThink {
+		s.reasoning.WriteString(text)
+		reasoningOut.WriteString(text)
+
+		return
+	}
+
+	// Models put whitespace between the closing think tag and the answer, which
+	// shouldn't end up at the start of the message.
+	if !s.contentStarted {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			return
+		}
+
+		s.contentStarted = true
+	}
+
+	s.content.WriteString(text)
+	contentOut.WriteString(text)
+}
+
+// partialTagSuffixLength returns the length of the longest suffix of s that is
+// a prefix of tag.
+func partialTagSuffixLength(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+
+	return 0
+}
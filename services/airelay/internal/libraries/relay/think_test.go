@@ -0,0 +1,103 @@
+package relay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// splitFragments writes each fragment to a new splitter and flushes it,
+// returning everything it emitted.
+func splitFragments(is *is.I, fragments []string) (content, reasoning string) {
+	var splitter ThinkTagSplitter
+	var contentOut, reasoningOut strings.Builder
+
+	for _, fragment := range fragments {
+		c, r := splitter.Write(fragment)
+		contentOut.WriteString(c)
+		reasoningOut.WriteString(r)
+	}
+
+	c, r := splitter.Flush()
+	contentOut.WriteString(c)
+	reasoningOut.WriteString(r)
+
+	is.Equal(splitter.Content(), contentOut.String())     // accumulated content matches what was emitted
+	is.Equal(splitter.Reasoning(), reasoningOut.String()) // accumulated reasoning matches what was emitted
+
+	return contentOut.String(), reasoningOut.String()
+}
+
+func TestThinkTagSplitter(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Fragments []string
+
+		Content   string
+		Reasoning string
+	}{
+		{"Empty", []string{""}, "", ""},
+		{"ContentOnly", []string{"Hello", " world"}, "Hello world", ""},
+		{"ThinkThenContentInOneFragment", []string{"<think>Pondering</think>Hello world"}, "Hello world", "Pondering"},
+		{"ContentAfterCloseTagInSameFragment", []string{"<think>Ponder", "ing</think>Hello", " world"}, "Hello world", "Pondering"},
+		{"WhitespaceAfterCloseTagTrimmed", []string{"<think>Pondering</think>\n\n  Hello"}, "Hello", "Pondering"},
+		{"InnerWhitespaceKept", []string{"Hello", " ", "world"}, "Hello world", ""},
+		{"MultipleBlocks", []string{"<think>a</think>b<think>c</think>d"}, "bd", "ac"},
+		{"MultipleBlocksAcrossFragments", []string{"<think>a</th", "ink>b<th", "ink>c</think", ">d"}, "bd", "ac"},
+		{"UnclosedThink", []string{"<think>Pondering", " forever"}, "", "Pondering forever"},
+		{"UnclosedThinkPartialCloseTag", []string{"<think>Pondering</thi"}, "", "Pondering</thi"},
+		{"PartialOpenTagNeverCompleted", []string{"a <thi"}, "a <thi", ""},
+		{"PartialOpenTagNotATag", []string{"a <thi", "s is fine"}, "a <this is fine", ""},
+		{"CloseTagWithoutOpen", []string{"Hello</think>"}, "Hello</think>", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			content, reasoning := splitFragments(is, test.Fragments)
+
+			is.Equal(content, test.Content)
+			is.Equal(reasoning, test.Reasoning)
+		})
+	}
+}
+
+func TestThinkTagSplitterSplitAtEveryByte(t *testing.T) {
+	inputs := []struct {
+		Name  string
+		Input string
+
+		Content   string
+		Reasoning string
+	}{
+		{"ThinkThenContent", "<think>Pondering</think>\nHello world", "Hello world", "Pondering"},
+		{"MultipleBlocks", "<think>a</think>b<think>c</think>d", "bd", "ac"},
+		{"Unclosed", "<think>Pondering</thin", "", "Pondering</thin"},
+		{"Multibyte", "<think>héllo</think>wörld", "wörld", "héllo"},
+	}
+
+	for _, input := range inputs {
+		t.Run(input.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			for split := range len(input.Input) + 1 {
+				content, reasoning := splitFragments(is, []string{input.Input[:split], input.Input[split:]})
+
+				is.Equal(content, input.Content)     // split into two fragments
+				is.Equal(reasoning, input.Reasoning) // split into two fragments
+			}
+
+			fragments := make([]string, len(input.Input))
+			for i := range len(input.Input) {
+				fragments[i] = input.Input[i : i+1]
+			}
+
+			content, reasoning := splitFragments(is, fragments)
+
+			is.Equal(content, input.Content)     // a fragment per byte
+			is.Equal(reasoning, input.Reasoning) // a fragment per byte
+		})
+	}
+}
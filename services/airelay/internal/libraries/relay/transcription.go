@@ -0,0 +1,14 @@
+package relay
+
+import "io"
+
+type TranscriptionParams struct {
+	ModelID  string
+	FileName string
+	MIMEType string
+	Audio    io.Reader
+}
+
+type Transcription struct {
+	Text string
+}
@@ -2,19 +2,24 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
-	ollamaClient "github.com/0xdeafcafe/bloefish/libraries/ollama"
-	"github.com/0xdeafcafe/bloefish/libraries/otelopenai"
 	oaiClient "github.com/openai/openai-go"
-	openaiOption "github.com/openai/openai-go/option"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/libraries/config"
+	"github.com/0xdeafcafe/bloefish/libraries/encryption"
+	"github.com/0xdeafcafe/bloefish/libraries/langwatch"
 	"github.com/0xdeafcafe/bloefish/libraries/telemetry"
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/app"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/app/repositories"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/airelay/internal/domain/ports"
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay"
-	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay/providers/ollama"
-	"github.com/0xdeafcafe/bloefish/services/airelay/internal/libraries/relay/providers/openai"
 	"github.com/0xdeafcafe/bloefish/services/airelay/internal/transport/rpc"
 	"github.com/0xdeafcafe/bloefish/services/conversation"
 	"github.com/0xdeafcafe/bloefish/services/fileupload"
@@ -25,14 +30,48 @@ type Config struct {
 	Server    config.Server    `env:"SERVER"`
 	Telemetry telemetry.Config `env:"TELEMETRY"`
 	Logging   clog.Config      `env:"LOGGING"`
+	Mongo     config.MongoDB   `env:"MONGO"`
 
 	ConversationService config.UnauthenticatedService `env:"CONVERSATION_SERVICE"`
 	FileUploadService   config.UnauthenticatedService `env:"FILE_UPLOAD_SERVICE"`
 	StreamService       config.UnauthenticatedService `env:"STREAM_SERVICE"`
 
+	// AIProviders are only used to seed the provider registry on first boot,
+	// after which providers are managed through the provider RPCs.
 	AIProviders AIProviders `env:"AI_PROVIDERS"`
 
+	ProviderReloadIntervalSeconds int `env:"PROVIDER_RELOAD_INTERVAL_SECONDS"`
+
+	// ProviderEncryptionKey is the base64 encoded AES-256 key provider API keys
+	// are encrypted with at rest. Providers with API keys can't be used
+	// without it.
+	ProviderEncryptionKey string `env:"PROVIDER_ENCRYPTION_KEY"`
+
 	Langwatch LangwatchConfig `env:"LANGWATCH"`
+
+	Transcription TranscriptionConfig `env:"TRANSCRIPTION"`
+
+	Audit AuditConfig `env:"AUDIT"`
+}
+
+// TranscriptionConfig picks the provider and model audio files are
+// transcribed with. Audio files aren't transcribed when ProviderID is empty.
+type TranscriptionConfig struct {
+	ProviderID string `env:"PROVIDER_ID"`
+	ModelID    string `env:"MODEL_ID"`
+}
+
+// AuditConfig picks where invocations of models are audited, and for how
+// long. Invocations aren't audited when Sink is empty, and audit entries are
+// kept forever when RetentionDays is zero.
+type AuditConfig struct {
+	Sink          string `env:"SINK"`
+	Directory     string `env:"DIRECTORY"`
+	RetentionDays int    `env:"RETENTION_DAYS"`
+
+	// RedactionRules is a JSON array of the rules applied to audit entries
+	// before they're recorded.
+	RedactionRules string `env:"REDACTION_RULES"`
 }
 
 type AIProviders struct {
@@ -46,7 +85,11 @@ type OpenAIConfig struct {
 }
 
 type LangwatchConfig struct {
-	APIKey string `env:"API_KEY"`
+	APIKey      string `env:"API_KEY"`
+	EndpointURL string `env:"ENDPOINT_URL"`
+
+	// Guardrails is a JSON array of the LangWatch evaluators run on chats.
+	Guardrails string `env:"GUARDRAILS"`
 }
 
 type OllamaConfig struct {
@@ -64,6 +107,11 @@ func defaultConfig() Config {
 			Debug:  true,
 		},
 
+		Mongo: config.MongoDB{
+			URI:          "mongodb://localhost:27017",
+			DatabaseName: "bloefish_svc_ai_relay",
+		},
+
 		ConversationService: config.UnauthenticatedService{
 			BaseURL: "http://localhost:4002/rpc",
 		},
@@ -84,6 +132,18 @@ func defaultConfig() Config {
 				Endpoint: "http://localhost:11434",
 			},
 		},
+
+		ProviderReloadIntervalSeconds: 30,
+
+		Transcription: TranscriptionConfig{
+			ProviderID: string(relay.ProviderIdOpenAI),
+			ModelID:    string(oaiClient.AudioModelWhisper1),
+		},
+
+		Audit: AuditConfig{
+			Directory:     "audit",
+			RetentionDays: 30,
+		},
 	}
 }
 
@@ -99,57 +159,205 @@ func Run(ctx context.Context) error {
 	}()
 
 	ctx = clog.Set(ctx, cfg.Logging.Configure(ctx))
+	_, mongoDatabase := cfg.Mongo.MustConnect(ctx)
+
+	if err := repositories.EnsureIndexes(ctx, mongoDatabase); err != nil {
+		return err
+	}
+
+	providerCipher, err := newProviderCipher(cfg.ProviderEncryptionKey)
+	if err != nil {
+		return err
+	}
+	if err := repositories.Migrate(ctx, mongoDatabase, providerCipher); err != nil {
+		return err
+	}
+
+	providerRepository := repositories.NewMgoProvider(mongoDatabase, providerCipher)
+	relayClient := relay.NewClient(
+		relay.WithProviderLoader(app.NewRelayProviderLoader(providerRepository)),
+	)
+
+	chatMetrics, err := app.NewChatMetrics()
+	if err != nil {
+		return err
+	}
+
+	guardrails, err := newGuardrails(cfg.Langwatch)
+	if err != nil {
+		return err
+	}
+
+	auditor, err := newAuditor(cfg.Audit, mongoDatabase)
+	if err != nil {
+		return err
+	}
+
+	langwatchOpts := []langwatch.ClientOption{langwatch.WithAPIKey(cfg.Langwatch.APIKey)}
+	if cfg.Langwatch.EndpointURL != "" {
+		langwatchOpts = append(langwatchOpts, langwatch.WithEndpointURL(cfg.Langwatch.EndpointURL))
+	}
 
 	app := &app.App{
-		Relay: relay.NewClient(
-			relay.WithProvider(openai.NewProvider(
-				oaiClient.NewClient(
-					openaiOption.WithAPIKey(cfg.AIProviders.OpenAI.APIKey),
-					openaiOption.WithMiddleware(otelopenai.Middleware(
-						"openai",
-						otelopenai.WithCaptureInput(),
-						otelopenai.WithCaptureOutput(),
-					)),
-				),
-				openai.WithModels([]openai.Model{{
-					ID:   string(oaiClient.ChatModelGPT4),
-					Name: "GPT 4",
-				}, {
-					ID:   string(oaiClient.ChatModelGPT4Turbo),
-					Name: "GPT 4 turbo",
-				}, {
-					ID:   string(oaiClient.ChatModelGPT4o),
-					Name: "GPT 4o",
-				}, {
-					ID:   string(oaiClient.ChatModelGPT4oMini),
-					Name: "GPT 4o mini",
-				}, {
-					ID:   string(oaiClient.ChatModelGPT3_5Turbo),
-					Name: "GPT 3.5 turbo",
-				}, {
-					ID:   string(oaiClient.ChatModelO1Mini),
-					Name: "o1",
-				}, {
-					ID:   string(oaiClient.ChatModelO1Mini),
-					Name: "o1 mini",
-				}, {
-					ID:   string(oaiClient.ChatModelO3Mini),
-					Name: "o3 mini",
-				}}),
-			)),
-			relay.WithProvider(ollama.NewProvider(
-				ollamaClient.NewClient(
-					ollamaClient.WithEndpointURL(cfg.AIProviders.Ollama.Endpoint),
-				),
-			)),
-		),
+		Relay: relayClient,
+
+		ProviderRepository: providerRepository,
+		SeedRepository:     repositories.NewMgoSeed(mongoDatabase),
+
+		ChatMetrics: chatMetrics,
+		Auditor:     auditor,
+
+		Langwatch:  langwatch.NewClient(langwatchOpts...),
+		Guardrails: guardrails,
+
+		TranscriptionProviderID: cfg.Transcription.ProviderID,
+		TranscriptionModelID:    cfg.Transcription.ModelID,
 
 		ConversationService: conversation.NewRPCClient(ctx, cfg.ConversationService),
 		FileUploadService:   fileupload.NewRPCClient(ctx, cfg.FileUploadService),
 		StreamService:       stream.NewRPCClient(ctx, cfg.StreamService),
 	}
 
+	if err := app.SeedProviders(ctx, seedProviders(ctx, cfg.AIProviders, providerCipher != nil)); err != nil {
+		return err
+	}
+	if err := relayClient.Reload(ctx); err != nil {
+		return err
+	}
+	if cfg.ProviderReloadIntervalSeconds > 0 {
+		go relayClient.Watch(ctx, time.Duration(cfg.ProviderReloadIntervalSeconds)*time.Second)
+	}
+
 	rpc := rpc.New(ctx, app)
 
 	return rpc.Run(ctx, cfg.Server)
 }
+
+// seedProviders skips providers with API keys when they can't be encrypted,
+// rather than stopping the service from booting.
+func seedProviders(ctx context.Context, cfg AIProviders, canEncrypt bool) []*models.CreateProviderCommand {
+	var cmds []*models.CreateProviderCommand
+
+	if cfg.OpenAI.APIKey != "" && !canEncrypt {
+		clog.Get(ctx).WithField("provider_id", relay.ProviderIdOpenAI).Warn("not seeding provider as PROVIDER_ENCRYPTION_KEY isn't set, create it with create_provider once it is")
+	} else if cfg.OpenAI.APIKey != "" {
+		cmds = append(cmds, &models.CreateProviderCommand{
+			ID:             string(relay.ProviderIdOpenAI),
+			Type:           models.ProviderTypeOpenAI,
+			Name:           "Open AI",
+			APIKey:         cfg.OpenAI.APIKey,
+			OrganizationID: cfg.OpenAI.OrganizationID,
+			Models: []*models.CreateProviderCommandModel{{
+				ID:   string(oaiClient.ChatModelGPT4),
+				Name: "GPT 4",
+			}, {
+				ID:   string(oaiClient.ChatModelGPT4Turbo),
+				Name: "GPT 4 turbo",
+			}, {
+				ID:   string(oaiClient.ChatModelGPT4o),
+				Name: "GPT 4o",
+			}, {
+				ID:   string(oaiClient.ChatModelGPT4oMini),
+				Name: "GPT 4o mini",
+			}, {
+				ID:   string(oaiClient.ChatModelGPT3_5Turbo),
+				Name: "GPT 3.5 turbo",
+			}, {
+				ID:   string(oaiClient.ChatModelO1),
+				Name: "o1",
+			}, {
+				ID:   string(oaiClient.ChatModelO1Mini),
+				Name: "o1 mini",
+			}, {
+				ID:   string(oaiClient.ChatModelO3Mini),
+				Name: "o3 mini",
+			}},
+		})
+	}
+
+	if cfg.Ollama.Endpoint != "" {
+		cmds = append(cmds, &models.CreateProviderCommand{
+			ID:          string(relay.ProviderIdOllama),
+			Type:        models.ProviderTypeOllama,
+			Name:        "Ollama",
+			EndpointURL: cfg.Ollama.Endpoint,
+		})
+	}
+
+	return cmds
+}
+
+func newProviderCipher(encodedKey string) (*encryption.AESGCM, error) {
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := encryption.ParseKey(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider encryption key: %w", err)
+	}
+
+	return encryption.NewAESGCM(key)
+}
+
+func newGuardrails(cfg LangwatchConfig) ([]*models.Guardrail, error) {
+	if cfg.Guardrails == "" {
+		return nil, nil
+	}
+
+	var guardrails []*models.Guardrail
+	if err := json.Unmarshal([]byte(cfg.Guardrails), &guardrails); err != nil {
+		return nil, fmt.Errorf("failed to parse guardrails: %w", err)
+	}
+
+	if len(guardrails) > 0 && cfg.APIKey == "" {
+		return nil, errors.New("guardrails need a langwatch api key")
+	}
+
+	for _, guardrail := range guardrails {
+		if guardrail.Evaluator == "" {
+			return nil, fmt.Errorf("guardrail %q has no evaluator", guardrail.Name)
+		}
+		if guardrail.Name == "" {
+			guardrail.Name = guardrail.Evaluator
+		}
+
+		for _, stage := range guardrail.Stages {
+			if stage != models.GuardrailStageInput && stage != models.GuardrailStageOutput {
+				return nil, fmt.Errorf("guardrail %q has unknown stage %q", guardrail.Name, stage)
+			}
+		}
+	}
+
+	return guardrails, nil
+}
+
+func newAuditor(cfg AuditConfig, db *mongo.Database) (*app.Auditor, error) {
+	retention := time.Duration(cfg.RetentionDays) * 24 * time.Hour
+
+	var repository ports.AuditRepository
+	switch models.AuditSink(cfg.Sink) {
+	case "":
+		return nil, nil
+	case models.AuditSinkMongo:
+		repository = repositories.NewMgoAudit(db, retention)
+	case models.AuditSinkFile:
+		fileRepository, err := repositories.NewFileAudit(cfg.Directory, retention)
+		if err != nil {
+			return nil, err
+		}
+
+		repository = fileRepository
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", cfg.Sink)
+	}
+
+	var rules []*models.AuditRedactionRule
+	if cfg.RedactionRules != "" {
+		if err := json.Unmarshal([]byte(cfg.RedactionRules), &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse audit redaction rules: %w", err)
+		}
+	}
+
+	return app.NewAuditor(repository, rules)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) CreateProvider(ctx context.Context, req *airelay.CreateProviderRequest) (*airelay.CreateProviderResponse, error) {
+	return r.app.CreateProvider(ctx, req)
+}
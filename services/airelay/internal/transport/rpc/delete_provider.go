@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) DeleteProvider(ctx context.Context, req *airelay.DeleteProviderRequest) error {
+	return r.app.DeleteProvider(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) ListProviders(ctx context.Context) (*airelay.ListProvidersResponse, error) {
+	return r.app.ListProviders(ctx)
+}
@@ -43,11 +43,18 @@ func New(ctx context.Context, app *app.App) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("list_supported", "2025-02-12", nil, rpc.ListSupported)
 	svr.Register("invoke_conversation_message", "2025-02-12", schema("invoke_conversation_message"), rpc.InvokeConversationMessage)
 	svr.Register("invoke_streaming_conversation_message", "2025-02-12", schema("invoke_streaming_conversation_message"), rpc.InvokeStreamingConversationMessage)
+	svr.Register("stream_conversation_message", "2025-02-12", schema("invoke_conversation_message"), rpc.StreamConversationMessage)
+	svr.Register("create_provider", "2025-02-12", schema("create_provider"), rpc.CreateProvider)
+	svr.Register("list_providers", "2025-02-12", nil, rpc.ListProviders)
+	svr.Register("update_provider", "2025-02-12", schema("update_provider"), rpc.UpdateProvider)
+	svr.Register("delete_provider", "2025-02-12", schema("delete_provider"), rpc.DeleteProvider)
+	svr.Register("test_provider", "2025-02-12", schema("test_provider"), rpc.TestProvider)
 
 	mux := chi.NewRouter()
 	mux.Use(version.HeaderMiddleware(svcInfo.ServiceHTTPName))
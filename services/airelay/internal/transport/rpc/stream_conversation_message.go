@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) StreamConversationMessage(ctx context.Context, req *airelay.InvokeConversationMessageRequest, send func(*airelay.StreamConversationMessageEvent) error) error {
+	return r.app.StreamConversationMessage(ctx, req, send)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) TestProvider(ctx context.Context, req *airelay.TestProviderRequest) error {
+	return r.app.TestProvider(ctx, req)
+}
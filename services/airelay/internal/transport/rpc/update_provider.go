@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+)
+
+func (r *RPC) UpdateProvider(ctx context.Context, req *airelay.UpdateProviderRequest) (*airelay.UpdateProviderResponse, error) {
+	return r.app.UpdateProvider(ctx, req)
+}
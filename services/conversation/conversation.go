@@ -1,5 +1,7 @@
 package conversation
 
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
 import (
 	"context"
 	"time"
@@ -16,6 +18,19 @@ type Service interface {
 	DeleteConversations(ctx context.Context, req *DeleteConversationsRequest) error
 	DeleteInteractions(ctx context.Context, req *DeleteInteractionsRequest) error
 	UpdateInteractionExcludedState(ctx context.Context, req *UpdateInteractionExcludedStateRequest) error
+	UpdateConversationTitle(ctx context.Context, req *UpdateConversationTitleRequest) (*UpdateConversationTitleResponse, error)
+	UpdateConversationFolder(ctx context.Context, req *UpdateConversationFolderRequest) error
+	UpdateConversationTags(ctx context.Context, req *UpdateConversationTagsRequest) (*UpdateConversationTagsResponse, error)
+	UpdateConversationPinnedState(ctx context.Context, req *UpdateConversationPinnedStateRequest) error
+	UpdateConversationArchivedState(ctx context.Context, req *UpdateConversationArchivedStateRequest) error
+	CreateFolder(ctx context.Context, req *CreateFolderRequest) (*CreateFolderResponse, error)
+	ListFolders(ctx context.Context, req *ListFoldersRequest) (*ListFoldersResponse, error)
+	UpdateFolderName(ctx context.Context, req *UpdateFolderNameRequest) error
+	DeleteFolder(ctx context.Context, req *DeleteFolderRequest) error
+	CreateConversationShare(ctx context.Context, req *CreateConversationShareRequest) (*CreateConversationShareResponse, error)
+	ListConversationShares(ctx context.Context, req *ListConversationSharesRequest) (*ListConversationSharesResponse, error)
+	RevokeConversationShare(ctx context.Context, req *RevokeConversationShareRequest) error
+	GetSharedConversation(ctx context.Context, req *GetSharedConversationRequest) (*GetSharedConversationResponse, error)
 }
 
 type ActorType string
@@ -30,6 +45,23 @@ type Actor struct {
 	Identifier string    `json:"identifier"`
 }
 
+type InteractionStatus string
+
+const (
+	InteractionStatusPending   InteractionStatus = "pending"
+	InteractionStatusStreaming InteractionStatus = "streaming"
+	InteractionStatusCompleted InteractionStatus = "completed"
+	InteractionStatusFailed    InteractionStatus = "failed"
+	InteractionStatusCancelled InteractionStatus = "cancelled"
+)
+
+type ShareMode string
+
+const (
+	ShareModeSnapshot ShareMode = "snapshot"
+	ShareModeLive     ShareMode = "live"
+)
+
 type AIRelayOptions struct {
 	ProviderID string `json:"provider_id"`
 	ModelID    string `json:"model_id"`
@@ -84,8 +116,10 @@ type CreateConversationMessageResponseInteraction struct {
 
 	MarkedAsExcludedAt *time.Time `json:"marked_as_excluded_at"`
 
-	MessageContent string   `json:"message_content"`
-	Errors         []cher.E `json:"errors"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+	Errors           []cher.E          `json:"errors"`
 
 	Owner          *Actor          `json:"owner"`
 	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
@@ -94,6 +128,9 @@ type CreateConversationMessageResponseInteraction struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
 	CompletedAt *time.Time `json:"completed_at"`
+
+	StartedAt    *time.Time `json:"started_at"`
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 
 type GetInteractionRequest struct {
@@ -108,8 +145,10 @@ type GetInteractionResponse struct {
 
 	MarkedAsExcludedAt *time.Time `json:"marked_as_excluded_at"`
 
-	MessageContent string   `json:"message_content"`
-	Errors         []cher.E `json:"errors"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+	Errors           []cher.E          `json:"errors"`
 
 	Owner          *Actor          `json:"owner"`
 	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
@@ -118,6 +157,9 @@ type GetInteractionResponse struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
 	CompletedAt *time.Time `json:"completed_at"`
+
+	StartedAt    *time.Time `json:"started_at"`
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 
 type GetConversationWithInteractionsRequest struct {
@@ -132,6 +174,11 @@ type GetConversationWithInteractionsResponse struct {
 	Title           *string `json:"title"`
 	StreamChannelID string  `json:"stream_channel_id"`
 
+	FolderID   *string    `json:"folder_id"`
+	Tags       []string   `json:"tags"`
+	PinnedAt   *time.Time `json:"pinned_at"`
+	ArchivedAt *time.Time `json:"archived_at"`
+
 	Interactions []*GetConversationWithInteractionsResponseInteraction `json:"interactions"`
 
 	CreatedAt time.Time  `json:"created_at"`
@@ -147,8 +194,10 @@ type GetConversationWithInteractionsResponseInteraction struct {
 
 	MarkedAsExcludedAt *time.Time `json:"marked_as_excluded_at"`
 
-	MessageContent string   `json:"message_content"`
-	Errors         []cher.E `json:"errors"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+	Errors           []cher.E          `json:"errors"`
 
 	Owner          *Actor          `json:"owner"`
 	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
@@ -157,10 +206,27 @@ type GetConversationWithInteractionsResponseInteraction struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
+
+	StartedAt    *time.Time `json:"started_at"`
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 
 type ListConversationsWithInteractionsRequest struct {
-	Owner *Actor `json:"owner"`
+	Owner  *Actor                                          `json:"owner"`
+	Filter *ListConversationsWithInteractionsRequestFilter `json:"filter"`
+}
+
+type ListConversationsWithInteractionsRequestFilter struct {
+	// FolderID only lists conversations in the folder. When nil, conversations
+	// in any folder or none are listed.
+	FolderID *string `json:"folder_id"`
+	// Tags only lists conversations which have every one of the tags.
+	Tags []string `json:"tags"`
+	// Pinned only lists pinned or unpinned conversations. When nil, both are
+	// listed.
+	Pinned *bool `json:"pinned"`
+	// Archived lists archived conversations instead of unarchived ones.
+	Archived bool `json:"archived"`
 }
 
 type ListConversationsWithInteractionsResponse struct {
@@ -175,6 +241,11 @@ type ListConversationsWithInteractionsResponseConversation struct {
 	Title           *string `json:"title"`
 	StreamChannelID string  `json:"stream_channel_id"`
 
+	FolderID   *string    `json:"folder_id"`
+	Tags       []string   `json:"tags"`
+	PinnedAt   *time.Time `json:"pinned_at"`
+	ArchivedAt *time.Time `json:"archived_at"`
+
 	Interactions []*ListConversationsWithInteractionsResponseConversationInteraction `json:"interactions"`
 
 	CreatedAt time.Time  `json:"created_at"`
@@ -190,8 +261,10 @@ type ListConversationsWithInteractionsResponseConversationInteraction struct {
 
 	MarkedAsExcludedAt *time.Time `json:"marked_as_excluded_at"`
 
-	MessageContent string   `json:"message_content"`
-	Errors         []cher.E `json:"errors"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+	Errors           []cher.E          `json:"errors"`
 
 	Owner          *Actor          `json:"owner"`
 	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
@@ -200,6 +273,9 @@ type ListConversationsWithInteractionsResponseConversationInteraction struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
+
+	StartedAt    *time.Time `json:"started_at"`
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 
 type DeleteConversationsRequest struct {
@@ -214,3 +290,161 @@ type UpdateInteractionExcludedStateRequest struct {
 	InteractionID string `json:"interaction_id"`
 	Excluded      bool   `json:"excluded"`
 }
+
+type UpdateConversationTitleRequest struct {
+	ConversationID string `json:"conversation_id"`
+
+	// Title is set as-is when provided, otherwise a new title is generated.
+	Title *string `json:"title"`
+}
+
+type UpdateConversationTitleResponse struct {
+	ConversationID string `json:"conversation_id"`
+	Title          string `json:"title"`
+}
+
+type UpdateConversationFolderRequest struct {
+	ConversationID string `json:"conversation_id"`
+
+	// FolderID is the folder to move the conversation into. When nil, the
+	// conversation is taken out of its folder.
+	FolderID *string `json:"folder_id"`
+}
+
+type UpdateConversationTagsRequest struct {
+	ConversationID string   `json:"conversation_id"`
+	Tags           []string `json:"tags"`
+}
+
+type UpdateConversationTagsResponse struct {
+	ConversationID string   `json:"conversation_id"`
+	Tags           []string `json:"tags"`
+}
+
+type UpdateConversationPinnedStateRequest struct {
+	ConversationID string `json:"conversation_id"`
+	Pinned         bool   `json:"pinned"`
+}
+
+type UpdateConversationArchivedStateRequest struct {
+	ConversationID string `json:"conversation_id"`
+	Archived       bool   `json:"archived"`
+}
+
+type CreateFolderRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	Owner          *Actor `json:"owner"`
+	Name           string `json:"name"`
+}
+
+type CreateFolderResponse struct {
+	ID    string `json:"id"`
+	Owner *Actor `json:"owner"`
+	Name  string `json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListFoldersRequest struct {
+	Owner *Actor `json:"owner"`
+}
+
+type ListFoldersResponse struct {
+	Folders []*ListFoldersResponseFolder `json:"folders"`
+}
+
+type ListFoldersResponseFolder struct {
+	ID    string `json:"id"`
+	Owner *Actor `json:"owner"`
+	Name  string `json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateFolderNameRequest struct {
+	FolderID string `json:"folder_id"`
+	Name     string `json:"name"`
+}
+
+type DeleteFolderRequest struct {
+	FolderID string `json:"folder_id"`
+}
+
+type CreateConversationShareRequest struct {
+	ConversationID string    `json:"conversation_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Owner          *Actor    `json:"owner"`
+	Mode           ShareMode `json:"mode"`
+
+	// ExpiresInSeconds is how long the share can be viewed for. When nil, the
+	// share never expires.
+	ExpiresInSeconds *int `json:"expires_in_seconds"`
+}
+
+type CreateConversationShareResponse struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	Token          string    `json:"token"`
+	Mode           ShareMode `json:"mode"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+type ListConversationSharesRequest struct {
+	ConversationID string `json:"conversation_id"`
+	Owner          *Actor `json:"owner"`
+}
+
+type ListConversationSharesResponse struct {
+	Shares []*ListConversationSharesResponseShare `json:"shares"`
+}
+
+type ListConversationSharesResponseShare struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	Token          string    `json:"token"`
+	Mode           ShareMode `json:"mode"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+type RevokeConversationShareRequest struct {
+	ShareID string `json:"share_id"`
+	Owner   *Actor `json:"owner"`
+}
+
+type GetSharedConversationRequest struct {
+	Token string `json:"token"`
+}
+
+// GetSharedConversationResponse is a read-only view of a shared conversation.
+// It leaves out who owns the conversation and the files attached to it, as
+// anyone with the share token can see it.
+type GetSharedConversationResponse struct {
+	Mode  ShareMode `json:"mode"`
+	Title *string   `json:"title"`
+
+	Interactions []*GetSharedConversationResponseInteraction `json:"interactions"`
+
+	SharedAt  time.Time  `json:"shared_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type GetSharedConversationResponseInteraction struct {
+	ID             string          `json:"id"`
+	OwnerType      ActorType       `json:"owner_type"`
+	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
+
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
@@ -1,7 +1,11 @@
 package app
 
 import (
+	"text/template"
+	"time"
+
 	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/ports"
 	"github.com/0xdeafcafe/bloefish/services/skillset"
 	"github.com/0xdeafcafe/bloefish/services/stream"
@@ -10,10 +14,25 @@ import (
 
 type App struct {
 	ConversationRepository ports.ConversationRepository
+	FolderRepository       ports.FolderRepository
 	InteractionRepository  ports.InteractionRepository
+	JobRepository          ports.JobRepository
+	ShareRepository        ports.ShareRepository
 
 	AIRelayService  airelay.Service
 	SkillSetService skillset.Service
 	StreamService   stream.Service
 	UserService     user.Service
+
+	// TitleAIRelayOptions overrides the model used to generate conversation
+	// titles, unless the owner has set their own preference. When nil, the
+	// conversation's model is used.
+	TitleAIRelayOptions *models.AIRelayOptions
+	TitlePromptTemplate *template.Template
+
+	// JobMaxAttempts is how many times a reply or title job is run before it is
+	// failed. JobLeaseDuration is how long a worker can go without extending
+	// its lease on a job before another worker takes it over.
+	JobMaxAttempts   int
+	JobLeaseDuration time.Duration
 }
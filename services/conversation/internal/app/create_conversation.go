@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
 	"github.com/0xdeafcafe/bloefish/services/conversation"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
 )
@@ -17,15 +18,32 @@ func (a *App) CreateConversation(ctx context.Context, req *conversation.CreateCo
 		return nil, cher.New("invalid_owner", cher.M{"identifier": req.Owner.Identifier})
 	}
 
-	convo, err := a.ConversationRepository.Create(ctx, &models.CreateConversationCommand{
+	var aiRelayOptions *models.AIRelayOptions
+	if req.AIRelayOptions != nil {
+		aiRelayOptions = &models.AIRelayOptions{
+			ProviderID: req.AIRelayOptions.ProviderID,
+			ModelID:    req.AIRelayOptions.ModelID,
+		}
+	} else {
+		aiRelayOptions = a.getDefaultAIRelayOptions(ctx, &airelay.Actor{
+			Type:       airelay.ActorType(req.Owner.Type),
+			Identifier: req.Owner.Identifier,
+		})
+	}
+	if aiRelayOptions == nil {
+		return nil, cher.New("ai_relay_options_required", nil)
+	}
+
+	// Retried requests get the original conversation back
+	convo, _, err := a.ConversationRepository.Create(ctx, &models.CreateConversationCommand{
 		IdempotencyKey: req.IdempotencyKey,
 		Owner: &models.CreateConversationCommandOwner{
 			Type:       models.ActorType(req.Owner.Type),
 			Identifier: req.Owner.Identifier,
 		},
 		AIRelayOptions: &models.CreateConversationCommandAIRelayOptions{
-			ProviderID: req.AIRelayOptions.ProviderID,
-			ModelID:    req.AIRelayOptions.ModelID,
+			ProviderID: aiRelayOptions.ProviderID,
+			ModelID:    aiRelayOptions.ModelID,
 		},
 	})
 	if err != nil {
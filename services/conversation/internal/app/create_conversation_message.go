@@ -5,11 +5,8 @@ import (
 	"fmt"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
-	"github.com/0xdeafcafe/bloefish/libraries/clog"
-	"github.com/0xdeafcafe/bloefish/libraries/forkedcontext"
 	"github.com/0xdeafcafe/bloefish/services/airelay"
 	"github.com/0xdeafcafe/bloefish/services/skillset"
-	"github.com/0xdeafcafe/bloefish/services/stream"
 
 	"github.com/0xdeafcafe/bloefish/services/conversation"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
@@ -24,15 +21,16 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 		return nil, cher.New("invalid_owner", cher.M{"identifier": req.Owner.Identifier})
 	}
 
-	skillSets, err := a.SkillSetService.GetManySkillSets(ctx, &skillset.GetManySkillSetsRequest{
+	// Skill sets are loaded again when the reply is generated, this makes sure
+	// they exist and belong to the owner before anything is created
+	if _, err := a.SkillSetService.GetManySkillSets(ctx, &skillset.GetManySkillSetsRequest{
 		SkillSetIDs: req.SkillSetIDs,
 		Owner: &skillset.Actor{
 			Type:       skillset.ActorType(req.Owner.Type),
 			Identifier: req.Owner.Identifier,
 		},
 		AllowDeleted: false,
-	})
-	if err != nil {
+	}); err != nil {
 		return nil, err
 	}
 
@@ -47,15 +45,35 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 		})
 	}
 
-	var interactionAIRelayOptions *models.CreateInteractionCommandAIRelayOptions
+	// Messages without a model use the conversation's, and conversations
+	// without one use the owner's default
+	aiRelayOptions := convo.AIRelayOptions
 	if req.AIRelayOptions != nil {
-		interactionAIRelayOptions = &models.CreateInteractionCommandAIRelayOptions{
+		aiRelayOptions = &models.AIRelayOptions{
 			ProviderID: req.AIRelayOptions.ProviderID,
 			ModelID:    req.AIRelayOptions.ModelID,
 		}
 	}
+	if aiRelayOptions == nil {
+		aiRelayOptions = a.getDefaultAIRelayOptions(ctx, &airelay.Actor{
+			Type:       airelay.ActorType(req.Owner.Type),
+			Identifier: req.Owner.Identifier,
+		})
+	}
+	if aiRelayOptions == nil {
+		return nil, cher.New("ai_relay_options_required", cher.M{
+			"conversation_id": convo.ID,
+		})
+	}
 
-	interaction, err := a.InteractionRepository.Create(ctx, &models.CreateInteractionCommand{
+	interactionAIRelayOptions := &models.CreateInteractionCommandAIRelayOptions{
+		ProviderID: aiRelayOptions.ProviderID,
+		ModelID:    aiRelayOptions.ModelID,
+	}
+
+	// Retried requests get the original interactions back, and the jobs
+	// queued for them are only ever queued once
+	interaction, _, err := a.InteractionRepository.Create(ctx, &models.CreateInteractionCommand{
 		IdempotencyKey: req.IdempotencyKey,
 		ConversationID: convo.ID,
 		FileIDs:        req.FileIDs,
@@ -75,35 +93,34 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 		if newConversation, err := a.InteractionRepository.ConversationHasInteractions(ctx, convo.ID); err != nil {
 			return nil, err
 		} else if newConversation {
-			titleStreamingChannelID := fmt.Sprintf("%s/title", convo.ID)
-
-			forkedcontext.ForkContext(ctx, func(ctx context.Context) error {
-				if err := a.generateConversationTitle(ctx, &generateConversationTitleCommand{
-					Conversation: convo,
-					Owner: &airelay.Actor{
-						Type:       airelay.ActorType(req.Owner.Type),
-						Identifier: req.Owner.Identifier,
-					},
-					Interaction:        interaction,
-					StreamingChannelID: titleStreamingChannelID,
-					UseStreaming:       req.Options.UseStreaming,
-				}); err != nil {
-					if sendErrorErr := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
-						ChannelID: titleStreamingChannelID,
-						Error:     cher.Coerce(err),
-					}); sendErrorErr != nil {
-						clog.Get(ctx).WithError(sendErrorErr).Error("failed to send title error message to stream service")
-					}
-
-					clog.Get(ctx).WithError(err).Error("failed to generate conversation title")
-				}
+			titleJobCmd := &models.EnqueueJobCommand{
+				IdempotencyKey:     fmt.Sprintf("title/%s", convo.ID),
+				Type:               models.JobTypeConversationTitle,
+				ConversationID:     convo.ID,
+				InteractionID:      interaction.ID,
+				StreamingChannelID: fmt.Sprintf("%s/title", convo.ID),
+				UseStreaming:       req.Options.UseStreaming,
+				MaxAttempts:        a.JobMaxAttempts,
+			}
 
-				return nil
-			})
+			titleJob, err := a.JobRepository.Enqueue(ctx, titleJobCmd)
+			if err != nil {
+				return nil, fmt.Errorf("failed to enqueue title job: %w", err)
+			}
+
+			// The conversation's title job gave up, so the title is tried again
+			// with this message
+			if titleJob.FailedAt != nil {
+				titleJobCmd.IdempotencyKey = fmt.Sprintf("title/%s/%s", convo.ID, interaction.ID)
+
+				if _, err := a.JobRepository.Enqueue(ctx, titleJobCmd); err != nil {
+					return nil, fmt.Errorf("failed to enqueue title job: %w", err)
+				}
+			}
 		}
 	}
 
-	activeInteraction, err := a.InteractionRepository.CreateActive(ctx, &models.CreateActiveInteractionCommand{
+	activeInteraction, _, err := a.InteractionRepository.CreateActive(ctx, &models.CreateActiveInteractionCommand{
 		IdempotencyKey: fmt.Sprintf("%s-response", req.IdempotencyKey),
 		ConversationID: convo.ID,
 		FileIDs:        []string{},
@@ -123,36 +140,23 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 	}
 
 	streamingChannelID := fmt.Sprintf("%s/%s", convo.ID, activeInteraction.ID)
-	forkedcontext.ForkContext(ctx, func(ctx context.Context) error {
-		if err := a.createConversationMessageReply(ctx, &createConversationMessageReplyCommand{
-			Conversation: convo,
-			Owner: &airelay.Actor{
-				Type:       airelay.ActorType(interaction.Owner.Type),
-				Identifier: interaction.Owner.Identifier,
-			},
-			SkillSets:          skillSets.SkillSets,
-			Interaction:        interaction,
-			ActiveInteraction:  activeInteraction,
-			StreamingChannelID: streamingChannelID,
-			UseStreaming:       req.Options.UseStreaming,
-		}); err != nil {
-			if sendErrorErr := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
-				ChannelID: streamingChannelID,
-				Error:     cher.Coerce(err),
-			}); sendErrorErr != nil {
-				clog.Get(ctx).WithError(sendErrorErr).Error("failed to send error message to stream service")
-			}
-
-			if saveErrorErr := a.InteractionRepository.AddError(ctx, activeInteraction.ID, cher.Coerce(err)); saveErrorErr != nil {
-				clog.Get(ctx).WithError(saveErrorErr).Error("failed to save error to interaction")
-			}
-
-			clog.Get(ctx).WithError(err).Error("failed to create conversation message reply")
-		}
+	if _, err := a.JobRepository.Enqueue(ctx, &models.EnqueueJobCommand{
+		IdempotencyKey:      fmt.Sprintf("reply/%s", activeInteraction.ID),
+		Type:                models.JobTypeConversationMessageReply,
+		ConversationID:      convo.ID,
+		InteractionID:       interaction.ID,
+		ActiveInteractionID: activeInteraction.ID,
+		StreamingChannelID:  streamingChannelID,
+		UseStreaming:        req.Options.UseStreaming,
+		MaxAttempts:         a.JobMaxAttempts,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to enqueue reply job: %w", err)
+	}
 
-		return nil
-	})
+	return newCreateConversationMessageResponse(convo, interaction, activeInteraction, streamingChannelID), nil
+}
 
+func newCreateConversationMessageResponse(convo *models.Conversation, interaction, activeInteraction *models.Interaction, streamingChannelID string) *conversation.CreateConversationMessageResponse {
 	return &conversation.CreateConversationMessageResponse{
 		ConversationID: convo.ID,
 		InputInteraction: &conversation.CreateConversationMessageResponseInteraction{
@@ -163,8 +167,10 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 
 			MarkedAsExcludedAt: interaction.MarkedAsExcludedAt,
 
-			MessageContent: interaction.MessageContent,
-			Errors:         interaction.Errors,
+			Status:           conversation.InteractionStatus(interaction.Status),
+			MessageContent:   interaction.MessageContent,
+			ReasoningContent: interaction.ReasoningContent,
+			Errors:           interaction.Errors,
 
 			AIRelayOptions: &conversation.AIRelayOptions{
 				ProviderID: interaction.AIRelayOptions.ProviderID,
@@ -179,6 +185,9 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 			UpdatedAt:   interaction.UpdatedAt,
 			CompletedAt: interaction.CompletedAt,
 			DeletedAt:   interaction.DeletedAt,
+
+			StartedAt:    interaction.StartedAt,
+			FirstTokenAt: interaction.FirstTokenAt,
 		},
 		ResponseInteraction: &conversation.CreateConversationMessageResponseInteraction{
 			ID:              activeInteraction.ID,
@@ -188,8 +197,10 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 
 			MarkedAsExcludedAt: activeInteraction.MarkedAsExcludedAt,
 
-			MessageContent: activeInteraction.MessageContent,
-			Errors:         activeInteraction.Errors,
+			Status:           conversation.InteractionStatus(activeInteraction.Status),
+			MessageContent:   activeInteraction.MessageContent,
+			ReasoningContent: activeInteraction.ReasoningContent,
+			Errors:           activeInteraction.Errors,
 
 			AIRelayOptions: &conversation.AIRelayOptions{
 				ProviderID: activeInteraction.AIRelayOptions.ProviderID,
@@ -204,7 +215,10 @@ func (a *App) CreateConversationMessage(ctx context.Context, req *conversation.C
 			UpdatedAt:   activeInteraction.UpdatedAt,
 			CompletedAt: activeInteraction.CompletedAt,
 			DeletedAt:   activeInteraction.DeletedAt,
+
+			StartedAt:    activeInteraction.StartedAt,
+			FirstTokenAt: activeInteraction.FirstTokenAt,
 		},
 		StreamChannelID: streamingChannelID,
-	}, nil
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/services/airelay"
@@ -53,8 +54,10 @@ func (a *App) createConversationMessageReply(
 		}
 	}
 
+	// Only message content is sent back as context, a previous reply's reasoning
+	// is never fed into later turns.
 	for _, interaction := range conversationInteractions {
-		if interaction.CompletedAt == nil || interaction.MarkedAsExcludedAt != nil {
+		if interaction.Status != models.InteractionStatusCompleted || interaction.MarkedAsExcludedAt != nil {
 			continue
 		}
 
@@ -79,7 +82,8 @@ func (a *App) createConversationMessageReply(
 		}
 	}
 
-	var messageContent string
+	var messageContent, reasoningContent string
+	var firstTokenAt *time.Time
 	if cmd.UseStreaming {
 		response, err := a.AIRelayService.InvokeStreamingConversationMessage(ctx, &airelay.InvokeStreamingConversationMessageRequest{
 			ConversationID:     cmd.Conversation.ID,
@@ -100,6 +104,8 @@ func (a *App) createConversationMessageReply(
 		}
 
 		messageContent = response.MessageContent
+		reasoningContent = response.ReasoningContent
+		firstTokenAt = response.FirstTokenAt
 	} else {
 		response, err := a.AIRelayService.InvokeConversationMessage(ctx, &airelay.InvokeConversationMessageRequest{
 			ConversationID: cmd.Conversation.ID,
@@ -119,9 +125,11 @@ func (a *App) createConversationMessageReply(
 		}
 
 		messageContent = response.MessageContent
+		reasoningContent = response.ReasoningContent
+		firstTokenAt = response.FirstTokenAt
 	}
 
-	if err := a.InteractionRepository.MarkActiveAsComplete(ctx, cmd.ActiveInteraction.ID, messageContent); err != nil {
+	if err := a.InteractionRepository.MarkActiveAsComplete(ctx, cmd.ActiveInteraction.ID, messageContent, reasoningContent, firstTokenAt); err != nil {
 		return err
 	}
 
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) CreateConversationShare(ctx context.Context, req *conversation.CreateConversationShareRequest) (*conversation.CreateConversationShareResponse, error) {
+	convo, err := a.ConversationRepository.GetByID(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if convo.Owner.Type != models.ActorType(req.Owner.Type) || convo.Owner.Identifier != req.Owner.Identifier {
+		return nil, cher.New("invalid_owner", cher.M{
+			"type":       req.Owner.Type,
+			"identifier": req.Owner.Identifier,
+		})
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInSeconds != nil {
+		t := time.Now().Add(time.Duration(*req.ExpiresInSeconds) * time.Second)
+		expiresAt = &t
+	}
+
+	var snapshot *models.ShareSnapshot
+	if models.ShareMode(req.Mode) == models.ShareModeSnapshot {
+		interactions, err := a.InteractionRepository.GetAllByConversationID(ctx, convo.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot = &models.ShareSnapshot{
+			Title:        convo.Title,
+			Interactions: shareableInteractions(interactions),
+		}
+	}
+
+	// Retried requests get the original share back, so only one link is ever
+	// created per request
+	share, err := a.ShareRepository.Create(ctx, &models.CreateShareCommand{
+		IdempotencyKey: req.IdempotencyKey,
+		ConversationID: convo.ID,
+		Owner:          convo.Owner,
+		Mode:           models.ShareMode(req.Mode),
+		Snapshot:       snapshot,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation.CreateConversationShareResponse{
+		ID:             share.ID,
+		ConversationID: share.ConversationID,
+		Token:          share.Token,
+		Mode:           conversation.ShareMode(share.Mode),
+
+		CreatedAt: share.CreatedAt,
+		ExpiresAt: share.ExpiresAt,
+		RevokedAt: share.RevokedAt,
+	}, nil
+}
+
+// shareableInteractions picks out the interactions which are shown in a
+// shared conversation, and strips them down to what the viewer is allowed to
+// see. Excluded interactions are left out, as are replies which failed or
+// were cancelled as their errors are only meant for the owner.
+func shareableInteractions(interactions []*models.Interaction) []*models.ShareSnapshotInteraction {
+	shareable := make([]*models.ShareSnapshotInteraction, 0, len(interactions))
+
+	for _, interaction := range interactions {
+		if interaction.MarkedAsExcludedAt != nil {
+			continue
+		}
+
+		switch interaction.Status {
+		case models.InteractionStatusFailed, models.InteractionStatusCancelled:
+			continue
+		}
+
+		shareable = append(shareable, &models.ShareSnapshotInteraction{
+			ID:               interaction.ID,
+			OwnerType:        interaction.Owner.Type,
+			AIRelayOptions:   interaction.AIRelayOptions,
+			Status:           interaction.Status,
+			MessageContent:   interaction.MessageContent,
+			ReasoningContent: interaction.ReasoningContent,
+
+			CreatedAt:   interaction.CreatedAt,
+			CompletedAt: interaction.CompletedAt,
+		})
+	}
+
+	return shareable
+}
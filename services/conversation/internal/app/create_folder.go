@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) CreateFolder(ctx context.Context, req *conversation.CreateFolderRequest) (*conversation.CreateFolderResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, cher.New("invalid_name", nil)
+	}
+
+	folder, err := a.FolderRepository.Create(ctx, &models.CreateFolderCommand{
+		IdempotencyKey: req.IdempotencyKey,
+		Owner: &models.Actor{
+			Type:       models.ActorType(req.Owner.Type),
+			Identifier: req.Owner.Identifier,
+		},
+		Name: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation.CreateFolderResponse{
+		ID: folder.ID,
+		Owner: &conversation.Actor{
+			Type:       conversation.ActorType(folder.Owner.Type),
+			Identifier: folder.Owner.Identifier,
+		},
+		Name: folder.Name,
+
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}, nil
+}
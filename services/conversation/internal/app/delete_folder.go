@@ -0,0 +1,22 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) DeleteFolder(ctx context.Context, req *conversation.DeleteFolderRequest) error {
+	folder, err := a.FolderRepository.GetByID(ctx, req.FolderID)
+	if err != nil {
+		return err
+	}
+
+	// The folder is deleted first so nothing can be moved into it while its
+	// conversations are being taken out
+	if err := a.FolderRepository.Delete(ctx, folder.ID); err != nil {
+		return err
+	}
+
+	return a.ConversationRepository.RemoveAllFromFolder(ctx, folder.ID)
+}
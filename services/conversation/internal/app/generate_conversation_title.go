@@ -4,12 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/services/airelay"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/stream"
 )
 
+const maxConversationTitleLength = 100
+
+// DefaultTitlePromptTemplate is used when no title prompt template is configured.
+// It is rendered with a TitlePromptData.
+const DefaultTitlePromptTemplate = "Generate a conversation title for the following text. It should be concise but descriptive. It should be a single sentence, no more than 100 characters. Return nothing but the title. The output should not be wrapped in double quotes at all, or use any markdown formatting. Again, do not wrap the output in quotes, just return it as is.\n\n {{ .MessageContent }}"
+
+var (
+	thinkBlockRegex     = regexp.MustCompile(`(?s)<think>.*?</think>`)
+	unclosedThinkRegex  = regexp.MustCompile(`(?s)<think>.*$`)
+	titleQuoteCutset    = "\"'`“”‘’«»"
+	titleMarkdownCutset = "#*_ "
+)
+
+type TitlePromptData struct {
+	MessageContent string
+}
+
 type generateConversationTitleCommand struct {
 	Conversation *models.Conversation
 	Owner        *airelay.Actor
@@ -23,7 +45,7 @@ type generateConversationTitleCommand struct {
 func (a *App) generateConversationTitle(
 	ctx context.Context,
 	cmd *generateConversationTitleCommand,
-) error {
+) (string, error) {
 	aiRelayOptions := &models.AIRelayOptions{
 		ProviderID: cmd.Conversation.AIRelayOptions.ProviderID,
 		ModelID:    cmd.Conversation.AIRelayOptions.ModelID,
@@ -36,12 +58,24 @@ func (a *App) generateConversationTitle(
 		}
 	}
 
+	if a.TitleAIRelayOptions != nil {
+		aiRelayOptions = a.TitleAIRelayOptions
+	}
+
+	if titleAIRelayOptions := a.getTitleGenerationAIRelayOptions(ctx, cmd.Owner); titleAIRelayOptions != nil {
+		aiRelayOptions = titleAIRelayOptions
+	}
+
+	var prompt strings.Builder
+	if err := a.TitlePromptTemplate.Execute(&prompt, &TitlePromptData{
+		MessageContent: cmd.Interaction.MessageContent,
+	}); err != nil {
+		return "", fmt.Errorf("failed to render title prompt: %w", err)
+	}
+
 	var messageContent string
 	messages := []*airelay.InvokeConversationMessageRequestMessage{{
-		Content: fmt.Sprintf(
-			"Generate a conversation title for the following text. It should be concise but descriptive. It should be a single sentence, no more than 100 characters. Return nothing but the title. The output should not be wrapped in double quotes at all, or use any markdown formatting. Again, do not wrap the output in quotes, just return it as is.\n\n %s",
-			cmd.Interaction.MessageContent,
-		),
+		Content: prompt.String(),
 		Owner:   cmd.Owner,
 		FileIDs: []string{}, // Titles should not support files
 	}}
@@ -62,7 +96,7 @@ func (a *App) generateConversationTitle(
 			json, _ := json.Marshal(err)
 			clog.Get(ctx).WithError(err).WithField("json", string(json)).Error("failed to call invoke streaming conversation message to generate title")
 
-			return err
+			return "", err
 		}
 
 		messageContent = response.MessageContent
@@ -81,15 +115,72 @@ func (a *App) generateConversationTitle(
 			json, _ := json.Marshal(err)
 			clog.Get(ctx).WithError(err).WithField("json", string(json)).Error("failed to call invoke conversation message to generate title")
 
-			return err
+			return "", err
 		}
 
 		messageContent = response.MessageContent
 	}
 
-	if err := a.ConversationRepository.UpdateTitle(ctx, cmd.Conversation.ID, messageContent); err != nil {
-		return fmt.Errorf("failed to update conversation title: %w", err)
+	title := sanitizeConversationTitle(messageContent)
+	if title == "" {
+		return "", cher.New("title_generation_failed", cher.M{
+			"conversation_id": cmd.Conversation.ID,
+			"provider_id":     aiRelayOptions.ProviderID,
+			"model_id":        aiRelayOptions.ModelID,
+		})
+	}
+
+	if err := a.ConversationRepository.UpdateTitle(ctx, cmd.Conversation.ID, title); err != nil {
+		return "", fmt.Errorf("failed to update conversation title: %w", err)
+	}
+
+	// The streamed fragments are the model's raw output, so clients are sent the
+	// sanitized title to replace them with
+	if cmd.UseStreaming {
+		if err := a.StreamService.SendMessageFull(ctx, &stream.SendMessageFullRequest{
+			ChannelID:      cmd.StreamingChannelID,
+			MessageContent: title,
+		}); err != nil {
+			clog.Get(ctx).WithError(err).Warn("failed to send sanitized conversation title")
+		}
+	}
+
+	return title, nil
+}
+
+// sanitizeConversationTitle cleans up a title, whether it came from a model or a
+// user. Models don't reliably follow the prompt, so reasoning blocks, wrapping
+// quotes and markdown are stripped, only the first line is kept, and the result
+// is cut down to maxConversationTitleLength characters.
+func sanitizeConversationTitle(title string) string {
+	title = thinkBlockRegex.ReplaceAllString(title, "")
+	title = unclosedThinkRegex.ReplaceAllString(title, "")
+
+	firstLine := ""
+	for _, line := range strings.Split(title, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			firstLine = line
+			break
+		}
+	}
+
+	// Markdown and quotes can be nested in either order, such as "**Title**"
+	title = firstLine
+	for {
+		trimmed := strings.Trim(title, titleMarkdownCutset)
+		trimmed = strings.Trim(trimmed, titleQuoteCutset)
+		trimmed = strings.TrimSpace(trimmed)
+
+		if trimmed == title {
+			break
+		}
+
+		title = trimmed
+	}
+
+	if utf8.RuneCountInString(title) > maxConversationTitleLength {
+		title = strings.TrimSpace(string([]rune(title)[:maxConversationTitleLength]))
 	}
 
-	return nil
+	return title
 }
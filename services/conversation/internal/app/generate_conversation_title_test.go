@@ -0,0 +1,47 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSanitizeConversationTitle(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Title string
+
+		Expected string
+	}{
+		{"Empty", "", ""},
+		{"Plain", "Planning a trip to Lisbon", "Planning a trip to Lisbon"},
+		{"Whitespace", "  Planning a trip  ", "Planning a trip"},
+		{"BlankLines", "\n  \n\t\n", ""},
+		{"FirstNonBlankLine", "\n\nPlanning a trip\nSecond line", "Planning a trip"},
+		{"ThinkBlock", "<think>The user wants a title</think>\n\nPlanning a trip", "Planning a trip"},
+		{"MultipleThinkBlocks", "<think>a</think>Planning<think>b</think> a trip", "Planning a trip"},
+		{"UnclosedThinkBlock", "Planning a trip<think>wait, maybe", "Planning a trip"},
+		{"OnlyThinkBlock", "<think>The user wants a title</think>", ""},
+		{"DoubleQuotes", `"Planning a trip"`, "Planning a trip"},
+		{"SmartQuotes", "“Planning a trip”", "Planning a trip"},
+		{"Heading", "# Planning a trip", "Planning a trip"},
+		{"Bold", "**Planning a trip**", "Planning a trip"},
+		{"QuotedBold", `"**Planning a trip**"`, "Planning a trip"},
+		{"BoldQuoted", `**"Planning a trip"**`, "Planning a trip"},
+		{"QuotedHeading", `"# Planning a trip"`, "Planning a trip"},
+		{"OnlyMarkdown", "** **", ""},
+		{"InnerMarkdownKept", "Planning a *big* trip", "Planning a *big* trip"},
+		{"Truncated", strings.Repeat("a", maxConversationTitleLength+10), strings.Repeat("a", maxConversationTitleLength)},
+		{"TruncatedRunes", strings.Repeat("é", maxConversationTitleLength+10), strings.Repeat("é", maxConversationTitleLength)},
+		{"TruncatedTrailingSpace", strings.Repeat("a", maxConversationTitleLength-1) + " b", strings.Repeat("a", maxConversationTitleLength-1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			is.Equal(sanitizeConversationTitle(test.Title), test.Expected)
+		})
+	}
+}
@@ -30,6 +30,11 @@ func (a *App) GetConversationWithInteractions(ctx context.Context, req *conversa
 		Title:           convo.Title,
 		StreamChannelID: convo.ID,
 
+		FolderID:   convo.FolderID,
+		Tags:       convo.Tags,
+		PinnedAt:   convo.PinnedAt,
+		ArchivedAt: convo.ArchivedAt,
+
 		Interactions: make([]*conversation.GetConversationWithInteractionsResponseInteraction, len(interactions)),
 
 		CreatedAt: convo.CreatedAt,
@@ -44,7 +49,9 @@ func (a *App) GetConversationWithInteractions(ctx context.Context, req *conversa
 			SkillSetIDs: interaction.SkillSetIDs,
 
 			MarkedAsExcludedAt: interaction.MarkedAsExcludedAt,
+			Status:             conversation.InteractionStatus(interaction.Status),
 			MessageContent:     interaction.MessageContent,
+			ReasoningContent:   interaction.ReasoningContent,
 			Errors:             interaction.Errors,
 
 			Owner: &conversation.Actor{
@@ -60,6 +67,9 @@ func (a *App) GetConversationWithInteractions(ctx context.Context, req *conversa
 			UpdatedAt:   interaction.UpdatedAt,
 			CompletedAt: interaction.CompletedAt,
 			DeletedAt:   interaction.DeletedAt,
+
+			StartedAt:    interaction.StartedAt,
+			FirstTokenAt: interaction.FirstTokenAt,
 		}
 	}
 
@@ -19,8 +19,11 @@ func (a *App) GetInteraction(ctx context.Context, req *conversation.GetInteracti
 		SkillSetIDs:    foundInteraction.SkillSetIDs,
 
 		MarkedAsExcludedAt: foundInteraction.MarkedAsExcludedAt,
-		MessageContent:     foundInteraction.MessageContent,
-		Errors:             foundInteraction.Errors,
+
+		Status:           conversation.InteractionStatus(foundInteraction.Status),
+		MessageContent:   foundInteraction.MessageContent,
+		ReasoningContent: foundInteraction.ReasoningContent,
+		Errors:           foundInteraction.Errors,
 
 		Owner: &conversation.Actor{
 			Type:       conversation.ActorType(foundInteraction.Owner.Type),
@@ -35,5 +38,8 @@ func (a *App) GetInteraction(ctx context.Context, req *conversation.GetInteracti
 		UpdatedAt:   foundInteraction.UpdatedAt,
 		DeletedAt:   foundInteraction.DeletedAt,
 		CompletedAt: foundInteraction.CompletedAt,
+
+		StartedAt:    foundInteraction.StartedAt,
+		FirstTokenAt: foundInteraction.FirstTokenAt,
 	}, nil
 }
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) GetSharedConversation(ctx context.Context, req *conversation.GetSharedConversationRequest) (*conversation.GetSharedConversationResponse, error) {
+	share, err := a.ShareRepository.GetByToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revoked and expired shares look the same as ones which never existed, so
+	// the viewer can't tell anything about the conversation from the token
+	if share.RevokedAt != nil || (share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now())) {
+		return nil, cher.New("share_not_found", nil)
+	}
+
+	convo, err := a.ConversationRepository.GetByID(ctx, share.ConversationID)
+	if err != nil {
+		if _, ok := cher.AsCherWithCode(err, "conversation_not_found"); ok {
+			return nil, cher.New("share_not_found", nil)
+		}
+
+		return nil, err
+	}
+	if convo.DeletedAt != nil {
+		return nil, cher.New("share_not_found", nil)
+	}
+
+	snapshot := share.Snapshot
+	if share.Mode == models.ShareModeLive {
+		interactions, err := a.InteractionRepository.GetAllByConversationID(ctx, convo.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot = &models.ShareSnapshot{
+			Title:        convo.Title,
+			Interactions: shareableInteractions(interactions),
+		}
+	}
+
+	resp := &conversation.GetSharedConversationResponse{
+		Mode:  conversation.ShareMode(share.Mode),
+		Title: snapshot.Title,
+
+		Interactions: make([]*conversation.GetSharedConversationResponseInteraction, len(snapshot.Interactions)),
+
+		SharedAt:  share.CreatedAt,
+		ExpiresAt: share.ExpiresAt,
+	}
+
+	for i, interaction := range snapshot.Interactions {
+		resp.Interactions[i] = &conversation.GetSharedConversationResponseInteraction{
+			ID:        interaction.ID,
+			OwnerType: conversation.ActorType(interaction.OwnerType),
+			AIRelayOptions: &conversation.AIRelayOptions{
+				ProviderID: interaction.AIRelayOptions.ProviderID,
+				ModelID:    interaction.AIRelayOptions.ModelID,
+			},
+
+			Status:           conversation.InteractionStatus(interaction.Status),
+			MessageContent:   interaction.MessageContent,
+			ReasoningContent: interaction.ReasoningContent,
+
+			CreatedAt:   interaction.CreatedAt,
+			CompletedAt: interaction.CompletedAt,
+		}
+	}
+
+	return resp, nil
+}
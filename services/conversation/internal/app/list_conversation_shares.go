@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) ListConversationShares(ctx context.Context, req *conversation.ListConversationSharesRequest) (*conversation.ListConversationSharesResponse, error) {
+	convo, err := a.ConversationRepository.GetByID(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if convo.Owner.Type != models.ActorType(req.Owner.Type) || convo.Owner.Identifier != req.Owner.Identifier {
+		return nil, cher.New("invalid_owner", cher.M{
+			"type":       req.Owner.Type,
+			"identifier": req.Owner.Identifier,
+		})
+	}
+
+	shares, err := a.ShareRepository.ListByConversationID(ctx, convo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &conversation.ListConversationSharesResponse{
+		Shares: make([]*conversation.ListConversationSharesResponseShare, len(shares)),
+	}
+
+	for i, share := range shares {
+		resp.Shares[i] = &conversation.ListConversationSharesResponseShare{
+			ID:             share.ID,
+			ConversationID: share.ConversationID,
+			Token:          share.Token,
+			Mode:           conversation.ShareMode(share.Mode),
+
+			CreatedAt: share.CreatedAt,
+			ExpiresAt: share.ExpiresAt,
+			RevokedAt: share.RevokedAt,
+		}
+	}
+
+	return resp, nil
+}
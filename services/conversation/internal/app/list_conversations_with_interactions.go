@@ -13,10 +13,20 @@ import (
 )
 
 func (a *App) ListConversationsWithInteractions(ctx context.Context, req *conversation.ListConversationsWithInteractionsRequest) (*conversation.ListConversationsWithInteractionsResponse, error) {
+	var filter *models.ConversationFilter
+	if req.Filter != nil {
+		filter = &models.ConversationFilter{
+			FolderID: req.Filter.FolderID,
+			Tags:     sanitizeConversationTags(req.Filter.Tags),
+			Pinned:   req.Filter.Pinned,
+			Archived: req.Filter.Archived,
+		}
+	}
+
 	conversations, err := a.ConversationRepository.ListByOwner(ctx, models.Actor{
 		Type:       models.ActorType(req.Owner.Type),
 		Identifier: req.Owner.Identifier,
-	})
+	}, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +82,11 @@ func (a *App) ListConversationsWithInteractions(ctx context.Context, req *conver
 			Title:           convo.Title,
 			StreamChannelID: convo.ID,
 
+			FolderID:   convo.FolderID,
+			Tags:       convo.Tags,
+			PinnedAt:   convo.PinnedAt,
+			ArchivedAt: convo.ArchivedAt,
+
 			Interactions: make([]*conversation.ListConversationsWithInteractionsResponseConversationInteraction, len(relics[convo.ID])),
 
 			CreatedAt: convo.CreatedAt,
@@ -88,8 +103,10 @@ func (a *App) ListConversationsWithInteractions(ctx context.Context, req *conver
 
 				MarkedAsExcludedAt: interaction.MarkedAsExcludedAt,
 
-				MessageContent: interaction.MessageContent,
-				Errors:         interaction.Errors,
+				Status:           conversation.InteractionStatus(interaction.Status),
+				MessageContent:   interaction.MessageContent,
+				ReasoningContent: interaction.ReasoningContent,
+				Errors:           interaction.Errors,
 
 				Owner: &conversation.Actor{
 					Type:       conversation.ActorType(interaction.Owner.Type),
@@ -104,6 +121,9 @@ func (a *App) ListConversationsWithInteractions(ctx context.Context, req *conver
 				CompletedAt: interaction.CompletedAt,
 				UpdatedAt:   interaction.UpdatedAt,
 				DeletedAt:   interaction.DeletedAt,
+
+				StartedAt:    interaction.StartedAt,
+				FirstTokenAt: interaction.FirstTokenAt,
 			}
 		}
 	}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) ListFolders(ctx context.Context, req *conversation.ListFoldersRequest) (*conversation.ListFoldersResponse, error) {
+	folders, err := a.FolderRepository.ListByOwner(ctx, models.Actor{
+		Type:       models.ActorType(req.Owner.Type),
+		Identifier: req.Owner.Identifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &conversation.ListFoldersResponse{
+		Folders: make([]*conversation.ListFoldersResponseFolder, len(folders)),
+	}
+
+	for i, folder := range folders {
+		resp.Folders[i] = &conversation.ListFoldersResponseFolder{
+			ID: folder.ID,
+			Owner: &conversation.Actor{
+				Type:       conversation.ActorType(folder.Owner.Type),
+				Identifier: folder.Owner.Identifier,
+			},
+			Name: folder.Name,
+
+			CreatedAt: folder.CreatedAt,
+			UpdatedAt: folder.UpdatedAt,
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the repositories rely on. Creating an index
+// which already exists is a no-op, so this is run on every startup.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"conversations": {
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "idempotency_key", Value: 1},
+				},
+				Options: idempotencyKeyIndexOptions(),
+			},
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "archived_at", Value: 1},
+					{Key: "pinned_at", Value: -1},
+					{Key: "created_at", Value: -1},
+				},
+				Options: options.Index().SetName("owner_list"),
+			},
+			{
+				Keys:    bson.D{{Key: "folder_id", Value: 1}},
+				Options: options.Index().SetName("folder_id"),
+			},
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "tags", Value: 1},
+				},
+				Options: options.Index().SetName("owner_tags"),
+			},
+		},
+		"folders": {
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "idempotency_key", Value: 1},
+				},
+				Options: options.Index().SetName("idempotency_key").SetUnique(true),
+			},
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "name", Value: 1},
+				},
+				Options: options.Index().SetName("owner_name"),
+			},
+		},
+		"interactions": {
+			{
+				Keys: bson.D{
+					{Key: "conversation_id", Value: 1},
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "idempotency_key", Value: 1},
+				},
+				Options: idempotencyKeyIndexOptions(),
+			},
+		},
+		"shares": {
+			{
+				Keys: bson.D{
+					{Key: "conversation_id", Value: 1},
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "idempotency_key", Value: 1},
+				},
+				Options: options.Index().SetName("idempotency_key").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "token", Value: 1}},
+				Options: options.Index().SetName("token").SetUnique(true),
+			},
+		},
+		"jobs": {
+			{
+				Keys:    bson.D{{Key: "idempotency_key", Value: 1}},
+				Options: options.Index().SetName("idempotency_key").SetUnique(true),
+			},
+			{
+				Keys: bson.D{
+					{Key: "completed_at", Value: 1},
+					{Key: "failed_at", Value: 1},
+					{Key: "run_after", Value: 1},
+				},
+				Options: options.Index().SetName("runnable"),
+			},
+			{
+				Keys:    bson.D{{Key: "active_interaction_id", Value: 1}},
+				Options: options.Index().SetName("active_interaction_id"),
+			},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create %s indexes: %w", collection, err)
+		}
+	}
+
+	return nil
+}
+
+// idempotencyKeyIndexOptions only indexes documents with an idempotency key.
+// Conversations and interactions created before idempotency keys were added
+// don't have one, and would otherwise all collide on null.
+func idempotencyKeyIndexOptions() *options.IndexOptions {
+	return options.Index().
+		SetName("idempotency_key").
+		SetUnique(true).
+		SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$type": "string"}})
+}
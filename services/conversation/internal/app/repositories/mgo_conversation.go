@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/ports"
@@ -27,6 +28,11 @@ type persistedConversation struct {
 
 	Title *string `bson:"title"`
 
+	FolderID   *string    `bson:"folder_id"`
+	Tags       []string   `bson:"tags"`
+	PinnedAt   *time.Time `bson:"pinned_at"`
+	ArchivedAt *time.Time `bson:"archived_at"`
+
 	CreatedAt time.Time  `bson:"created_at"`
 	UpdatedAt time.Time  `bson:"updated_at"`
 	DeletedAt *time.Time `bson:"deleted_at"`
@@ -40,14 +46,17 @@ func NewMgoConversation(db *mongo.Database) ports.ConversationRepository {
 	return &mgoConversation{c: db.Collection("conversations")}
 }
 
-func (r *mgoConversation) Create(ctx context.Context, cmd *models.CreateConversationCommand) (*models.Conversation, error) {
-	result := r.c.FindOneAndUpdate(ctx, bson.M{
+func (r *mgoConversation) Create(ctx context.Context, cmd *models.CreateConversationCommand) (*models.Conversation, bool, error) {
+	id := ksuid.Generate(ctx, "conversation").String()
+	filter := bson.M{
 		"idempotency_key":  cmd.IdempotencyKey,
 		"owner.type":       cmd.Owner.Type,
 		"owner.identifier": cmd.Owner.Identifier,
-	}, bson.M{
+	}
+
+	result := r.c.FindOneAndUpdate(ctx, filter, bson.M{
 		"$setOnInsert": bson.M{
-			"_id":             ksuid.Generate(ctx, "conversation").String(),
+			"_id":             id,
 			"idempotency_key": cmd.IdempotencyKey,
 			"owner": bson.M{
 				"type":       cmd.Owner.Type,
@@ -60,6 +69,11 @@ func (r *mgoConversation) Create(ctx context.Context, cmd *models.CreateConversa
 
 			"title": nil,
 
+			"folder_id":   nil,
+			"tags":        bson.A{},
+			"pinned_at":   nil,
+			"archived_at": nil,
+
 			"created_at": time.Now(),
 			"updated_at": nil,
 			"deleted_at": nil,
@@ -67,16 +81,26 @@ func (r *mgoConversation) Create(ctx context.Context, cmd *models.CreateConversa
 	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
 
 	var conversation *persistedConversation
-	if err := result.Decode(&conversation); err != nil {
-		return nil, err
+	err := result.Decode(&conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its conversation
+		err = r.c.FindOne(ctx, filter).Decode(&conversation)
+	}
+	if err != nil {
+		return nil, false, err
 	}
 
-	return conversation.ToDomainModel(), nil
+	return conversation.ToDomainModel(), conversation.ID == id, nil
 }
 
 func (r *mgoConversation) GetByID(ctx context.Context, conversationID string) (*models.Conversation, error) {
 	result := r.c.FindOne(ctx, bson.M{"_id": conversationID})
 	if err := result.Err(); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, cher.New("conversation_not_found", cher.M{"conversation_id": conversationID})
+		}
+
 		return nil, err
 	}
 
@@ -88,12 +112,38 @@ func (r *mgoConversation) GetByID(ctx context.Context, conversationID string) (*
 	return conversation.ToDomainModel(), nil
 }
 
-func (r *mgoConversation) ListByOwner(ctx context.Context, actor models.Actor) ([]*models.Conversation, error) {
-	cursor, err := r.c.Find(ctx, bson.M{
+func (r *mgoConversation) ListByOwner(ctx context.Context, actor models.Actor, filter *models.ConversationFilter) ([]*models.Conversation, error) {
+	query := bson.M{
 		"owner.type":       actor.Type,
 		"owner.identifier": actor.Identifier,
 		"deleted_at":       nil,
-	}, options.Find().SetSort(bson.M{"created_at": -1}))
+		"archived_at":      nil,
+	}
+
+	if filter != nil {
+		if filter.FolderID != nil {
+			query["folder_id"] = *filter.FolderID
+		}
+		if len(filter.Tags) > 0 {
+			query["tags"] = bson.M{"$all": filter.Tags}
+		}
+		if filter.Pinned != nil {
+			if *filter.Pinned {
+				query["pinned_at"] = bson.M{"$ne": nil}
+			} else {
+				query["pinned_at"] = nil
+			}
+		}
+		if filter.Archived {
+			query["archived_at"] = bson.M{"$ne": nil}
+		}
+	}
+
+	// Pinned conversations come first, most recently pinned at the top
+	cursor, err := r.c.Find(ctx, query, options.Find().SetSort(bson.D{
+		{Key: "pinned_at", Value: -1},
+		{Key: "created_at", Value: -1},
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +195,96 @@ func (r *mgoConversation) UpdateTitle(ctx context.Context, conversationID string
 	return nil
 }
 
+func (r *mgoConversation) UpdateFolder(ctx context.Context, conversationID string, folderID *string) error {
+	_, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        conversationID,
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+		"$set": bson.M{
+			"folder_id": folderID,
+		},
+	})
+
+	return err
+}
+
+func (r *mgoConversation) RemoveAllFromFolder(ctx context.Context, folderID string) error {
+	_, err := r.c.UpdateMany(ctx, bson.M{
+		"folder_id": folderID,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+		"$set": bson.M{
+			"folder_id": nil,
+		},
+	})
+
+	return err
+}
+
+func (r *mgoConversation) UpdateTags(ctx context.Context, conversationID string, tags []string) error {
+	_, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        conversationID,
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+		"$set": bson.M{
+			"tags": tags,
+		},
+	})
+
+	return err
+}
+
+func (r *mgoConversation) UpdatePinnedState(ctx context.Context, conversationID string, pinned bool) error {
+	return r.updateTimestampState(ctx, conversationID, "pinned_at", pinned)
+}
+
+func (r *mgoConversation) UpdateArchivedState(ctx context.Context, conversationID string, archived bool) error {
+	return r.updateTimestampState(ctx, conversationID, "archived_at", archived)
+}
+
+// updateTimestampState sets field to the current time when state is true, and
+// clears it when false. Setting a state which is already set keeps the
+// original time.
+func (r *mgoConversation) updateTimestampState(ctx context.Context, conversationID, field string, state bool) error {
+	filter := bson.M{
+		"_id":        conversationID,
+		"deleted_at": nil,
+	}
+	update := bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	}
+
+	if state {
+		filter[field] = nil
+		update["$currentDate"].(bson.M)[field] = true
+	} else {
+		update["$set"] = bson.M{
+			field: nil,
+		}
+	}
+
+	_, err := r.c.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
 func (p *persistedConversation) ToDomainModel() *models.Conversation {
+	// Conversations created before tags were added don't have any
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
 	return &models.Conversation{
 		ID:             p.ID,
 		IdempotencyKey: p.IdempotencyKey,
@@ -160,6 +299,11 @@ func (p *persistedConversation) ToDomainModel() *models.Conversation {
 
 		Title: p.Title,
 
+		FolderID:   p.FolderID,
+		Tags:       tags,
+		PinnedAt:   p.PinnedAt,
+		ArchivedAt: p.ArchivedAt,
+
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
 		DeletedAt: p.DeletedAt,
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type persistedFolder struct {
+	ID             string `bson:"_id"`
+	IdempotencyKey string `bson:"idempotency_key"`
+
+	Owner struct {
+		Type       string `bson:"type"`
+		Identifier string `bson:"identifier"`
+	} `bson:"owner"`
+
+	Name string `bson:"name"`
+
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at"`
+	DeletedAt *time.Time `bson:"deleted_at"`
+}
+
+type mgoFolder struct {
+	c *mongo.Collection
+}
+
+func NewMgoFolder(db *mongo.Database) ports.FolderRepository {
+	return &mgoFolder{c: db.Collection("folders")}
+}
+
+func (r *mgoFolder) Create(ctx context.Context, cmd *models.CreateFolderCommand) (*models.Folder, error) {
+	now := time.Now()
+	filter := bson.M{
+		"idempotency_key":  cmd.IdempotencyKey,
+		"owner.type":       cmd.Owner.Type,
+		"owner.identifier": cmd.Owner.Identifier,
+	}
+
+	result := r.c.FindOneAndUpdate(ctx, filter, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":             ksuid.Generate(ctx, "folder").String(),
+			"idempotency_key": cmd.IdempotencyKey,
+			"owner": bson.M{
+				"type":       cmd.Owner.Type,
+				"identifier": cmd.Owner.Identifier,
+			},
+
+			"name": cmd.Name,
+
+			"created_at": now,
+			"updated_at": now,
+			"deleted_at": nil,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var folder *persistedFolder
+	err := result.Decode(&folder)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its folder
+		err = r.c.FindOne(ctx, filter).Decode(&folder)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return folder.ToDomainModel(), nil
+}
+
+func (r *mgoFolder) GetByID(ctx context.Context, folderID string) (*models.Folder, error) {
+	var folder *persistedFolder
+	if err := r.c.FindOne(ctx, bson.M{
+		"_id":        folderID,
+		"deleted_at": nil,
+	}).Decode(&folder); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, cher.New("folder_not_found", cher.M{"folder_id": folderID})
+		}
+
+		return nil, err
+	}
+
+	return folder.ToDomainModel(), nil
+}
+
+func (r *mgoFolder) ListByOwner(ctx context.Context, actor models.Actor) ([]*models.Folder, error) {
+	cursor, err := r.c.Find(ctx, bson.M{
+		"owner.type":       actor.Type,
+		"owner.identifier": actor.Identifier,
+		"deleted_at":       nil,
+	}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var persistedFolders []*persistedFolder
+	if err := cursor.All(ctx, &persistedFolders); err != nil {
+		return nil, err
+	}
+
+	folders := make([]*models.Folder, len(persistedFolders))
+	for i, persistedFolder := range persistedFolders {
+		folders[i] = persistedFolder.ToDomainModel()
+	}
+
+	return folders, nil
+}
+
+func (r *mgoFolder) UpdateName(ctx context.Context, folderID, name string) error {
+	_, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        folderID,
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+		"$set": bson.M{
+			"name": name,
+		},
+	})
+
+	return err
+}
+
+func (r *mgoFolder) Delete(ctx context.Context, folderID string) error {
+	_, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        folderID,
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"deleted_at": true,
+			"updated_at": true,
+		},
+	})
+
+	return err
+}
+
+func (p *persistedFolder) ToDomainModel() *models.Folder {
+	return &models.Folder{
+		ID:             p.ID,
+		IdempotencyKey: p.IdempotencyKey,
+		Owner: &models.Actor{
+			Type:       models.ActorType(p.Owner.Type),
+			Identifier: p.Owner.Identifier,
+		},
+		Name: p.Name,
+
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+		DeletedAt: p.DeletedAt,
+	}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"maps"
 	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
@@ -22,8 +23,10 @@ type persistedInteraction struct {
 
 	MarkedAsExcludedAt *time.Time `bson:"marked_as_excluded_at"`
 
-	MessageContent string   `bson:"message_content"`
-	Errors         []cher.E `bson:"errors"`
+	Status           string   `bson:"status"`
+	MessageContent   string   `bson:"message_content"`
+	ReasoningContent string   `bson:"reasoning_content"`
+	Errors           []cher.E `bson:"errors"`
 
 	Owner struct {
 		Type       string `bson:"type"`
@@ -38,6 +41,9 @@ type persistedInteraction struct {
 	UpdatedAt   time.Time  `bson:"updated_at"`
 	DeletedAt   *time.Time `bson:"deleted_at"`
 	CompletedAt *time.Time `bson:"completed_at"`
+
+	StartedAt    *time.Time `bson:"started_at"`
+	FirstTokenAt *time.Time `bson:"first_token_at"`
 }
 
 type mgoInteraction struct {
@@ -48,107 +54,162 @@ func NewMgoInteraction(db *mongo.Database) ports.InteractionRepository {
 	return &mgoInteraction{c: db.Collection("interactions")}
 }
 
-func (r *mgoInteraction) Create(ctx context.Context, cmd *models.CreateInteractionCommand) (*models.Interaction, error) {
-	result := r.c.FindOneAndUpdate(ctx, bson.M{
+func (r *mgoInteraction) Create(ctx context.Context, cmd *models.CreateInteractionCommand) (*models.Interaction, bool, error) {
+	now := time.Now()
+
+	return r.createOnce(ctx, bson.M{
 		"idempotency_key":  cmd.IdempotencyKey,
 		"conversation_id":  cmd.ConversationID,
 		"owner.type":       cmd.Owner.Type,
 		"owner.identifier": cmd.Owner.Identifier,
 	}, bson.M{
-		"$currentDate": bson.M{
-			"updated_at": true,
+		"_id":                   ksuid.Generate(ctx, "interaction").String(),
+		"idempotency_key":       cmd.IdempotencyKey,
+		"conversation_id":       cmd.ConversationID,
+		"message_content":       cmd.MessageContent,
+		"file_ids":              cmd.FileIDs,
+		"skill_set_ids":         cmd.SkillSetIDs,
+		"marked_as_excluded_at": nil,
+
+		"owner": bson.M{
+			"type":       cmd.Owner.Type,
+			"identifier": cmd.Owner.Identifier,
 		},
-		"$setOnInsert": bson.M{
-			"_id":                   ksuid.Generate(ctx, "interaction").String(),
-			"idempotency_key":       cmd.IdempotencyKey,
-			"conversation_id":       cmd.ConversationID,
-			"message_content":       cmd.MessageContent,
-			"file_ids":              cmd.FileIDs,
-			"skill_set_ids":         cmd.SkillSetIDs,
-			"marked_as_excluded_at": nil,
-
-			"owner": bson.M{
-				"type":       cmd.Owner.Type,
-				"identifier": cmd.Owner.Identifier,
-			},
-			"ai_relay_options": bson.M{
-				"provider_id": cmd.AIRelayOptions.ProviderID,
-				"model_id":    cmd.AIRelayOptions.ModelID,
-			},
-
-			"created_at":   time.Now(),
-			"completed_at": time.Now(),
-			"deleted_at":   nil,
+		"ai_relay_options": bson.M{
+			"provider_id": cmd.AIRelayOptions.ProviderID,
+			"model_id":    cmd.AIRelayOptions.ModelID,
 		},
-	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
-
-	var interaction *persistedInteraction
-	if err := result.Decode(&interaction); err != nil {
-		return nil, err
-	}
 
-	return interaction.ToDomainModel(), nil
+		"status":         models.InteractionStatusCompleted,
+		"created_at":     now,
+		"updated_at":     now,
+		"completed_at":   now,
+		"deleted_at":     nil,
+		"started_at":     nil,
+		"first_token_at": nil,
+	})
 }
 
-func (r *mgoInteraction) CreateActive(ctx context.Context, cmd *models.CreateActiveInteractionCommand) (*models.Interaction, error) {
-	result := r.c.FindOneAndUpdate(ctx, bson.M{
+func (r *mgoInteraction) CreateActive(ctx context.Context, cmd *models.CreateActiveInteractionCommand) (*models.Interaction, bool, error) {
+	now := time.Now()
+
+	return r.createOnce(ctx, bson.M{
 		"idempotency_key":  cmd.IdempotencyKey,
 		"conversation_id":  cmd.ConversationID,
 		"owner.type":       cmd.Owner.Type,
 		"owner.identifier": cmd.Owner.Identifier,
 	}, bson.M{
-		"$currentDate": bson.M{
-			"updated_at": true,
+		"_id":                   ksuid.Generate(ctx, "interaction").String(),
+		"idempotency_key":       cmd.IdempotencyKey,
+		"conversation_id":       cmd.ConversationID,
+		"message_content":       cmd.MessageContent,
+		"file_ids":              cmd.FileIDs,
+		"skill_set_ids":         cmd.SkillSetIDs,
+		"marked_as_excluded_at": nil,
+
+		"owner": bson.M{
+			"type":       cmd.Owner.Type,
+			"identifier": cmd.Owner.Identifier,
 		},
-		"$setOnInsert": bson.M{
-			"_id":                   ksuid.Generate(ctx, "interaction").String(),
-			"idempotency_key":       cmd.IdempotencyKey,
-			"conversation_id":       cmd.ConversationID,
-			"message_content":       cmd.MessageContent,
-			"file_ids":              cmd.FileIDs,
-			"skill_set_ids":         cmd.SkillSetIDs,
-			"marked_as_excluded_at": nil,
-
-			"owner": bson.M{
-				"type":       cmd.Owner.Type,
-				"identifier": cmd.Owner.Identifier,
-			},
-			"ai_relay_options": bson.M{
-				"provider_id": cmd.AIRelayOptions.ProviderID,
-				"model_id":    cmd.AIRelayOptions.ModelID,
-			},
-
-			"created_at":   time.Now(),
-			"deleted_at":   nil,
-			"completed_at": nil,
+		"ai_relay_options": bson.M{
+			"provider_id": cmd.AIRelayOptions.ProviderID,
+			"model_id":    cmd.AIRelayOptions.ModelID,
 		},
-	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+		"status":         models.InteractionStatusPending,
+		"created_at":     now,
+		"updated_at":     now,
+		"deleted_at":     nil,
+		"completed_at":   nil,
+		"started_at":     nil,
+		"first_token_at": nil,
+	})
+}
+
+// createOnce inserts the document unless one already matches the idempotency
+// filter, in which case the existing interaction is returned instead. The
+// returned bool is true if the interaction was created by this call.
+func (r *mgoInteraction) createOnce(ctx context.Context, filter, document bson.M) (*models.Interaction, bool, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 
 	var interaction *persistedInteraction
-	if err := result.Decode(&interaction); err != nil {
-		return nil, err
+	err := r.c.FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": document}, opts).Decode(&interaction)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its interaction
+		err = r.c.FindOne(ctx, filter).Decode(&interaction)
+	}
+	if err != nil {
+		return nil, false, err
 	}
 
-	return interaction.ToDomainModel(), nil
+	return interaction.ToDomainModel(), interaction.ID == document["_id"], nil
 }
 
-func (r *mgoInteraction) MarkActiveAsComplete(ctx context.Context, interactionID, messageContent string) error {
-	_, err := r.c.UpdateOne(ctx, bson.M{
-		"_id":          interactionID,
-		"confirmed_at": nil,
-	}, bson.M{
+func (r *mgoInteraction) MarkActiveAsStreaming(ctx context.Context, interactionID string) error {
+	return r.transition(ctx, interactionID, models.InteractionStatusStreaming, bson.M{
+		"$currentDate": bson.M{
+			"started_at": true,
+		},
+	})
+}
+
+func (r *mgoInteraction) MarkActiveAsComplete(ctx context.Context, interactionID, messageContent, reasoningContent string, firstTokenAt *time.Time) error {
+	return r.transition(ctx, interactionID, models.InteractionStatusCompleted, bson.M{
 		"$currentDate": bson.M{
-			"updated_at":   true,
 			"completed_at": true,
 		},
 		"$set": bson.M{
-			"message_content": messageContent,
+			"message_content":   messageContent,
+			"reasoning_content": reasoningContent,
+			"first_token_at":    firstTokenAt,
+		},
+	})
+}
+
+func (r *mgoInteraction) MarkActiveAsFailed(ctx context.Context, interactionID string, e cher.E) error {
+	return r.transition(ctx, interactionID, models.InteractionStatusFailed, bson.M{
+		"$push": bson.M{
+			"errors": e,
 		},
 	})
+}
+
+// transition applies the update to the interaction and moves it to status, as
+// long as its current status allows it.
+func (r *mgoInteraction) transition(ctx context.Context, interactionID string, status models.InteractionStatus, update bson.M) error {
+	if _, ok := update["$set"]; !ok {
+		update["$set"] = bson.M{}
+	}
+	if _, ok := update["$currentDate"]; !ok {
+		update["$currentDate"] = bson.M{}
+	}
+
+	update["$set"].(bson.M)["status"] = status
+	update["$currentDate"].(bson.M)["updated_at"] = true
+
+	result, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        interactionID,
+		"deleted_at": nil,
+		"status":     bson.M{"$in": models.InteractionStatusTransitions[status]},
+	}, update)
 	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		interaction, err := r.GetByID(ctx, interactionID)
+		if err != nil {
+			return err
+		}
+
+		return cher.New("invalid_interaction_status_transition", cher.M{
+			"interaction_id": interactionID,
+			"from":           interaction.Status,
+			"to":             status,
+		})
+	}
+
 	return nil
 }
 
@@ -194,20 +255,10 @@ func (r *mgoInteraction) GetAllByConversationID(ctx context.Context, conversatio
 }
 
 func (r *mgoInteraction) DeleteManyByConversationID(ctx context.Context, conversationID string) error {
-	_, err := r.c.UpdateMany(ctx, bson.M{
+	return r.deleteMany(ctx, bson.M{
 		"conversation_id": conversationID,
 		"deleted_at":      nil,
-	}, bson.M{
-		"$currentDate": bson.M{
-			"updated_at": true,
-			"deleted_at": true,
-		},
 	})
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 func (r *mgoInteraction) ConversationHasInteractions(ctx context.Context, conversationID string) (bool, error) {
@@ -223,10 +274,32 @@ func (r *mgoInteraction) ConversationHasInteractions(ctx context.Context, conver
 }
 
 func (r *mgoInteraction) DeleteMany(ctx context.Context, interactionIDs []string) error {
-	_, err := r.c.UpdateMany(ctx, bson.M{
+	return r.deleteMany(ctx, bson.M{
 		"_id":        bson.M{"$in": interactionIDs},
 		"deleted_at": nil,
-	}, bson.M{
+	})
+}
+
+// deleteMany deletes the interactions matching filter, cancelling any replies
+// which haven't finished first.
+func (r *mgoInteraction) deleteMany(ctx context.Context, filter bson.M) error {
+	cancelFilter := bson.M{
+		"status": bson.M{"$in": models.InteractionStatusTransitions[models.InteractionStatusCancelled]},
+	}
+	maps.Copy(cancelFilter, filter)
+
+	if _, err := r.c.UpdateMany(ctx, cancelFilter, bson.M{
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+		"$set": bson.M{
+			"status": models.InteractionStatusCancelled,
+		},
+	}); err != nil {
+		return err
+	}
+
+	_, err := r.c.UpdateMany(ctx, filter, bson.M{
 		"$currentDate": bson.M{
 			"updated_at": true,
 			"deleted_at": true,
@@ -265,23 +338,31 @@ func (r *mgoInteraction) UpdateExcludedState(ctx context.Context, interactionID
 	return nil
 }
 
-func (r *mgoInteraction) AddError(ctx context.Context, interactionID string, e cher.E) error {
-	_, err := r.c.UpdateOne(ctx, bson.M{
-		"_id":        interactionID,
+func (r *mgoInteraction) ListIncompleteActive(ctx context.Context, createdBefore time.Time) ([]*models.Interaction, error) {
+	cursor, err := r.c.Find(ctx, bson.M{
+		"status": bson.M{"$in": bson.A{
+			models.InteractionStatusPending,
+			models.InteractionStatusStreaming,
+		}},
 		"deleted_at": nil,
-	}, bson.M{
-		"$currentDate": bson.M{
-			"updated_at": true,
-		},
-		"$push": bson.M{
-			"errors": e,
-		},
-	})
+		"created_at": bson.M{"$lt": createdBefore},
+	}, options.Find().SetSort(bson.M{"created_at": 1}))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var persistedInteractions []*persistedInteraction
+	if err := cursor.All(ctx, &persistedInteractions); err != nil {
+		return nil, err
 	}
 
-	return nil
+	interactions := make([]*models.Interaction, len(persistedInteractions))
+	for i, persistedInteraction := range persistedInteractions {
+		interactions[i] = persistedInteraction.ToDomainModel()
+	}
+
+	return interactions, nil
 }
 
 func (p *persistedInteraction) ToDomainModel() *models.Interaction {
@@ -294,8 +375,10 @@ func (p *persistedInteraction) ToDomainModel() *models.Interaction {
 
 		MarkedAsExcludedAt: p.MarkedAsExcludedAt,
 
-		MessageContent: p.MessageContent,
-		Errors:         p.Errors,
+		Status:           models.InteractionStatus(p.Status),
+		MessageContent:   p.MessageContent,
+		ReasoningContent: p.ReasoningContent,
+		Errors:           p.Errors,
 
 		Owner: &models.Actor{
 			Type:       models.ActorType(p.Owner.Type),
@@ -310,5 +393,8 @@ func (p *persistedInteraction) ToDomainModel() *models.Interaction {
 		UpdatedAt:   p.UpdatedAt,
 		DeletedAt:   p.DeletedAt,
 		CompletedAt: p.CompletedAt,
+
+		StartedAt:    p.StartedAt,
+		FirstTokenAt: p.FirstTokenAt,
 	}
 }
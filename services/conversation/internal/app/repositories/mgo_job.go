@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type persistedJob struct {
+	ID             string `bson:"_id"`
+	IdempotencyKey string `bson:"idempotency_key"`
+	Type           string `bson:"type"`
+
+	ConversationID      string `bson:"conversation_id"`
+	InteractionID       string `bson:"interaction_id"`
+	ActiveInteractionID string `bson:"active_interaction_id"`
+	StreamingChannelID  string `bson:"streaming_channel_id"`
+	UseStreaming        bool   `bson:"use_streaming"`
+
+	Attempts    int     `bson:"attempts"`
+	MaxAttempts int     `bson:"max_attempts"`
+	LastError   *cher.E `bson:"last_error"`
+
+	RunAfter    time.Time  `bson:"run_after"`
+	LeasedBy    *string    `bson:"leased_by"`
+	LeasedUntil *time.Time `bson:"leased_until"`
+
+	CreatedAt   time.Time  `bson:"created_at"`
+	UpdatedAt   time.Time  `bson:"updated_at"`
+	CompletedAt *time.Time `bson:"completed_at"`
+	FailedAt    *time.Time `bson:"failed_at"`
+}
+
+type mgoJob struct {
+	c *mongo.Collection
+}
+
+func NewMgoJob(db *mongo.Database) ports.JobRepository {
+	return &mgoJob{c: db.Collection("jobs")}
+}
+
+func (r *mgoJob) Enqueue(ctx context.Context, cmd *models.EnqueueJobCommand) (*models.Job, error) {
+	now := time.Now()
+	filter := bson.M{
+		"idempotency_key": cmd.IdempotencyKey,
+	}
+
+	result := r.c.FindOneAndUpdate(ctx, filter, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":             ksuid.Generate(ctx, "job").String(),
+			"idempotency_key": cmd.IdempotencyKey,
+			"type":            cmd.Type,
+
+			"conversation_id":       cmd.ConversationID,
+			"interaction_id":        cmd.InteractionID,
+			"active_interaction_id": cmd.ActiveInteractionID,
+			"streaming_channel_id":  cmd.StreamingChannelID,
+			"use_streaming":         cmd.UseStreaming,
+
+			"attempts":     0,
+			"max_attempts": cmd.MaxAttempts,
+			"last_error":   nil,
+
+			"run_after":    now,
+			"leased_by":    nil,
+			"leased_until": nil,
+
+			"created_at":   now,
+			"updated_at":   now,
+			"completed_at": nil,
+			"failed_at":    nil,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var job *persistedJob
+	err := result.Decode(&job)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its job
+		err = r.c.FindOne(ctx, filter).Decode(&job)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return job.ToDomainModel(), nil
+}
+
+func (r *mgoJob) Lease(ctx context.Context, workerID string, leaseDuration time.Duration) (*models.Job, error) {
+	now := time.Now()
+
+	result := r.c.FindOneAndUpdate(ctx, bson.M{
+		"completed_at": nil,
+		"failed_at":    nil,
+		"run_after":    bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"leased_until": nil},
+			bson.M{"leased_until": bson.M{"$lte": now}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"leased_by":    workerID,
+			"leased_until": now.Add(leaseDuration),
+			"updated_at":   now,
+		},
+		"$inc": bson.M{
+			"attempts": 1,
+		},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.M{"run_after": 1}).
+		SetReturnDocument(options.After),
+	)
+
+	var job *persistedJob
+	if err := result.Decode(&job); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return job.ToDomainModel(), nil
+}
+
+func (r *mgoJob) ExtendLease(ctx context.Context, jobID, workerID string, leaseDuration time.Duration) error {
+	now := time.Now()
+
+	return r.updateLeased(ctx, jobID, workerID, bson.M{
+		"$set": bson.M{
+			"leased_until": now.Add(leaseDuration),
+			"updated_at":   now,
+		},
+	})
+}
+
+func (r *mgoJob) Complete(ctx context.Context, jobID, workerID string) error {
+	now := time.Now()
+
+	return r.updateLeased(ctx, jobID, workerID, bson.M{
+		"$set": bson.M{
+			"leased_by":    nil,
+			"leased_until": nil,
+			"updated_at":   now,
+			"completed_at": now,
+		},
+	})
+}
+
+func (r *mgoJob) Retry(ctx context.Context, jobID, workerID string, runAfter time.Time, e cher.E) error {
+	return r.updateLeased(ctx, jobID, workerID, bson.M{
+		"$set": bson.M{
+			"leased_by":    nil,
+			"leased_until": nil,
+			"last_error":   e,
+			"run_after":    runAfter,
+			"updated_at":   time.Now(),
+		},
+	})
+}
+
+func (r *mgoJob) Fail(ctx context.Context, jobID, workerID string, e cher.E) error {
+	now := time.Now()
+
+	return r.updateLeased(ctx, jobID, workerID, bson.M{
+		"$set": bson.M{
+			"leased_by":    nil,
+			"leased_until": nil,
+			"last_error":   e,
+			"updated_at":   now,
+			"failed_at":    now,
+		},
+	})
+}
+
+func (r *mgoJob) Release(ctx context.Context, jobID, workerID string) error {
+	return r.updateLeased(ctx, jobID, workerID, bson.M{
+		"$set": bson.M{
+			"leased_by":    nil,
+			"leased_until": nil,
+			"updated_at":   time.Now(),
+		},
+		"$inc": bson.M{
+			"attempts": -1,
+		},
+	})
+}
+
+func (r *mgoJob) HasPendingForActiveInteraction(ctx context.Context, interactionID string) (bool, error) {
+	count, err := r.c.CountDocuments(ctx, bson.M{
+		"active_interaction_id": interactionID,
+		"completed_at":          nil,
+		"failed_at":             nil,
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// updateLeased applies the update to an unfinished job, as long as workerID
+// still holds its lease.
+func (r *mgoJob) updateLeased(ctx context.Context, jobID, workerID string, update bson.M) error {
+	result, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":          jobID,
+		"leased_by":    workerID,
+		"completed_at": nil,
+		"failed_at":    nil,
+	}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return cher.New("job_lease_lost", cher.M{
+			"job_id":    jobID,
+			"worker_id": workerID,
+		})
+	}
+
+	return nil
+}
+
+func (p *persistedJob) ToDomainModel() *models.Job {
+	return &models.Job{
+		ID:             p.ID,
+		IdempotencyKey: p.IdempotencyKey,
+		Type:           models.JobType(p.Type),
+
+		ConversationID:      p.ConversationID,
+		InteractionID:       p.InteractionID,
+		ActiveInteractionID: p.ActiveInteractionID,
+		StreamingChannelID:  p.StreamingChannelID,
+		UseStreaming:        p.UseStreaming,
+
+		Attempts:    p.Attempts,
+		MaxAttempts: p.MaxAttempts,
+		LastError:   p.LastError,
+
+		RunAfter:    p.RunAfter,
+		LeasedBy:    p.LeasedBy,
+		LeasedUntil: p.LeasedUntil,
+
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		CompletedAt: p.CompletedAt,
+		FailedAt:    p.FailedAt,
+	}
+}
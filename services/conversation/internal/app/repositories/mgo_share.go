@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// shareTokenBytes is the amount of randomness in a share token. Anyone with
+// the token can read the conversation, so it has to be unguessable.
+const shareTokenBytes = 32
+
+type persistedShare struct {
+	ID             string `bson:"_id"`
+	IdempotencyKey string `bson:"idempotency_key"`
+	ConversationID string `bson:"conversation_id"`
+
+	Owner struct {
+		Type       string `bson:"type"`
+		Identifier string `bson:"identifier"`
+	} `bson:"owner"`
+
+	Token string `bson:"token"`
+	Mode  string `bson:"mode"`
+
+	Snapshot *persistedShareSnapshot `bson:"snapshot"`
+
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at"`
+	ExpiresAt *time.Time `bson:"expires_at"`
+	RevokedAt *time.Time `bson:"revoked_at"`
+}
+
+type persistedShareSnapshot struct {
+	Title        *string                              `bson:"title"`
+	Interactions []*persistedShareSnapshotInteraction `bson:"interactions"`
+}
+
+type persistedShareSnapshotInteraction struct {
+	ID        string `bson:"id"`
+	OwnerType string `bson:"owner_type"`
+
+	AIRelayOptions struct {
+		ProviderID string `bson:"provider_id"`
+		ModelID    string `bson:"model_id"`
+	} `bson:"ai_relay_options"`
+
+	Status           string `bson:"status"`
+	MessageContent   string `bson:"message_content"`
+	ReasoningContent string `bson:"reasoning_content"`
+
+	CreatedAt   time.Time  `bson:"created_at"`
+	CompletedAt *time.Time `bson:"completed_at"`
+}
+
+type mgoShare struct {
+	c *mongo.Collection
+}
+
+func NewMgoShare(db *mongo.Database) ports.ShareRepository {
+	return &mgoShare{c: db.Collection("shares")}
+}
+
+func (r *mgoShare) Create(ctx context.Context, cmd *models.CreateShareCommand) (*models.Share, error) {
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"idempotency_key":  cmd.IdempotencyKey,
+		"conversation_id":  cmd.ConversationID,
+		"owner.type":       cmd.Owner.Type,
+		"owner.identifier": cmd.Owner.Identifier,
+	}
+
+	result := r.c.FindOneAndUpdate(ctx, filter, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":             ksuid.Generate(ctx, "share").String(),
+			"idempotency_key": cmd.IdempotencyKey,
+			"conversation_id": cmd.ConversationID,
+			"owner": bson.M{
+				"type":       cmd.Owner.Type,
+				"identifier": cmd.Owner.Identifier,
+			},
+
+			"token":    token,
+			"mode":     cmd.Mode,
+			"snapshot": newPersistedShareSnapshot(cmd.Snapshot),
+
+			"created_at": now,
+			"updated_at": now,
+			"expires_at": cmd.ExpiresAt,
+			"revoked_at": nil,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var share *persistedShare
+	err = result.Decode(&share)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its share
+		err = r.c.FindOne(ctx, filter).Decode(&share)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return share.ToDomainModel(), nil
+}
+
+func (r *mgoShare) GetByID(ctx context.Context, shareID string) (*models.Share, error) {
+	return r.getOne(ctx, bson.M{"_id": shareID})
+}
+
+func (r *mgoShare) GetByToken(ctx context.Context, token string) (*models.Share, error) {
+	return r.getOne(ctx, bson.M{"token": token})
+}
+
+func (r *mgoShare) ListByConversationID(ctx context.Context, conversationID string) ([]*models.Share, error) {
+	cursor, err := r.c.Find(ctx, bson.M{
+		"conversation_id": conversationID,
+	}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var persistedShares []*persistedShare
+	if err := cursor.All(ctx, &persistedShares); err != nil {
+		return nil, err
+	}
+
+	shares := make([]*models.Share, len(persistedShares))
+	for i, persistedShare := range persistedShares {
+		shares[i] = persistedShare.ToDomainModel()
+	}
+
+	return shares, nil
+}
+
+func (r *mgoShare) Revoke(ctx context.Context, shareID string) error {
+	_, err := r.c.UpdateOne(ctx, bson.M{
+		"_id":        shareID,
+		"revoked_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"revoked_at": true,
+			"updated_at": true,
+		},
+	})
+
+	return err
+}
+
+func (r *mgoShare) getOne(ctx context.Context, filter bson.M) (*models.Share, error) {
+	var share *persistedShare
+	if err := r.c.FindOne(ctx, filter).Decode(&share); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, cher.New("share_not_found", nil)
+		}
+
+		return nil, err
+	}
+
+	return share.ToDomainModel(), nil
+}
+
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func newPersistedShareSnapshot(snapshot *models.ShareSnapshot) *persistedShareSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+
+	p := &persistedShareSnapshot{
+		Title:        snapshot.Title,
+		Interactions: make([]*persistedShareSnapshotInteraction, len(snapshot.Interactions)),
+	}
+
+	for i, interaction := range snapshot.Interactions {
+		p.Interactions[i] = &persistedShareSnapshotInteraction{
+			ID:               interaction.ID,
+			OwnerType:        string(interaction.OwnerType),
+			Status:           string(interaction.Status),
+			MessageContent:   interaction.MessageContent,
+			ReasoningContent: interaction.ReasoningContent,
+
+			CreatedAt:   interaction.CreatedAt,
+			CompletedAt: interaction.CompletedAt,
+		}
+		p.Interactions[i].AIRelayOptions.ProviderID = interaction.AIRelayOptions.ProviderID
+		p.Interactions[i].AIRelayOptions.ModelID = interaction.AIRelayOptions.ModelID
+	}
+
+	return p
+}
+
+func (p *persistedShare) ToDomainModel() *models.Share {
+	share := &models.Share{
+		ID:             p.ID,
+		IdempotencyKey: p.IdempotencyKey,
+		ConversationID: p.ConversationID,
+		Owner: &models.Actor{
+			Type:       models.ActorType(p.Owner.Type),
+			Identifier: p.Owner.Identifier,
+		},
+		Token: p.Token,
+		Mode:  models.ShareMode(p.Mode),
+
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+		ExpiresAt: p.ExpiresAt,
+		RevokedAt: p.RevokedAt,
+	}
+
+	if p.Snapshot != nil {
+		share.Snapshot = &models.ShareSnapshot{
+			Title:        p.Snapshot.Title,
+			Interactions: make([]*models.ShareSnapshotInteraction, len(p.Snapshot.Interactions)),
+		}
+
+		for i, interaction := range p.Snapshot.Interactions {
+			share.Snapshot.Interactions[i] = &models.ShareSnapshotInteraction{
+				ID:        interaction.ID,
+				OwnerType: models.ActorType(interaction.OwnerType),
+				AIRelayOptions: &models.AIRelayOptions{
+					ProviderID: interaction.AIRelayOptions.ProviderID,
+					ModelID:    interaction.AIRelayOptions.ModelID,
+				},
+				Status:           models.InteractionStatus(interaction.Status),
+				MessageContent:   interaction.MessageContent,
+				ReasoningContent: interaction.ReasoningContent,
+
+				CreatedAt:   interaction.CreatedAt,
+				CompletedAt: interaction.CompletedAt,
+			}
+		}
+	}
+
+	return share
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+// Migrate backfills fields which documents written by older versions of the
+// service don't have. Each migration only touches documents which still need
+// it, so this is run on every startup, before EnsureIndexes.
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	if err := migrateIdempotencyKeys(ctx, db); err != nil {
+		return err
+	}
+
+	interactions := db.Collection("interactions")
+
+	// Interaction status used to be inferred from completed_at and errors
+	statuses := []struct {
+		filter bson.M
+		status models.InteractionStatus
+	}{
+		{bson.M{"completed_at": bson.M{"$ne": nil}}, models.InteractionStatusCompleted},
+		{bson.M{"errors.0": bson.M{"$exists": true}}, models.InteractionStatusFailed},
+		{bson.M{}, models.InteractionStatusPending},
+	}
+
+	for _, s := range statuses {
+		s.filter["status"] = bson.M{"$exists": false}
+
+		if _, err := interactions.UpdateMany(ctx, s.filter, bson.M{
+			"$set": bson.M{"status": s.status},
+		}); err != nil {
+			return fmt.Errorf("failed to backfill %s interaction statuses: %w", s.status, err)
+		}
+	}
+
+	return nil
+}
+
+// idempotencyKeyScopes are the fields, other than the key itself, which the
+// idempotency key indexes of each collection are unique within.
+var idempotencyKeyScopes = map[string]bson.M{
+	"conversations": {
+		"owner_type":       "$owner.type",
+		"owner_identifier": "$owner.identifier",
+	},
+	"interactions": {
+		"conversation_id":  "$conversation_id",
+		"owner_type":       "$owner.type",
+		"owner_identifier": "$owner.identifier",
+	},
+}
+
+// migrateIdempotencyKeys gets conversations and interactions ready for their
+// partial idempotency key indexes. The unique indexes used to cover every
+// document, so they're dropped to be recreated by EnsureIndexes. Documents which
+// were written with the same key before the indexes existed keep the key on the
+// earliest of them only, so retries still find the original.
+func migrateIdempotencyKeys(ctx context.Context, db *mongo.Database) error {
+	for collection, scope := range idempotencyKeyScopes {
+		c := db.Collection(collection)
+
+		if err := dropUnfilteredIdempotencyKeyIndex(ctx, c); err != nil {
+			return err
+		}
+
+		group := bson.M{"idempotency_key": "$idempotency_key"}
+		for field, value := range scope {
+			group[field] = value
+		}
+
+		cursor, err := c.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"idempotency_key": bson.M{"$type": "string"}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":   group,
+				"ids":   bson.M{"$push": "$_id"},
+				"count": bson.M{"$sum": 1},
+			}}},
+			{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to find duplicate %s idempotency keys: %w", collection, err)
+		}
+
+		var duplicates []struct {
+			IDs []any `bson:"ids"`
+		}
+		if err := cursor.All(ctx, &duplicates); err != nil {
+			return fmt.Errorf("failed to read duplicate %s idempotency keys: %w", collection, err)
+		}
+
+		for _, duplicate := range duplicates {
+			if _, err := c.UpdateMany(ctx, bson.M{
+				"_id": bson.M{"$in": duplicate.IDs[1:]},
+			}, bson.M{
+				"$unset": bson.M{"idempotency_key": ""},
+			}); err != nil {
+				return fmt.Errorf("failed to dedupe %s idempotency keys: %w", collection, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func dropUnfilteredIdempotencyKeyIndex(ctx context.Context, c *mongo.Collection) error {
+	cursor, err := c.Indexes().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list %s indexes: %w", c.Name(), err)
+	}
+
+	var indexes []struct {
+		Name                    string `bson:"name"`
+		PartialFilterExpression bson.M `bson:"partialFilterExpression"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return fmt.Errorf("failed to read %s indexes: %w", c.Name(), err)
+	}
+
+	for _, index := range indexes {
+		if index.Name != "idempotency_key" || index.PartialFilterExpression != nil {
+			continue
+		}
+
+		if _, err := c.Indexes().DropOne(ctx, index.Name); err != nil {
+			return fmt.Errorf("failed to drop %s idempotency key index: %w", c.Name(), err)
+		}
+	}
+
+	return nil
+}
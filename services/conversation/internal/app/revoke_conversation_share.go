@@ -0,0 +1,24 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) RevokeConversationShare(ctx context.Context, req *conversation.RevokeConversationShareRequest) error {
+	share, err := a.ShareRepository.GetByID(ctx, req.ShareID)
+	if err != nil {
+		return err
+	}
+	if share.Owner.Type != models.ActorType(req.Owner.Type) || share.Owner.Identifier != req.Owner.Identifier {
+		return cher.New("invalid_owner", cher.M{
+			"type":       req.Owner.Type,
+			"identifier": req.Owner.Identifier,
+		})
+	}
+
+	return a.ShareRepository.Revoke(ctx, share.ID)
+}
@@ -0,0 +1,278 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/skillset"
+	"github.com/0xdeafcafe/bloefish/services/stream"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+// jobRetryBackoff is how long a failed job waits before its first retry. It
+// doubles with each attempt.
+const jobRetryBackoff = 5 * time.Second
+
+var errJobLeaseLost = errors.New("job lease lost")
+
+// RunNextJob leases the next runnable job and runs it to completion, retrying
+// or failing it if it errors. It returns false if there was no job to run.
+func (a *App) RunNextJob(ctx context.Context, workerID string) (bool, error) {
+	job, err := a.JobRepository.Lease(ctx, workerID, a.JobLeaseDuration)
+	if err != nil {
+		return false, fmt.Errorf("failed to lease job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	ctx = clog.Set(ctx, clog.Get(ctx).
+		WithField("job_id", job.ID).
+		WithField("job_type", job.Type).
+		WithField("job_attempts", job.Attempts),
+	)
+
+	// A job leased more times than it's allowed attempts has had its worker
+	// die while running it, so it isn't safe to try again
+	if job.Attempts > job.MaxAttempts {
+		return true, a.failJob(ctx, job, workerID, cher.New("job_interrupted", cher.M{
+			"job_id":   job.ID,
+			"attempts": job.MaxAttempts,
+		}))
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go a.extendJobLease(jobCtx, cancel, job, workerID)
+
+	err = a.runJob(jobCtx, job)
+	switch {
+	case err == nil:
+		return true, a.JobRepository.Complete(ctx, job.ID, workerID)
+
+	case ctx.Err() != nil:
+		// The worker is shutting down, so hand the job back without using up
+		// an attempt
+		clog.Get(ctx).WithError(err).Info("releasing job as worker is stopping")
+
+		return true, a.JobRepository.Release(context.WithoutCancel(ctx), job.ID, workerID)
+
+	case errors.Is(context.Cause(jobCtx), errJobLeaseLost):
+		// Another worker has picked the job up, and owns it now
+		clog.Get(ctx).WithError(err).Warn("abandoned job after losing its lease")
+
+		return true, nil
+
+	case isRetryableJobError(err) && job.Attempts < job.MaxAttempts:
+		clog.Get(ctx).WithError(err).Warn("job failed, retrying")
+
+		runAfter := time.Now().Add(jobRetryBackoff << (job.Attempts - 1))
+		return true, a.JobRepository.Retry(ctx, job.ID, workerID, runAfter, cher.Coerce(err))
+	}
+
+	return true, a.failJob(ctx, job, workerID, cher.Coerce(err))
+}
+
+func (a *App) runJob(ctx context.Context, job *models.Job) error {
+	switch job.Type {
+	case models.JobTypeConversationMessageReply:
+		return a.runConversationMessageReplyJob(ctx, job)
+	case models.JobTypeConversationTitle:
+		return a.runConversationTitleJob(ctx, job)
+	}
+
+	return cher.New("unknown_job_type", cher.M{"type": job.Type})
+}
+
+func (a *App) runConversationMessageReplyJob(ctx context.Context, job *models.Job) error {
+	activeInteraction, err := a.InteractionRepository.GetByID(ctx, job.ActiveInteractionID)
+	if err != nil {
+		// Deleting the interaction cancelled the reply
+		if _, ok := cher.AsCherWithCode(err, "interaction_not_found"); ok {
+			return nil
+		}
+
+		return err
+	}
+
+	// The reply was saved by an earlier attempt which died before the job
+	// could be completed, or was cancelled
+	switch activeInteraction.Status {
+	case models.InteractionStatusCompleted, models.InteractionStatusCancelled:
+		return nil
+	}
+
+	convo, err := a.ConversationRepository.GetByID(ctx, job.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	interaction, err := a.InteractionRepository.GetByID(ctx, job.InteractionID)
+	if err != nil {
+		return err
+	}
+
+	skillSets, err := a.SkillSetService.GetManySkillSets(ctx, &skillset.GetManySkillSetsRequest{
+		SkillSetIDs: interaction.SkillSetIDs,
+		Owner: &skillset.Actor{
+			Type:       skillset.ActorType(interaction.Owner.Type),
+			Identifier: interaction.Owner.Identifier,
+		},
+		AllowDeleted: false,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := a.InteractionRepository.MarkActiveAsStreaming(ctx, activeInteraction.ID); err != nil {
+		return err
+	}
+
+	if err := a.resetJobStream(ctx, job); err != nil {
+		return err
+	}
+
+	return a.createConversationMessageReply(ctx, &createConversationMessageReplyCommand{
+		Conversation: convo,
+		Owner: &airelay.Actor{
+			Type:       airelay.ActorType(interaction.Owner.Type),
+			Identifier: interaction.Owner.Identifier,
+		},
+		SkillSets:          skillSets.SkillSets,
+		Interaction:        interaction,
+		ActiveInteraction:  activeInteraction,
+		StreamingChannelID: job.StreamingChannelID,
+		UseStreaming:       job.UseStreaming,
+	})
+}
+
+func (a *App) runConversationTitleJob(ctx context.Context, job *models.Job) error {
+	convo, err := a.ConversationRepository.GetByID(ctx, job.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	// The title was set by an earlier attempt, or by the owner
+	if convo.Title != nil {
+		return nil
+	}
+
+	interaction, err := a.InteractionRepository.GetByID(ctx, job.InteractionID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.resetJobStream(ctx, job); err != nil {
+		return err
+	}
+
+	_, err = a.generateConversationTitle(ctx, &generateConversationTitleCommand{
+		Conversation: convo,
+		Owner: &airelay.Actor{
+			Type:       airelay.ActorType(interaction.Owner.Type),
+			Identifier: interaction.Owner.Identifier,
+		},
+		Interaction:        interaction,
+		StreamingChannelID: job.StreamingChannelID,
+		UseStreaming:       job.UseStreaming,
+	})
+
+	return err
+}
+
+// resetJobStream discards whatever earlier runs of a job streamed, as each run
+// streams to the same channel from the start. Runs handed back by a stopping
+// worker don't use up an attempt, so the stream is reset on every run rather
+// than only on retries.
+func (a *App) resetJobStream(ctx context.Context, job *models.Job) error {
+	if !job.UseStreaming {
+		return nil
+	}
+
+	if err := a.StreamService.SendMessageReset(ctx, &stream.SendMessageResetRequest{
+		ChannelID: job.StreamingChannelID,
+	}); err != nil {
+		return fmt.Errorf("failed to reset job stream: %w", err)
+	}
+
+	return nil
+}
+
+// extendJobLease keeps the job leased while it runs, as replies can take
+// longer than a single lease. If the lease is lost the job is cancelled.
+func (a *App) extendJobLease(ctx context.Context, cancel context.CancelCauseFunc, job *models.Job, workerID string) {
+	ticker := time.NewTicker(a.JobLeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.JobRepository.ExtendLease(ctx, job.ID, workerID, a.JobLeaseDuration); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				clog.Get(ctx).WithError(err).Error("failed to extend job lease")
+				cancel(errJobLeaseLost)
+
+				return
+			}
+		}
+	}
+}
+
+// failJob marks the job as failed, and lets anyone waiting on it know.
+func (a *App) failJob(ctx context.Context, job *models.Job, workerID string, e cher.E) error {
+	clog.Get(ctx).WithError(e).Error("job failed")
+
+	if err := a.JobRepository.Fail(ctx, job.ID, workerID, e); err != nil {
+		return err
+	}
+
+	if sendErrorErr := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
+		ChannelID: job.StreamingChannelID,
+		Error:     e,
+	}); sendErrorErr != nil {
+		clog.Get(ctx).WithError(sendErrorErr).Error("failed to send error message to stream service")
+	}
+
+	if job.Type == models.JobTypeConversationMessageReply {
+		if saveErrorErr := a.InteractionRepository.MarkActiveAsFailed(ctx, job.ActiveInteractionID, e); saveErrorErr != nil {
+			clog.Get(ctx).WithError(saveErrorErr).Error("failed to save error to interaction")
+		}
+	}
+
+	return nil
+}
+
+// isRetryableJobError reports whether a job which failed with err might
+// succeed if run again. Errors raised on purpose are final, anything else
+// (timeouts, network and database errors) is assumed to be transient.
+func isRetryableJobError(err error) bool {
+	var cErr cher.E
+	if !errors.As(err, &cErr) {
+		return true
+	}
+
+	switch cErr.Code {
+	case cher.Unknown,
+		cher.TooManyRequests,
+		cher.RequestTimeout,
+		cher.ThirdPartyTimeout,
+		cher.ContextCanceled,
+		cher.EOF,
+		cher.UnexpectedEOF:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/stream"
+)
+
+// orphanedInteractionGracePeriod gives requests which have created an active
+// interaction time to queue its reply, before the interaction is considered
+// orphaned.
+const orphanedInteractionGracePeriod = time.Minute
+
+// SweepOrphanedInteractions fails active interactions which will never be
+// completed, because no job is queued or running to generate their reply. This
+// happens when the service stops between creating the interaction and queueing
+// its reply, and to replies which were generated before jobs were persisted.
+func (a *App) SweepOrphanedInteractions(ctx context.Context) error {
+	interactions, err := a.InteractionRepository.ListIncompleteActive(ctx, time.Now().Add(-orphanedInteractionGracePeriod))
+	if err != nil {
+		return fmt.Errorf("failed to list incomplete interactions: %w", err)
+	}
+
+	for _, interaction := range interactions {
+		pending, err := a.JobRepository.HasPendingForActiveInteraction(ctx, interaction.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check for pending jobs: %w", err)
+		}
+		if pending {
+			continue
+		}
+
+		clog.Get(ctx).WithField("interaction_id", interaction.ID).Warn("failing orphaned interaction")
+
+		e := cher.New("reply_interrupted", cher.M{"interaction_id": interaction.ID})
+		if err := a.InteractionRepository.MarkActiveAsFailed(ctx, interaction.ID, e); err != nil {
+			return fmt.Errorf("failed to save error to interaction: %w", err)
+		}
+
+		if err := a.StreamService.SendErrorMessage(ctx, &stream.SendErrorMessageRequest{
+			ChannelID: fmt.Sprintf("%s/%s", interaction.ConversationID, interaction.ID),
+			Error:     e,
+		}); err != nil {
+			clog.Get(ctx).WithError(err).Error("failed to send error message to stream service")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) UpdateConversationArchivedState(ctx context.Context, req *conversation.UpdateConversationArchivedStateRequest) error {
+	if err := a.ConversationRepository.UpdateArchivedState(ctx, req.ConversationID, req.Archived); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) UpdateConversationFolder(ctx context.Context, req *conversation.UpdateConversationFolderRequest) error {
+	convo, err := a.ConversationRepository.GetByID(ctx, req.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	if req.FolderID != nil {
+		folder, err := a.FolderRepository.GetByID(ctx, *req.FolderID)
+		if err != nil {
+			return err
+		}
+
+		// Conversations can only be filed away in their owner's folders
+		if folder.Owner.Type != convo.Owner.Type || folder.Owner.Identifier != convo.Owner.Identifier {
+			return cher.New("folder_not_found", cher.M{"folder_id": folder.ID})
+		}
+	}
+
+	return a.ConversationRepository.UpdateFolder(ctx, convo.ID, req.FolderID)
+}
@@ -0,0 +1,15 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) UpdateConversationPinnedState(ctx context.Context, req *conversation.UpdateConversationPinnedStateRequest) error {
+	if err := a.ConversationRepository.UpdatePinnedState(ctx, req.ConversationID, req.Pinned); err != nil {
+		return err
+	}
+
+	return nil
+}
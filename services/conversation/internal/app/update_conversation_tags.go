@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) UpdateConversationTags(ctx context.Context, req *conversation.UpdateConversationTagsRequest) (*conversation.UpdateConversationTagsResponse, error) {
+	convo, err := a.ConversationRepository.GetByID(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := sanitizeConversationTags(req.Tags)
+
+	if err := a.ConversationRepository.UpdateTags(ctx, convo.ID, tags); err != nil {
+		return nil, err
+	}
+
+	return &conversation.UpdateConversationTagsResponse{
+		ConversationID: convo.ID,
+		Tags:           tags,
+	}, nil
+}
+
+// sanitizeConversationTags trims and lowercases tags so they match however
+// they were typed, dropping any which are empty or repeated.
+func sanitizeConversationTags(tags []string) []string {
+	sanitized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		sanitized = append(sanitized, tag)
+	}
+
+	return sanitized
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+)
+
+func (a *App) UpdateConversationTitle(ctx context.Context, req *conversation.UpdateConversationTitleRequest) (*conversation.UpdateConversationTitleResponse, error) {
+	convo, err := a.ConversationRepository.GetByID(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		title := sanitizeConversationTitle(*req.Title)
+		if title == "" {
+			return nil, cher.New("invalid_title", cher.M{"conversation_id": convo.ID})
+		}
+
+		if err := a.ConversationRepository.UpdateTitle(ctx, convo.ID, title); err != nil {
+			return nil, err
+		}
+
+		return &conversation.UpdateConversationTitleResponse{
+			ConversationID: convo.ID,
+			Title:          title,
+		}, nil
+	}
+
+	interactions, err := a.InteractionRepository.GetAllByConversationID(ctx, convo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Titles are generated from the first message the owner sent, the same as
+	// when the conversation was started.
+	var firstInteraction *models.Interaction
+	for _, interaction := range interactions {
+		if interaction.Owner.Type == convo.Owner.Type && interaction.Owner.Identifier == convo.Owner.Identifier {
+			firstInteraction = interaction
+			break
+		}
+	}
+	if firstInteraction == nil {
+		return nil, cher.New("conversation_has_no_messages", cher.M{"conversation_id": convo.ID})
+	}
+
+	title, err := a.generateConversationTitle(ctx, &generateConversationTitleCommand{
+		Conversation: convo,
+		Owner: &airelay.Actor{
+			Type:       airelay.ActorType(convo.Owner.Type),
+			Identifier: convo.Owner.Identifier,
+		},
+		Interaction:        firstInteraction,
+		StreamingChannelID: fmt.Sprintf("%s/title", convo.ID),
+		UseStreaming:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation.UpdateConversationTitleResponse{
+		ConversationID: convo.ID,
+		Title:          title,
+	}, nil
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (a *App) UpdateFolderName(ctx context.Context, req *conversation.UpdateFolderNameRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return cher.New("invalid_name", cher.M{"folder_id": req.FolderID})
+	}
+
+	folder, err := a.FolderRepository.GetByID(ctx, req.FolderID)
+	if err != nil {
+		return err
+	}
+
+	return a.FolderRepository.UpdateName(ctx, folder.ID, name)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/airelay"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/user"
+)
+
+// getUserPreferences loads the owner's preferences. Failing to load them isn't
+// fatal, callers fall back to whatever they'd use without them.
+func (a *App) getUserPreferences(ctx context.Context, owner *airelay.Actor) *user.GetUserPreferencesResponse {
+	if owner == nil || owner.Type != airelay.ActorTypeUser {
+		return nil
+	}
+
+	resp, err := a.UserService.GetUserPreferences(ctx, &user.GetUserPreferencesRequest{
+		UserID: owner.Identifier,
+	})
+	if err != nil {
+		clog.Get(ctx).WithError(err).Warn("failed to get user preferences")
+
+		return nil
+	}
+
+	return resp
+}
+
+// getDefaultAIRelayOptions returns the owner's default model, if they have one.
+func (a *App) getDefaultAIRelayOptions(ctx context.Context, owner *airelay.Actor) *models.AIRelayOptions {
+	preferences := a.getUserPreferences(ctx, owner)
+	if preferences == nil || preferences.Preferences.DefaultAIRelayOptions == nil {
+		return nil
+	}
+
+	return &models.AIRelayOptions{
+		ProviderID: preferences.Preferences.DefaultAIRelayOptions.ProviderID,
+		ModelID:    preferences.Preferences.DefaultAIRelayOptions.ModelID,
+	}
+}
+
+// getTitleGenerationAIRelayOptions returns the owner's preferred title generation
+// model, if they have one.
+func (a *App) getTitleGenerationAIRelayOptions(ctx context.Context, owner *airelay.Actor) *models.AIRelayOptions {
+	preferences := a.getUserPreferences(ctx, owner)
+	if preferences == nil || preferences.Preferences.TitleGenerationAIRelayOptions == nil {
+		return nil
+	}
+
+	return &models.AIRelayOptions{
+		ProviderID: preferences.Preferences.TitleGenerationAIRelayOptions.ProviderID,
+		ModelID:    preferences.Preferences.TitleGenerationAIRelayOptions.ModelID,
+	}
+}
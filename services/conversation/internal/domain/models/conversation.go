@@ -10,6 +10,11 @@ type Conversation struct {
 
 	Title *string `json:"title"`
 
+	FolderID   *string    `json:"folder_id"`
+	Tags       []string   `json:"tags"`
+	PinnedAt   *time.Time `json:"pinned_at"`
+	ArchivedAt *time.Time `json:"archived_at"`
+
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
@@ -30,3 +35,17 @@ type CreateConversationCommandAIRelayOptions struct {
 	ProviderID string
 	ModelID    string
 }
+
+// ConversationFilter narrows down the conversations listed for an owner.
+type ConversationFilter struct {
+	// FolderID only lists conversations in the folder. When nil, conversations
+	// in any folder or none are listed.
+	FolderID *string
+	// Tags only lists conversations which have every one of the tags.
+	Tags []string
+	// Pinned only lists pinned or unpinned conversations. When nil, both are
+	// listed.
+	Pinned *bool
+	// Archived lists archived conversations instead of unarchived ones.
+	Archived bool
+}
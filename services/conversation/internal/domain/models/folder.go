@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Folder struct {
+	ID             string `json:"id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Owner          *Actor `json:"owner"`
+	Name           string `json:"name"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type CreateFolderCommand struct {
+	IdempotencyKey string
+	Owner          *Actor
+	Name           string
+}
@@ -6,6 +6,35 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
 )
 
+type InteractionStatus string
+
+const (
+	// InteractionStatusPending is a reply which is waiting for a worker to
+	// start generating it.
+	InteractionStatusPending InteractionStatus = "pending"
+	// InteractionStatusStreaming is a reply which is being generated.
+	InteractionStatusStreaming InteractionStatus = "streaming"
+	// InteractionStatusCompleted is a reply which has been generated, or a
+	// message sent by the owner.
+	InteractionStatusCompleted InteractionStatus = "completed"
+	// InteractionStatusFailed is a reply which could not be generated, its
+	// errors say why.
+	InteractionStatusFailed InteractionStatus = "failed"
+	// InteractionStatusCancelled is a reply which was deleted before it was
+	// generated.
+	InteractionStatusCancelled InteractionStatus = "cancelled"
+)
+
+// InteractionStatusTransitions lists the statuses an interaction can move to a
+// status from. Streaming can be restarted, as failed attempts at generating a
+// reply are retried.
+var InteractionStatusTransitions = map[InteractionStatus][]InteractionStatus{
+	InteractionStatusStreaming: {InteractionStatusPending, InteractionStatusStreaming},
+	InteractionStatusCompleted: {InteractionStatusStreaming},
+	InteractionStatusFailed:    {InteractionStatusPending, InteractionStatusStreaming},
+	InteractionStatusCancelled: {InteractionStatusPending, InteractionStatusStreaming},
+}
+
 type Interaction struct {
 	ID             string   `json:"id"`
 	IdempotencyKey string   `json:"idempotency_key"`
@@ -15,8 +44,10 @@ type Interaction struct {
 
 	MarkedAsExcludedAt *time.Time `json:"marked_as_excluded_at"`
 
-	MessageContent string   `json:"message_content"`
-	Errors         []cher.E `json:"errors"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+	Errors           []cher.E          `json:"errors"`
 
 	Owner          *Actor          `json:"owner"`
 	AIRelayOptions *AIRelayOptions `json:"ai_relay_options"`
@@ -25,6 +56,11 @@ type Interaction struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
 	CompletedAt *time.Time `json:"completed_at"`
+
+	// StartedAt is when a worker last started generating the reply, and
+	// FirstTokenAt when the model produced the first token of the reply.
+	StartedAt    *time.Time `json:"started_at"`
+	FirstTokenAt *time.Time `json:"first_token_at"`
 }
 
 type CreateInteractionCommand struct {
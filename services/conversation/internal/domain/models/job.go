@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+)
+
+type JobType string
+
+const (
+	JobTypeConversationMessageReply JobType = "conversation_message_reply"
+	JobTypeConversationTitle        JobType = "conversation_title"
+)
+
+type Job struct {
+	ID             string  `json:"id"`
+	IdempotencyKey string  `json:"idempotency_key"`
+	Type           JobType `json:"type"`
+
+	ConversationID      string `json:"conversation_id"`
+	InteractionID       string `json:"interaction_id"`
+	ActiveInteractionID string `json:"active_interaction_id"`
+	StreamingChannelID  string `json:"streaming_channel_id"`
+	UseStreaming        bool   `json:"use_streaming"`
+
+	Attempts    int     `json:"attempts"`
+	MaxAttempts int     `json:"max_attempts"`
+	LastError   *cher.E `json:"last_error"`
+
+	RunAfter    time.Time  `json:"run_after"`
+	LeasedBy    *string    `json:"leased_by"`
+	LeasedUntil *time.Time `json:"leased_until"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	FailedAt    *time.Time `json:"failed_at"`
+}
+
+type EnqueueJobCommand struct {
+	IdempotencyKey string
+	Type           JobType
+
+	ConversationID      string
+	InteractionID       string
+	ActiveInteractionID string
+	StreamingChannelID  string
+	UseStreaming        bool
+
+	MaxAttempts int
+}
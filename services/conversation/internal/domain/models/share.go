@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type ShareMode string
+
+const (
+	// ShareModeSnapshot shares the conversation as it was when the share was
+	// created.
+	ShareModeSnapshot ShareMode = "snapshot"
+	// ShareModeLive shares the conversation as it is when the share is viewed,
+	// including messages sent after the share was created.
+	ShareModeLive ShareMode = "live"
+)
+
+type Share struct {
+	ID             string    `json:"id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	ConversationID string    `json:"conversation_id"`
+	Owner          *Actor    `json:"owner"`
+	Token          string    `json:"token"`
+	Mode           ShareMode `json:"mode"`
+
+	// Snapshot is only set for snapshot shares.
+	Snapshot *ShareSnapshot `json:"snapshot"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// ShareSnapshot is a copy of the parts of a conversation which are shown to
+// whoever the share is shared with.
+type ShareSnapshot struct {
+	Title        *string                     `json:"title"`
+	Interactions []*ShareSnapshotInteraction `json:"interactions"`
+}
+
+type ShareSnapshotInteraction struct {
+	ID               string            `json:"id"`
+	OwnerType        ActorType         `json:"owner_type"`
+	AIRelayOptions   *AIRelayOptions   `json:"ai_relay_options"`
+	Status           InteractionStatus `json:"status"`
+	MessageContent   string            `json:"message_content"`
+	ReasoningContent string            `json:"reasoning_content"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+type CreateShareCommand struct {
+	IdempotencyKey string
+	ConversationID string
+	Owner          *Actor
+	Mode           ShareMode
+	Snapshot       *ShareSnapshot
+	ExpiresAt      *time.Time
+}
@@ -2,28 +2,97 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
 )
 
 type ConversationRepository interface {
-	Create(ctx context.Context, cmd *models.CreateConversationCommand) (*models.Conversation, error)
+	// Create is idempotent, if a conversation already exists with the
+	// command's idempotency key it is returned unchanged and the returned bool
+	// is false.
+	Create(ctx context.Context, cmd *models.CreateConversationCommand) (*models.Conversation, bool, error)
 	GetByID(ctx context.Context, conversationID string) (*models.Conversation, error)
-	ListByOwner(ctx context.Context, actor models.Actor) ([]*models.Conversation, error)
+	// ListByOwner lists pinned conversations first. A nil filter lists every
+	// unarchived conversation.
+	ListByOwner(ctx context.Context, actor models.Actor, filter *models.ConversationFilter) ([]*models.Conversation, error)
 	DeleteMany(ctx context.Context, conversationIDs []string) error
 	UpdateTitle(ctx context.Context, conversationID, title string) error
+	// UpdateFolder moves a conversation into a folder, or out of any folder
+	// when folderID is nil.
+	UpdateFolder(ctx context.Context, conversationID string, folderID *string) error
+	RemoveAllFromFolder(ctx context.Context, folderID string) error
+	UpdateTags(ctx context.Context, conversationID string, tags []string) error
+	UpdatePinnedState(ctx context.Context, conversationID string, pinned bool) error
+	UpdateArchivedState(ctx context.Context, conversationID string, archived bool) error
+}
+
+type FolderRepository interface {
+	// Create is idempotent, if a folder already exists with the command's
+	// idempotency key it is returned unchanged.
+	Create(ctx context.Context, cmd *models.CreateFolderCommand) (*models.Folder, error)
+	GetByID(ctx context.Context, folderID string) (*models.Folder, error)
+	ListByOwner(ctx context.Context, actor models.Actor) ([]*models.Folder, error)
+	UpdateName(ctx context.Context, folderID, name string) error
+	Delete(ctx context.Context, folderID string) error
 }
 
 type InteractionRepository interface {
-	Create(ctx context.Context, cmd *models.CreateInteractionCommand) (*models.Interaction, error)
-	CreateActive(ctx context.Context, cmd *models.CreateActiveInteractionCommand) (*models.Interaction, error)
-	MarkActiveAsComplete(ctx context.Context, interactionID, messageContent string) error
+	// Create and CreateActive are idempotent, if an interaction already exists
+	// with the command's idempotency key it is returned unchanged and the
+	// returned bool is false.
+	Create(ctx context.Context, cmd *models.CreateInteractionCommand) (*models.Interaction, bool, error)
+	CreateActive(ctx context.Context, cmd *models.CreateActiveInteractionCommand) (*models.Interaction, bool, error)
+	// MarkActiveAsStreaming, MarkActiveAsComplete and MarkActiveAsFailed
+	// return an invalid_interaction_status_transition error if the
+	// interaction can't move to the new status from its current one.
+	MarkActiveAsStreaming(ctx context.Context, interactionID string) error
+	MarkActiveAsComplete(ctx context.Context, interactionID, messageContent, reasoningContent string, firstTokenAt *time.Time) error
+	MarkActiveAsFailed(ctx context.Context, interactionID string, e cher.E) error
 	GetByID(ctx context.Context, interactionID string) (*models.Interaction, error)
 	GetAllByConversationID(ctx context.Context, conversationID string) ([]*models.Interaction, error)
+	// DeleteManyByConversationID and DeleteMany cancel any replies which
+	// haven't finished.
 	DeleteManyByConversationID(ctx context.Context, conversationID string) error
 	DeleteMany(ctx context.Context, interactionIDs []string) error
 	ConversationHasInteractions(ctx context.Context, conversationID string) (bool, error)
 	UpdateExcludedState(ctx context.Context, interactionID string, excluded bool) error
-	AddError(ctx context.Context, interactionID string, e cher.E) error
+	// ListIncompleteActive returns pending and streaming interactions created
+	// before createdBefore.
+	ListIncompleteActive(ctx context.Context, createdBefore time.Time) ([]*models.Interaction, error)
+}
+
+type ShareRepository interface {
+	// Create is idempotent, if a share already exists with the command's
+	// idempotency key it is returned unchanged. Each share is given a new
+	// random token.
+	Create(ctx context.Context, cmd *models.CreateShareCommand) (*models.Share, error)
+	// GetByID and GetByToken return revoked and expired shares, it's up to
+	// the caller to check them.
+	GetByID(ctx context.Context, shareID string) (*models.Share, error)
+	GetByToken(ctx context.Context, token string) (*models.Share, error)
+	ListByConversationID(ctx context.Context, conversationID string) ([]*models.Share, error)
+	// Revoke is a no-op if the share has already been revoked.
+	Revoke(ctx context.Context, shareID string) error
+}
+
+type JobRepository interface {
+	// Enqueue is idempotent, if a job already exists with the command's
+	// idempotency key it is returned unchanged.
+	Enqueue(ctx context.Context, cmd *models.EnqueueJobCommand) (*models.Job, error)
+	// Lease claims the next runnable job for workerID until the lease expires,
+	// incrementing its attempts. Jobs whose lease has expired are runnable
+	// again. A nil job is returned when there is nothing to run.
+	Lease(ctx context.Context, workerID string, leaseDuration time.Duration) (*models.Job, error)
+	// ExtendLease, Complete, Retry, Fail and Release return a job_lease_lost
+	// error if workerID no longer holds the job's lease.
+	ExtendLease(ctx context.Context, jobID, workerID string, leaseDuration time.Duration) error
+	Complete(ctx context.Context, jobID, workerID string) error
+	Retry(ctx context.Context, jobID, workerID string, runAfter time.Time, e cher.E) error
+	Fail(ctx context.Context, jobID, workerID string, e cher.E) error
+	// Release gives up the lease without counting it as an attempt, so the job
+	// is run again straight away.
+	Release(ctx context.Context, jobID, workerID string) error
+	HasPendingForActiveInteraction(ctx context.Context, interactionID string) (bool, error)
 }
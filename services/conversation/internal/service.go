@@ -2,6 +2,10 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/libraries/config"
@@ -9,7 +13,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/services/airelay"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/app"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/app/repositories"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/domain/models"
 	"github.com/0xdeafcafe/bloefish/services/conversation/internal/transport/rpc"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/transport/worker"
 	"github.com/0xdeafcafe/bloefish/services/skillset"
 	"github.com/0xdeafcafe/bloefish/services/stream"
 	"github.com/0xdeafcafe/bloefish/services/user"
@@ -25,6 +31,46 @@ type Config struct {
 	SkillSetService config.UnauthenticatedService `env:"SKILL_SET_SERVICE"`
 	StreamService   config.UnauthenticatedService `env:"STREAM_SERVICE"`
 	UserService     config.UnauthenticatedService `env:"USER_SERVICE"`
+
+	TitleGeneration TitleGenerationConfig `env:"TITLE_GENERATION"`
+	Jobs            JobsConfig            `env:"JOBS"`
+}
+
+// TitleGenerationConfig configures how conversation titles are generated. The
+// provider and model are optional, and should both be set to use a separate
+// (typically cheaper) model for titles.
+type TitleGenerationConfig struct {
+	ProviderID     string `env:"PROVIDER_ID"`
+	ModelID        string `env:"MODEL_ID"`
+	PromptTemplate string `env:"PROMPT_TEMPLATE"`
+}
+
+// JobsConfig configures the workers which generate replies and titles in the
+// background. Jobs are stored in MongoDB, so they survive restarts and are
+// shared between instances.
+type JobsConfig struct {
+	Workers                  int `env:"WORKERS"`
+	MaxAttempts              int `env:"MAX_ATTEMPTS"`
+	LeaseSeconds             int `env:"LEASE_SECONDS"`
+	PollIntervalMilliseconds int `env:"POLL_INTERVAL_MILLISECONDS"`
+	SweepIntervalSeconds     int `env:"SWEEP_INTERVAL_SECONDS"`
+}
+
+func (c JobsConfig) validate() error {
+	switch {
+	case c.Workers < 1:
+		return errors.New("jobs need at least one worker")
+	case c.MaxAttempts < 1:
+		return errors.New("jobs need at least one attempt")
+	case c.LeaseSeconds < 1:
+		return errors.New("jobs need a lease of at least one second")
+	case c.PollIntervalMilliseconds < 1:
+		return errors.New("jobs need a poll interval of at least one millisecond")
+	case c.SweepIntervalSeconds < 1:
+		return errors.New("jobs need a sweep interval of at least one second")
+	}
+
+	return nil
 }
 
 func defaultConfig() Config {
@@ -59,6 +105,18 @@ func defaultConfig() Config {
 		UserService: config.UnauthenticatedService{
 			BaseURL: "http://localhost:4001/rpc",
 		},
+
+		TitleGeneration: TitleGenerationConfig{
+			PromptTemplate: app.DefaultTitlePromptTemplate,
+		},
+
+		Jobs: JobsConfig{
+			Workers:                  4,
+			MaxAttempts:              3,
+			LeaseSeconds:             60,
+			PollIntervalMilliseconds: 500,
+			SweepIntervalSeconds:     300,
+		},
 	}
 }
 
@@ -66,6 +124,10 @@ func Run(ctx context.Context) error {
 	cfg := defaultConfig()
 	config.MustHydrate(ctx, &cfg)
 
+	if err := cfg.Jobs.validate(); err != nil {
+		return err
+	}
+
 	shutdown := cfg.Telemetry.MustSetup(ctx)
 	defer func() {
 		if err := shutdown(ctx); err != nil {
@@ -76,17 +138,66 @@ func Run(ctx context.Context) error {
 	ctx = clog.Set(ctx, cfg.Logging.Configure(ctx))
 	_, mongoDatabase := cfg.Mongo.MustConnect(ctx)
 
+	if err := repositories.Migrate(ctx, mongoDatabase); err != nil {
+		return err
+	}
+	if err := repositories.EnsureIndexes(ctx, mongoDatabase); err != nil {
+		return err
+	}
+
+	titlePromptTemplate, err := template.New("title_prompt").Parse(cfg.TitleGeneration.PromptTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse title prompt template: %w", err)
+	}
+
+	var titleAIRelayOptions *models.AIRelayOptions
+	if cfg.TitleGeneration.ProviderID != "" && cfg.TitleGeneration.ModelID != "" {
+		titleAIRelayOptions = &models.AIRelayOptions{
+			ProviderID: cfg.TitleGeneration.ProviderID,
+			ModelID:    cfg.TitleGeneration.ModelID,
+		}
+	}
+
 	app := &app.App{
 		ConversationRepository: repositories.NewMgoConversation(mongoDatabase),
+		FolderRepository:       repositories.NewMgoFolder(mongoDatabase),
 		InteractionRepository:  repositories.NewMgoInteraction(mongoDatabase),
+		JobRepository:          repositories.NewMgoJob(mongoDatabase),
+		ShareRepository:        repositories.NewMgoShare(mongoDatabase),
 
 		AIRelayService:  airelay.NewRPCClient(ctx, cfg.AIRelayService),
 		SkillSetService: skillset.NewRPCClient(ctx, cfg.SkillSetService),
 		StreamService:   stream.NewRPCClient(ctx, cfg.StreamService),
 		UserService:     user.NewRPCClient(ctx, cfg.UserService),
+
+		TitleAIRelayOptions: titleAIRelayOptions,
+		TitlePromptTemplate: titlePromptTemplate,
+
+		JobMaxAttempts:   cfg.Jobs.MaxAttempts,
+		JobLeaseDuration: time.Duration(cfg.Jobs.LeaseSeconds) * time.Second,
 	}
 
 	rpc := rpc.New(ctx, app)
+	worker := worker.New(ctx, app,
+		cfg.Jobs.Workers,
+		time.Duration(cfg.Jobs.PollIntervalMilliseconds)*time.Millisecond,
+		time.Duration(cfg.Jobs.SweepIntervalSeconds)*time.Second,
+	)
+
+	// Workers are stopped once the server has shut down, and waited on so
+	// running jobs are handed back rather than left leased
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+
+		worker.Run(workerCtx)
+	}()
+
+	err = rpc.Run(ctx, cfg.Server)
+
+	stopWorker()
+	<-workerDone
 
-	return rpc.Run(ctx, cfg.Server)
+	return err
 }
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) CreateConversationShare(ctx context.Context, req *conversation.CreateConversationShareRequest) (*conversation.CreateConversationShareResponse, error) {
+	return r.app.CreateConversationShare(ctx, req)
+}
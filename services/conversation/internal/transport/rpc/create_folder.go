@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) CreateFolder(ctx context.Context, req *conversation.CreateFolderRequest) (*conversation.CreateFolderResponse, error) {
+	return r.app.CreateFolder(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) DeleteFolder(ctx context.Context, req *conversation.DeleteFolderRequest) error {
+	return r.app.DeleteFolder(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) GetSharedConversation(ctx context.Context, req *conversation.GetSharedConversationRequest) (*conversation.GetSharedConversationResponse, error) {
+	return r.app.GetSharedConversation(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) ListConversationShares(ctx context.Context, req *conversation.ListConversationSharesRequest) (*conversation.ListConversationSharesResponse, error) {
+	return r.app.ListConversationShares(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) ListFolders(ctx context.Context, req *conversation.ListFoldersRequest) (*conversation.ListFoldersResponse, error) {
+	return r.app.ListFolders(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) RevokeConversationShare(ctx context.Context, req *conversation.RevokeConversationShareRequest) error {
+	return r.app.RevokeConversationShare(ctx, req)
+}
@@ -43,6 +43,7 @@ func New(ctx context.Context, app *app.App) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("create_conversation", "2025-02-12", schema("create_conversation"), rpc.CreateConversation)
@@ -53,6 +54,19 @@ func New(ctx context.Context, app *app.App) *RPC {
 	svr.Register("delete_conversations", "2025-02-12", schema("delete_conversations"), rpc.DeleteConversations)
 	svr.Register("delete_interactions", "2025-02-12", schema("delete_interactions"), rpc.DeleteInteractions)
 	svr.Register("update_interaction_excluded_state", "2025-02-12", schema("update_interaction_excluded_state"), rpc.UpdateInteractionExcludedState)
+	svr.Register("update_conversation_title", "2025-02-12", schema("update_conversation_title"), rpc.UpdateConversationTitle)
+	svr.Register("update_conversation_folder", "2025-02-12", schema("update_conversation_folder"), rpc.UpdateConversationFolder)
+	svr.Register("update_conversation_tags", "2025-02-12", schema("update_conversation_tags"), rpc.UpdateConversationTags)
+	svr.Register("update_conversation_pinned_state", "2025-02-12", schema("update_conversation_pinned_state"), rpc.UpdateConversationPinnedState)
+	svr.Register("update_conversation_archived_state", "2025-02-12", schema("update_conversation_archived_state"), rpc.UpdateConversationArchivedState)
+	svr.Register("create_folder", "2025-02-12", schema("create_folder"), rpc.CreateFolder)
+	svr.Register("list_folders", "2025-02-12", schema("list_folders"), rpc.ListFolders)
+	svr.Register("update_folder_name", "2025-02-12", schema("update_folder_name"), rpc.UpdateFolderName)
+	svr.Register("delete_folder", "2025-02-12", schema("delete_folder"), rpc.DeleteFolder)
+	svr.Register("create_conversation_share", "2025-02-12", schema("create_conversation_share"), rpc.CreateConversationShare)
+	svr.Register("list_conversation_shares", "2025-02-12", schema("list_conversation_shares"), rpc.ListConversationShares)
+	svr.Register("revoke_conversation_share", "2025-02-12", schema("revoke_conversation_share"), rpc.RevokeConversationShare)
+	svr.Register("get_shared_conversation", "2025-02-12", schema("get_shared_conversation"), rpc.GetSharedConversation)
 
 	mux := chi.NewRouter()
 	mux.Use(version.HeaderMiddleware(svcInfo.ServiceHTTPName))
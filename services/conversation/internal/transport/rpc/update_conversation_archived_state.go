@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateConversationArchivedState(ctx context.Context, req *conversation.UpdateConversationArchivedStateRequest) error {
+	return r.app.UpdateConversationArchivedState(ctx, req)
+}
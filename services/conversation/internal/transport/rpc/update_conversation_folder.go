@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateConversationFolder(ctx context.Context, req *conversation.UpdateConversationFolderRequest) error {
+	return r.app.UpdateConversationFolder(ctx, req)
+}
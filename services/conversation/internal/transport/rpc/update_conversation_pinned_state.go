@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateConversationPinnedState(ctx context.Context, req *conversation.UpdateConversationPinnedStateRequest) error {
+	return r.app.UpdateConversationPinnedState(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateConversationTags(ctx context.Context, req *conversation.UpdateConversationTagsRequest) (*conversation.UpdateConversationTagsResponse, error) {
+	return r.app.UpdateConversationTags(ctx, req)
+}
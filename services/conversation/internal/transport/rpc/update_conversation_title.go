@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateConversationTitle(ctx context.Context, req *conversation.UpdateConversationTitleRequest) (*conversation.UpdateConversationTitleResponse, error) {
+	return r.app.UpdateConversationTitle(ctx, req)
+}
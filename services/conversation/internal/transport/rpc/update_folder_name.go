@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/conversation"
+)
+
+func (r *RPC) UpdateFolderName(ctx context.Context, req *conversation.UpdateFolderNameRequest) error {
+	return r.app.UpdateFolderName(ctx, req)
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/services/conversation/internal/app"
+)
+
+// Worker runs queued reply and title jobs in the background.
+type Worker struct {
+	app *app.App
+
+	concurrency   int
+	pollInterval  time.Duration
+	sweepInterval time.Duration
+}
+
+func New(ctx context.Context, app *app.App, concurrency int, pollInterval, sweepInterval time.Duration) *Worker {
+	return &Worker{
+		app: app,
+
+		concurrency:   concurrency,
+		pollInterval:  pollInterval,
+		sweepInterval: sweepInterval,
+	}
+}
+
+// Run runs jobs, and sweeps orphaned interactions every sweep interval, until
+// the context is cancelled. Jobs which are running when the context is
+// cancelled are handed back to the queue before Run returns.
+func (w *Worker) Run(ctx context.Context) {
+	clog.Get(ctx).WithField("concurrency", w.concurrency).Info("running job workers")
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		w.sweep(ctx)
+	}()
+
+	for range w.concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w.poll(ctx, ksuid.Generate(ctx, "worker").String())
+		}()
+	}
+
+	wg.Wait()
+}
+
+// sweep fails orphaned interactions straight away, then again every sweep
+// interval. Interactions are orphaned when an instance stops part way through
+// creating them, which can happen while this one is running.
+func (w *Worker) sweep(ctx context.Context) {
+	ticker := time.NewTicker(w.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.app.SweepOrphanedInteractions(ctx); err != nil && ctx.Err() == nil {
+			clog.Get(ctx).WithError(err).Error("failed to sweep orphaned interactions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) poll(ctx context.Context, workerID string) {
+	for ctx.Err() == nil {
+		ran, err := w.app.RunNextJob(ctx, workerID)
+		if err != nil {
+			clog.Get(ctx).WithError(err).Error("failed to run job")
+		}
+
+		// Keep going while there is work, only waiting once the queue is empty
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
@@ -1,3 +1,5 @@
+// Code generated by crpcgen. DO NOT EDIT.
+
 package conversation
 
 import (
@@ -7,6 +9,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/crpc"
 )
 
+// Ensure RPCClient implements Service.
+var _ Service = (*RPCClient)(nil)
+
 type RPCClient struct {
 	client *crpc.Client
 }
@@ -48,3 +53,55 @@ func (r *RPCClient) DeleteInteractions(ctx context.Context, req *DeleteInteracti
 func (r *RPCClient) UpdateInteractionExcludedState(ctx context.Context, req *UpdateInteractionExcludedStateRequest) error {
 	return r.client.Do(ctx, "update_interaction_excluded_state", "2025-02-12", req, nil)
 }
+
+func (r *RPCClient) UpdateConversationTitle(ctx context.Context, req *UpdateConversationTitleRequest) (resp *UpdateConversationTitleResponse, err error) {
+	return resp, r.client.Do(ctx, "update_conversation_title", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) UpdateConversationFolder(ctx context.Context, req *UpdateConversationFolderRequest) error {
+	return r.client.Do(ctx, "update_conversation_folder", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) UpdateConversationTags(ctx context.Context, req *UpdateConversationTagsRequest) (resp *UpdateConversationTagsResponse, err error) {
+	return resp, r.client.Do(ctx, "update_conversation_tags", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) UpdateConversationPinnedState(ctx context.Context, req *UpdateConversationPinnedStateRequest) error {
+	return r.client.Do(ctx, "update_conversation_pinned_state", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) UpdateConversationArchivedState(ctx context.Context, req *UpdateConversationArchivedStateRequest) error {
+	return r.client.Do(ctx, "update_conversation_archived_state", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) CreateFolder(ctx context.Context, req *CreateFolderRequest) (resp *CreateFolderResponse, err error) {
+	return resp, r.client.Do(ctx, "create_folder", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) ListFolders(ctx context.Context, req *ListFoldersRequest) (resp *ListFoldersResponse, err error) {
+	return resp, r.client.Do(ctx, "list_folders", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) UpdateFolderName(ctx context.Context, req *UpdateFolderNameRequest) error {
+	return r.client.Do(ctx, "update_folder_name", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) DeleteFolder(ctx context.Context, req *DeleteFolderRequest) error {
+	return r.client.Do(ctx, "delete_folder", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) CreateConversationShare(ctx context.Context, req *CreateConversationShareRequest) (resp *CreateConversationShareResponse, err error) {
+	return resp, r.client.Do(ctx, "create_conversation_share", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) ListConversationShares(ctx context.Context, req *ListConversationSharesRequest) (resp *ListConversationSharesResponse, err error) {
+	return resp, r.client.Do(ctx, "list_conversation_shares", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) RevokeConversationShare(ctx context.Context, req *RevokeConversationShareRequest) error {
+	return r.client.Do(ctx, "revoke_conversation_share", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) GetSharedConversation(ctx context.Context, req *GetSharedConversationRequest) (resp *GetSharedConversationResponse, err error) {
+	return resp, r.client.Do(ctx, "get_shared_conversation", "2025-02-12", req, &resp)
+}
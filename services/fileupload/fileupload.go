@@ -1,7 +1,10 @@
 package fileupload
 
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
 import (
 	"context"
+	"time"
 )
 
 type Service interface {
@@ -9,6 +12,15 @@ type Service interface {
 	ConfirmUpload(ctx context.Context, req *ConfirmUploadRequest) error
 	GetFile(ctx context.Context, req *GetFileRequest) (*GetFileResponse, error)
 	GetManyFiles(ctx context.Context, req *GetManyFilesRequest) (*GetManyFilesResponse, error)
+	ListFilesByOwner(ctx context.Context, req *ListFilesByOwnerRequest) (*ListFilesByOwnerResponse, error)
+	DeleteFiles(ctx context.Context, req *DeleteFilesRequest) error
+	UpdateFileTranscript(ctx context.Context, req *UpdateFileTranscriptRequest) error
+
+	CreateMultipartUpload(ctx context.Context, req *CreateMultipartUploadRequest) (*CreateMultipartUploadResponse, error)
+	CreateUploadPartURLs(ctx context.Context, req *CreateUploadPartURLsRequest) (*CreateUploadPartURLsResponse, error)
+	GetMultipartUploadProgress(ctx context.Context, req *GetMultipartUploadProgressRequest) (*GetMultipartUploadProgressResponse, error)
+	CompleteMultipartUpload(ctx context.Context, req *CompleteMultipartUploadRequest) error
+	AbortMultipartUpload(ctx context.Context, req *AbortMultipartUploadRequest) error
 }
 
 type ActorType string
@@ -29,13 +41,31 @@ type File struct {
 	MIMEType           string  `json:"mime_type"`
 	Owner              *Actor  `json:"owner"`
 	PresignedAccessURL *string `json:"presigned_access_url"`
+
+	// SHA256 is the hex encoded digest of the file's content. It's nil for
+	// files confirmed before digests were stored.
+	SHA256 *string `json:"sha256"`
+
+	// Transcript is the text of an audio file, once it's been transcribed.
+	Transcript *FileTranscript `json:"transcript"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type FileTranscript struct {
+	Text       string    `json:"text"`
+	ProviderID string    `json:"provider_id"`
+	ModelID    string    `json:"model_id"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type CreateUploadRequest struct {
-	Name     string `json:"name"`
-	Size     int64  `json:"size"`
-	MIMEType string `json:"mime_type"`
-	Owner    *Actor `json:"owner"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	MIMEType       string `json:"mime_type"`
+	Owner          *Actor `json:"owner"`
 }
 
 type CreateUploadResponse struct {
@@ -68,3 +98,92 @@ type GetManyFilesRequest struct {
 type GetManyFilesResponse struct {
 	Files []*File `json:"files"`
 }
+
+type ListFilesByOwnerRequest struct {
+	Owner *Actor `json:"owner"`
+
+	// MIMETypes only lists files with one of the MIME types. Types can end
+	// with a wildcard, such as "image/*". When empty, files of any type are
+	// listed.
+	MIMETypes []string `json:"mime_types"`
+
+	// Cursor is the NextCursor of the previous page. When nil, the first page
+	// is listed.
+	Cursor *string `json:"cursor"`
+	Limit  int     `json:"limit"`
+}
+
+type ListFilesByOwnerResponse struct {
+	Files []*File `json:"files"`
+
+	// NextCursor is nil when there are no more files to list.
+	NextCursor *string `json:"next_cursor"`
+}
+
+type DeleteFilesRequest struct {
+	FileIDs []string `json:"file_ids"`
+	Owner   *Actor   `json:"owner"`
+}
+
+type UpdateFileTranscriptRequest struct {
+	FileID     string `json:"file_id"`
+	Text       string `json:"text"`
+	ProviderID string `json:"provider_id"`
+	ModelID    string `json:"model_id"`
+}
+
+type CreateMultipartUploadRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	MIMEType       string `json:"mime_type"`
+	Owner          *Actor `json:"owner"`
+}
+
+type CreateMultipartUploadResponse struct {
+	ID string `json:"id"`
+
+	// PartSize is the size of every part, other than the last which holds
+	// whatever is left over.
+	PartSize  int64 `json:"part_size"`
+	PartCount int   `json:"part_count"`
+}
+
+type CreateUploadPartURLsRequest struct {
+	FileID      string `json:"file_id"`
+	PartNumbers []int  `json:"part_numbers"`
+}
+
+type CreateUploadPartURLsResponse struct {
+	Parts []*UploadPartURL `json:"parts"`
+}
+
+type UploadPartURL struct {
+	PartNumber int    `json:"part_number"`
+	UploadURL  string `json:"upload_url"`
+}
+
+type GetMultipartUploadProgressRequest struct {
+	FileID string `json:"file_id"`
+}
+
+type GetMultipartUploadProgressResponse struct {
+	PartSize      int64           `json:"part_size"`
+	PartCount     int             `json:"part_count"`
+	UploadedParts []*UploadedPart `json:"uploaded_parts"`
+	UploadedBytes int64           `json:"uploaded_bytes"`
+	Completed     bool            `json:"completed"`
+}
+
+type UploadedPart struct {
+	PartNumber int   `json:"part_number"`
+	Size       int64 `json:"size"`
+}
+
+type CompleteMultipartUploadRequest struct {
+	FileID string `json:"file_id"`
+}
+
+type AbortMultipartUploadRequest struct {
+	FileID string `json:"file_id"`
+}
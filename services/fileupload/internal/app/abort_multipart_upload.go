@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (a *App) AbortMultipartUpload(ctx context.Context, req *fileupload.AbortMultipartUploadRequest) error {
+	file, err := a.FileRepository.Get(ctx, req.FileID)
+	if err != nil {
+		return err
+	}
+
+	if file.ConfirmedAt != nil {
+		return cher.New("file_already_confirmed", nil)
+	}
+	if file.MultipartUpload == nil {
+		return cher.New("not_multipart_upload", nil)
+	}
+
+	// Storage is cleaned up even if the file was already deleted, so retrying
+	// finishes off a failed abort
+	deleted, err := a.FileRepository.DeleteUnconfirmedUpload(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted && file.DeletedAt == nil {
+		return cher.New("file_already_confirmed", nil)
+	}
+
+	return a.deleteUnconfirmedObject(ctx, file)
+}
@@ -2,8 +2,10 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/services/fileupload"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/ports"
@@ -11,24 +13,45 @@ import (
 
 type App struct {
 	FileRepository    ports.FileRepository
+	BlobRepository    ports.BlobRepository
 	FileObjectService ports.FileObjectService
+
+	// UploadURLExpiry is how long upload URLs are valid for. Files which
+	// haven't been confirmed by the time their upload URL expires are purged.
+	UploadURLExpiry time.Duration
+
+	// DefaultUploadPolicy limits the files owners without a policy in
+	// OwnerUploadPolicies can upload. When nil, any file can be uploaded.
+	DefaultUploadPolicy *models.UploadPolicy
+	OwnerUploadPolicies map[models.Actor]*models.UploadPolicy
 }
 
 func (a *App) CreateUpload(ctx context.Context, req *fileupload.CreateUploadRequest) (*fileupload.CreateUploadResponse, error) {
+	owner := &models.Actor{
+		Type:       models.ActorType(req.Owner.Type),
+		Identifier: req.Owner.Identifier,
+	}
+	if err := a.ensureUploadAllowed(owner, req.Size, req.MIMEType, false); err != nil {
+		return nil, err
+	}
+
 	fileID, err := a.FileRepository.CreateUpload(ctx, &models.CreateUploadCommand{
-		Name:     req.Name,
-		Size:     req.Size,
-		MIMEType: req.MIMEType,
+		IdempotencyKey: req.IdempotencyKey,
+		Name:           req.Name,
+		Size:           req.Size,
+		MIMEType:       req.MIMEType,
 		Owner: &models.CreateUploadCommandActor{
 			Type:       models.ActorType(req.Owner.Type),
 			Identifier: req.Owner.Identifier,
 		},
+		UploadExpiresAt: time.Now().Add(a.UploadURLExpiry),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	presignedURL, err := a.FileObjectService.CreatePresignedUploadURL(ctx, fileID)
+	// Retried requests get the original file back, with a fresh upload URL
+	presignedURL, err := a.FileObjectService.CreatePresignedUploadURL(ctx, fileID, a.UploadURLExpiry)
 	if err != nil {
 		return nil, err
 	}
@@ -51,15 +74,81 @@ func (a *App) ConfirmUpload(ctx context.Context, req *fileupload.ConfirmUploadRe
 	if file.ConfirmedAt != nil {
 		return cher.New("file_already_confirmed", nil)
 	}
+	if file.MultipartUpload != nil && file.MultipartUpload.CompletedAt == nil {
+		return cher.New("multipart_upload_not_completed", nil)
+	}
+
+	return a.confirmUpload(ctx, file)
+}
+
+// confirmUpload checks the content uploaded for a file is valid, and marks the
+// file as confirmed so it can be used.
+func (a *App) confirmUpload(ctx context.Context, file *models.File) error {
+	// The policy might have changed since the upload was created
+	if err := a.ensureUploadAllowed(file.Owner, file.Size, file.MIMEType, file.MultipartUpload != nil); err != nil {
+		return err
+	}
 
 	if err := a.FileObjectService.EnsureFileUploadValidity(ctx, file.ID, file.MIMEType, int(file.Size)); err != nil {
 		return err
 	}
 
-	_, err = a.FileRepository.ConfirmUpload(ctx, req.FileID)
+	// The declared size and MIME type are controlled by the uploader, so check
+	// the content itself looks like what it's declared as
+	digest, detectedMIMEType, err := a.inspectObject(ctx, file.ID)
 	if err != nil {
 		return err
 	}
+	if err := ensureContentMatches(file.MIMEType, detectedMIMEType); err != nil {
+		return err
+	}
+
+	// If the owner has already uploaded the same content, the file shares the
+	// existing blob and the copy which was just uploaded is removed
+	blobID := file.ID
+	blob, err := a.BlobRepository.Reference(ctx, file.Owner, digest, file.Size, file.ID)
+	if err != nil {
+		return err
+	}
+	if blob != nil {
+		blobID = blob.ID
+	} else {
+		if err := a.BlobRepository.Create(ctx, &models.CreateBlobCommand{
+			ID:     file.ID,
+			Owner:  file.Owner,
+			SHA256: digest,
+			Size:   file.Size,
+			FileID: file.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	_, err = a.FileRepository.ConfirmUpload(ctx, file.ID, digest, blobID)
+	if err != nil {
+		// A file which wasn't confirmed mustn't keep the blob from being
+		// deleted. A blob created from this upload shares the file's own
+		// object, which is kept so confirming can be retried, and is removed
+		// with the file if it never is.
+		unreferenced, releaseErr := a.BlobRepository.Release(ctx, blobID, file.ID)
+		if releaseErr != nil {
+			clog.Get(ctx).WithError(releaseErr).Warn("failed to release blob")
+		} else if unreferenced && blobID != file.ID {
+			if deleteErr := a.FileObjectService.DeleteObject(ctx, blobID); deleteErr != nil {
+				clog.Get(ctx).WithError(deleteErr).Warn("failed to delete unreferenced blob object")
+			}
+		}
+
+		return err
+	}
+
+	if blobID != file.ID {
+		clog.SetField(ctx, "blob_id", blobID)
+
+		if err := a.FileObjectService.DeleteObject(ctx, file.ID); err != nil {
+			clog.Get(ctx).WithError(err).Warn("failed to delete duplicate upload object")
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+func (a *App) CompleteMultipartUpload(ctx context.Context, req *fileupload.CompleteMultipartUploadRequest) error {
+	file, err := a.getMultipartUploadFile(ctx, req.FileID)
+	if err != nil {
+		return err
+	}
+
+	// If joining the parts succeeded but confirming the file didn't, retrying
+	// goes straight to confirming it
+	if file.MultipartUpload.CompletedAt == nil {
+		if err := a.joinMultipartUpload(ctx, file); err != nil {
+			return err
+		}
+		if err := a.FileRepository.CompleteMultipartUpload(ctx, file.ID); err != nil {
+			return err
+		}
+	}
+
+	return a.confirmUpload(ctx, file)
+}
+
+// joinMultipartUpload joins the uploaded parts of a file into its object. An
+// earlier attempt might have joined them but failed to record it, by which
+// point the upload is gone from the object store, so the object is checked for
+// first.
+func (a *App) joinMultipartUpload(ctx context.Context, file *models.File) error {
+	if err := a.FileObjectService.EnsureFileUploadValidity(ctx, file.ID, file.MIMEType, int(file.Size)); err == nil {
+		// Anything left of the upload, such as parts an interrupted clean up
+		// missed, is no longer needed
+		return a.FileObjectService.AbortMultipartUpload(ctx, file.ID, file.MultipartUpload.UploadID)
+	}
+
+	parts, err := a.syncMultipartUploadProgress(ctx, file)
+	if err != nil {
+		return err
+	}
+	if err := ensureAllPartsUploaded(file, parts); err != nil {
+		return err
+	}
+
+	return a.FileObjectService.CompleteMultipartUpload(ctx, file.ID, file.MultipartUpload.UploadID, parts)
+}
+
+// ensureAllPartsUploaded checks every part of a file has been uploaded, and
+// that they're the right size.
+func ensureAllPartsUploaded(file *models.File, parts []*models.UploadedPart) error {
+	upload := file.MultipartUpload
+
+	uploaded := make(map[int]*models.UploadedPart, len(parts))
+	for _, part := range parts {
+		uploaded[part.PartNumber] = part
+	}
+
+	missingPartNumbers := make([]int, 0)
+	for partNumber := 1; partNumber <= upload.PartCount; partNumber++ {
+		if _, ok := uploaded[partNumber]; !ok {
+			missingPartNumbers = append(missingPartNumbers, partNumber)
+		}
+	}
+	if len(missingPartNumbers) > 0 {
+		return cher.New("multipart_upload_incomplete", cher.M{
+			"missing_part_numbers": missingPartNumbers,
+		})
+	}
+
+	for partNumber := 1; partNumber <= upload.PartCount; partNumber++ {
+		expectedSize := upload.PartSize
+		if partNumber == upload.PartCount {
+			expectedSize = file.Size - upload.PartSize*int64(upload.PartCount-1)
+		}
+
+		if size := uploaded[partNumber].Size; size != expectedSize {
+			return cher.New("part_size_mismatch", cher.M{
+				"part_number":   partNumber,
+				"expected_size": expectedSize,
+				"actual_size":   size,
+			})
+		}
+	}
+
+	return nil
+}
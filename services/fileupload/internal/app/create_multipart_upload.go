@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+const (
+	// minPartSize is the smallest parts files are split into. S3 compatible
+	// storage requires every part other than the last to be at least 5MiB.
+	minPartSize = 8 * 1024 * 1024
+	// maxPartCount is the most parts S3 compatible storage allows an object
+	// to be uploaded in.
+	maxPartCount = 10000
+)
+
+func (a *App) CreateMultipartUpload(ctx context.Context, req *fileupload.CreateMultipartUploadRequest) (*fileupload.CreateMultipartUploadResponse, error) {
+	owner := &models.Actor{
+		Type:       models.ActorType(req.Owner.Type),
+		Identifier: req.Owner.Identifier,
+	}
+	if err := a.ensureUploadAllowed(owner, req.Size, req.MIMEType, true); err != nil {
+		return nil, err
+	}
+
+	fileID, err := a.FileRepository.CreateUpload(ctx, &models.CreateUploadCommand{
+		IdempotencyKey: req.IdempotencyKey,
+		Name:           req.Name,
+		Size:           req.Size,
+		MIMEType:       req.MIMEType,
+		Owner: &models.CreateUploadCommandActor{
+			Type:       models.ActorType(req.Owner.Type),
+			Identifier: req.Owner.Identifier,
+		},
+		UploadExpiresAt: time.Now().Add(a.UploadURLExpiry),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := a.FileRepository.Get(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retried requests get the original multipart upload back
+	if file.MultipartUpload == nil {
+		if file, err = a.startMultipartUpload(ctx, file); err != nil {
+			return nil, err
+		}
+	}
+
+	return &fileupload.CreateMultipartUploadResponse{
+		ID:        file.ID,
+		PartSize:  file.MultipartUpload.PartSize,
+		PartCount: file.MultipartUpload.PartCount,
+	}, nil
+}
+
+func (a *App) startMultipartUpload(ctx context.Context, file *models.File) (*models.File, error) {
+	partSize := max(minPartSize, (file.Size+maxPartCount-1)/maxPartCount)
+	partCount := int((file.Size + partSize - 1) / partSize)
+
+	uploadID, err := a.FileObjectService.CreateMultipartUpload(ctx, file.ID, file.MIMEType, partCount)
+	if err != nil {
+		return nil, err
+	}
+
+	startedFile, err := a.FileRepository.StartMultipartUpload(ctx, file.ID, &models.MultipartUpload{
+		UploadID:  uploadID,
+		PartSize:  partSize,
+		PartCount: partCount,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A concurrent retry started its own multipart upload first, so this one
+	// won't be used
+	if startedFile.MultipartUpload.UploadID != uploadID {
+		if err := a.FileObjectService.AbortMultipartUpload(ctx, file.ID, uploadID); err != nil {
+			clog.Get(ctx).WithError(err).Warn("failed to abort unused multipart upload")
+		}
+	}
+
+	return startedFile, nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+func (a *App) CreateUploadPartURLs(ctx context.Context, req *fileupload.CreateUploadPartURLsRequest) (*fileupload.CreateUploadPartURLsResponse, error) {
+	file, err := a.getMultipartUploadFile(ctx, req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := file.MultipartUpload
+	if upload.CompletedAt != nil {
+		return nil, cher.New("multipart_upload_already_completed", nil)
+	}
+
+	for _, partNumber := range req.PartNumbers {
+		if partNumber > upload.PartCount {
+			return nil, cher.New("invalid_part_number", cher.M{
+				"part_number": partNumber,
+				"part_count":  upload.PartCount,
+			})
+		}
+	}
+
+	// Large files can take longer to upload than a single upload URL lasts,
+	// so each new batch of URLs keeps the file from being purged
+	if err := a.FileRepository.ExtendUploadExpiry(ctx, file.ID, time.Now().Add(a.UploadURLExpiry)); err != nil {
+		return nil, err
+	}
+
+	resp := &fileupload.CreateUploadPartURLsResponse{
+		Parts: make([]*fileupload.UploadPartURL, len(req.PartNumbers)),
+	}
+
+	for i, partNumber := range req.PartNumbers {
+		uploadURL, err := a.FileObjectService.CreatePresignedUploadPartURL(ctx, file.ID, upload.UploadID, partNumber, a.UploadURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Parts[i] = &fileupload.UploadPartURL{
+			PartNumber: partNumber,
+			UploadURL:  uploadURL,
+		}
+	}
+
+	return resp, nil
+}
+
+// getMultipartUploadFile gets a file which is being uploaded in parts, and
+// hasn't been confirmed yet.
+func (a *App) getMultipartUploadFile(ctx context.Context, fileID string) (*models.File, error) {
+	file, err := a.FileRepository.Get(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.DeletedAt != nil {
+		return nil, cher.New("file_deleted", nil)
+	}
+	if file.ConfirmedAt != nil {
+		return nil, cher.New("file_already_confirmed", nil)
+	}
+	if file.MultipartUpload == nil {
+		return nil, cher.New("not_multipart_upload", nil)
+	}
+
+	return file, nil
+}
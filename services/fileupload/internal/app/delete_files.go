@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+	"golang.org/x/sync/errgroup"
+)
+
+func (a *App) DeleteFiles(ctx context.Context, req *fileupload.DeleteFilesRequest) error {
+	files, err := a.FileRepository.GetMany(ctx, req.FileIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if string(file.Owner.Type) != string(req.Owner.Type) || file.Owner.Identifier != req.Owner.Identifier {
+			return cher.New("file_not_found", cher.M{"file_id": file.ID})
+		}
+	}
+
+	// Files are marked as deleted before their objects are removed, so they
+	// can't be accessed while half deleted. Objects are removed even if the
+	// file was already deleted, so retrying finishes off a failed delete.
+	if err := a.FileRepository.DeleteMany(ctx, req.FileIDs); err != nil {
+		return err
+	}
+
+	errGroup, egCtx := errgroup.WithContext(ctx)
+	errGroup.SetLimit(runtime.NumCPU() * 4)
+
+	for _, file := range files {
+		errGroup.Go(func() error {
+			if file.ConfirmedAt == nil {
+				return a.deleteUnconfirmedObject(egCtx, file)
+			}
+
+			// Confirmed files can share their blob with other files, so its
+			// object is only removed once none of them reference it
+			unreferenced, err := a.BlobRepository.Release(egCtx, file.ObjectID(), file.ID)
+			if err != nil || !unreferenced {
+				return err
+			}
+
+			return a.FileObjectService.DeleteObject(egCtx, file.ObjectID())
+		})
+	}
+
+	return errGroup.Wait()
+}
+
+// deleteUnconfirmedObject removes whatever was uploaded for a file which was
+// never confirmed. Multipart uploads which haven't been completed are aborted,
+// as their parts aren't stored in the file's object yet.
+func (a *App) deleteUnconfirmedObject(ctx context.Context, file *models.File) error {
+	if file.MultipartUpload != nil && file.MultipartUpload.CompletedAt == nil {
+		return a.FileObjectService.AbortMultipartUpload(ctx, file.ID, file.MultipartUpload.UploadID)
+	}
+
+	return a.FileObjectService.DeleteObject(ctx, file.ID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/services/fileupload"
 )
 
@@ -12,6 +13,9 @@ func (a *App) GetFile(ctx context.Context, req *fileupload.GetFileRequest) (*fil
 	if err != nil {
 		return nil, err
 	}
+	if file.DeletedAt != nil {
+		return nil, cher.New("file_not_found", cher.M{"file_id": file.ID})
+	}
 
 	var presignedAccessURL *string
 	if req.IncludeAccessURL {
@@ -21,7 +25,7 @@ func (a *App) GetFile(ctx context.Context, req *fileupload.GetFileRequest) (*fil
 			expiry = time.Second * time.Duration(expirySeconds)
 		}
 
-		presignedURL, err := a.FileObjectService.CreatePresignedDownloadURL(ctx, file.ID, expiry)
+		presignedURL, err := a.FileObjectService.CreatePresignedDownloadURL(ctx, file.ObjectID(), expiry)
 		if err != nil {
 			return nil, err
 		}
@@ -39,7 +43,12 @@ func (a *App) GetFile(ctx context.Context, req *fileupload.GetFileRequest) (*fil
 				Type:       fileupload.ActorType(file.Owner.Type),
 				Identifier: file.Owner.Identifier,
 			},
+			SHA256:             file.SHA256,
+			Transcript:         mapFileTranscript(file.Transcript),
 			PresignedAccessURL: presignedAccessURL,
+
+			CreatedAt: file.CreatedAt,
+			DeletedAt: file.DeletedAt,
 		},
 	}, nil
 }
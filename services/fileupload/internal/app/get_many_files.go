@@ -50,16 +50,23 @@ func (a *App) GetManyFiles(ctx context.Context, req *fileupload.GetManyFilesRequ
 					Type:       fileupload.ActorType(file.Owner.Type),
 					Identifier: file.Owner.Identifier,
 				},
+				SHA256:     file.SHA256,
+				Transcript: mapFileTranscript(file.Transcript),
+
+				CreatedAt: file.CreatedAt,
+				DeletedAt: file.DeletedAt,
 			}
 
-			if req.IncludeAccessURL {
+			// Deleted files' objects have been removed, so there's nothing to
+			// access
+			if req.IncludeAccessURL && file.DeletedAt == nil {
 				expiry := time.Minute * 15
 				if req.AccessURLExpirySeconds != nil {
 					expirySeconds := *req.AccessURLExpirySeconds
 					expiry = time.Second * time.Duration(expirySeconds)
 				}
 
-				presignedURL, err := a.FileObjectService.CreatePresignedDownloadURL(egCtx, file.ID, expiry)
+				presignedURL, err := a.FileObjectService.CreatePresignedDownloadURL(egCtx, file.ObjectID(), expiry)
 				if err != nil {
 					return err
 				}
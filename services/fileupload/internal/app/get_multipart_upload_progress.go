@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+func (a *App) GetMultipartUploadProgress(ctx context.Context, req *fileupload.GetMultipartUploadProgressRequest) (*fileupload.GetMultipartUploadProgressResponse, error) {
+	file, err := a.getMultipartUploadFile(ctx, req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := file.MultipartUpload
+	parts := upload.UploadedParts
+
+	// Parts are uploaded straight to storage, so it's the only thing which
+	// knows how far along the upload is. Once the parts have been joined
+	// together, the last progress which was stored is all there is.
+	if upload.CompletedAt == nil {
+		if parts, err = a.syncMultipartUploadProgress(ctx, file); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &fileupload.GetMultipartUploadProgressResponse{
+		PartSize:      upload.PartSize,
+		PartCount:     upload.PartCount,
+		UploadedParts: make([]*fileupload.UploadedPart, len(parts)),
+		Completed:     upload.CompletedAt != nil,
+	}
+
+	for i, part := range parts {
+		resp.UploadedParts[i] = &fileupload.UploadedPart{
+			PartNumber: part.PartNumber,
+			Size:       part.Size,
+		}
+		resp.UploadedBytes += part.Size
+	}
+
+	return resp, nil
+}
+
+// syncMultipartUploadProgress stores which parts of a file have been uploaded
+// on the file, and returns them.
+func (a *App) syncMultipartUploadProgress(ctx context.Context, file *models.File) ([]*models.UploadedPart, error) {
+	parts, err := a.FileObjectService.ListUploadedParts(ctx, file.ID, file.MultipartUpload.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.FileRepository.UpdateMultipartUploadProgress(ctx, file.ID, parts); err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+func (a *App) ListFilesByOwner(ctx context.Context, req *fileupload.ListFilesByOwnerRequest) (*fileupload.ListFilesByOwnerResponse, error) {
+	// One extra file is fetched to find out if there's another page
+	files, err := a.FileRepository.ListByOwner(ctx, &models.ListFilesByOwnerQuery{
+		Owner: &models.Actor{
+			Type:       models.ActorType(req.Owner.Type),
+			Identifier: req.Owner.Identifier,
+		},
+		MIMETypes: req.MIMETypes,
+		Cursor:    req.Cursor,
+		Limit:     req.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nextCursor *string
+	if len(files) > req.Limit {
+		files = files[:req.Limit]
+		nextCursor = &files[len(files)-1].ID
+	}
+
+	resp := &fileupload.ListFilesByOwnerResponse{
+		Files:      make([]*fileupload.File, len(files)),
+		NextCursor: nextCursor,
+	}
+
+	for i, file := range files {
+		resp.Files[i] = &fileupload.File{
+			ID:       file.ID,
+			Name:     file.Name,
+			Size:     file.Size,
+			MIMEType: file.MIMEType,
+			Owner: &fileupload.Actor{
+				Type:       fileupload.ActorType(file.Owner.Type),
+				Identifier: file.Owner.Identifier,
+			},
+			SHA256:     file.SHA256,
+			Transcript: mapFileTranscript(file.Transcript),
+
+			CreatedAt: file.CreatedAt,
+			DeletedAt: file.DeletedAt,
+		}
+	}
+
+	return resp, nil
+}
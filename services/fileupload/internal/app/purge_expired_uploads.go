@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+)
+
+// expiredUploadsBatchSize is how many expired uploads are purged at a time.
+const expiredUploadsBatchSize = 100
+
+// PurgeExpiredUploads deletes files which were never confirmed, and now never
+// can be as their upload URL has expired. Anything which was uploaded to them
+// is removed too.
+func (a *App) PurgeExpiredUploads(ctx context.Context) error {
+	for {
+		files, err := a.FileRepository.ListExpiredUploads(ctx, time.Now(), expiredUploadsBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list expired uploads: %w", err)
+		}
+
+		for _, file := range files {
+			// The file may have been confirmed since it was listed, if the
+			// upload finished just before the URL expired
+			deleted, err := a.FileRepository.DeleteUnconfirmedUpload(ctx, file.ID)
+			if err != nil {
+				return fmt.Errorf("failed to delete expired upload: %w", err)
+			}
+			if !deleted {
+				continue
+			}
+
+			clog.Get(ctx).WithField("file_id", file.ID).Info("purged expired upload")
+
+			if err := a.deleteUnconfirmedObject(ctx, file); err != nil {
+				return fmt.Errorf("failed to delete expired upload object: %w", err)
+			}
+		}
+
+		if len(files) < expiredUploadsBatchSize {
+			return nil
+		}
+	}
+}
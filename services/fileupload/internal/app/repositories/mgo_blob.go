@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type persistedBlob struct {
+	ID string `bson:"_id"`
+
+	Owner struct {
+		Type       string `bson:"type"`
+		Identifier string `bson:"identifier"`
+	} `bson:"owner"`
+
+	SHA256  *string  `bson:"sha256"`
+	Size    int64    `bson:"size"`
+	FileIDs []string `bson:"file_ids"`
+
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt *time.Time `bson:"updated_at"`
+	DeletedAt *time.Time `bson:"deleted_at"`
+}
+
+type mgoBlob struct {
+	c *mongo.Collection
+}
+
+func NewMgoBlob(db *mongo.Database) ports.BlobRepository {
+	return &mgoBlob{c: db.Collection("blobs")}
+}
+
+func (m *mgoBlob) Create(ctx context.Context, cmd *models.CreateBlobCommand) error {
+	_, err := m.c.UpdateOne(ctx, bson.M{
+		"_id": cmd.ID,
+	}, bson.M{
+		"$setOnInsert": bson.M{
+			"owner": bson.M{
+				"type":       cmd.Owner.Type,
+				"identifier": cmd.Owner.Identifier,
+			},
+			"sha256":     cmd.SHA256,
+			"size":       cmd.Size,
+			"created_at": time.Now(),
+			"updated_at": nil,
+			"deleted_at": nil,
+		},
+		"$addToSet": bson.M{
+			"file_ids": cmd.FileID,
+		},
+	}, options.Update().SetUpsert(true))
+
+	return err
+}
+
+func (m *mgoBlob) Reference(ctx context.Context, owner *models.Actor, sha256 string, size int64, fileID string) (*models.Blob, error) {
+	result := m.c.FindOneAndUpdate(ctx, bson.M{
+		"owner.type":       owner.Type,
+		"owner.identifier": owner.Identifier,
+		"sha256":           sha256,
+		"size":             size,
+		// Blobs which aren't referenced any more are being deleted, so they
+		// can't be brought back
+		"file_ids.0": bson.M{"$exists": true},
+	}, bson.M{
+		"$addToSet": bson.M{
+			"file_ids": fileID,
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.M{"created_at": 1}).
+		SetReturnDocument(options.After),
+	)
+
+	var blob *persistedBlob
+	if err := result.Decode(&blob); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return blob.ToDomainModel(), nil
+}
+
+func (m *mgoBlob) Release(ctx context.Context, blobID, fileID string) (bool, error) {
+	result := m.c.FindOneAndUpdate(ctx, bson.M{
+		"_id": blobID,
+	}, bson.M{
+		"$pull": bson.M{
+			"file_ids": fileID,
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+	var blob *persistedBlob
+	if err := result.Decode(&blob); err != nil {
+		// Files confirmed before blobs were added don't share their object
+		if err == mongo.ErrNoDocuments {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	if len(blob.FileIDs) > 0 {
+		return false, nil
+	}
+
+	if _, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":        blobID,
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"deleted_at": true,
+		},
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *persistedBlob) ToDomainModel() *models.Blob {
+	return &models.Blob{
+		ID: p.ID,
+		Owner: &models.Actor{
+			Type:       models.ActorType(p.Owner.Type),
+			Identifier: p.Owner.Identifier,
+		},
+		SHA256:  p.SHA256,
+		Size:    p.Size,
+		FileIDs: p.FileIDs,
+
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+		DeletedAt: p.DeletedAt,
+	}
+}
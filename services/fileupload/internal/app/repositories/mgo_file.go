@@ -2,7 +2,8 @@ package repositories
 
 import (
 	"context"
-	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
@@ -15,20 +16,54 @@ import (
 )
 
 type persistedFile struct {
-	ID       string `bson:"_id"`
-	Name     string `bson:"name"`
-	Size     int64  `bson:"size"`
-	MIMEType string `bson:"mime_type"`
+	ID             string `bson:"_id"`
+	IdempotencyKey string `bson:"idempotency_key"`
+	Name           string `bson:"name"`
+	Size           int64  `bson:"size"`
+	MIMEType       string `bson:"mime_type"`
 
 	Owner struct {
 		Type       string `bson:"type"`
 		Identifier string `bson:"identifier"`
 	} `bson:"owner"`
 
+	SHA256 *string `bson:"sha256"`
+	BlobID *string `bson:"blob_id"`
+
+	Transcript *persistedFileTranscript `bson:"transcript"`
+
 	CreatedAt   time.Time  `bson:"created_at"`
 	UpdatedAt   *time.Time `bson:"updated_at"`
 	ConfirmedAt *time.Time `bson:"confirmed_at"`
 	DeletedAt   *time.Time `bson:"deleted_at"`
+
+	UploadExpiresAt *time.Time `bson:"upload_expires_at"`
+
+	MultipartUpload *persistedMultipartUpload `bson:"multipart_upload"`
+}
+
+type persistedFileTranscript struct {
+	Text       string    `bson:"text"`
+	ProviderID string    `bson:"provider_id"`
+	ModelID    string    `bson:"model_id"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+type persistedMultipartUpload struct {
+	UploadID  string `bson:"upload_id"`
+	PartSize  int64  `bson:"part_size"`
+	PartCount int    `bson:"part_count"`
+
+	UploadedParts []*persistedUploadedPart `bson:"uploaded_parts"`
+
+	CreatedAt   time.Time  `bson:"created_at"`
+	CompletedAt *time.Time `bson:"completed_at"`
+}
+
+type persistedUploadedPart struct {
+	PartNumber int    `bson:"part_number"`
+	Size       int64  `bson:"size"`
+	ETag       string `bson:"etag"`
 }
 
 type mgoFile struct {
@@ -40,48 +75,75 @@ func NewMgoFile(db *mongo.Database) ports.FileRepository {
 }
 
 func (m *mgoFile) CreateUpload(ctx context.Context, req *models.CreateUploadCommand) (string, error) {
-	id := ksuid.Generate(ctx, "file").String()
-
-	result, err := m.c.InsertOne(ctx, bson.M{
-		"_id":       id,
-		"name":      req.Name,
-		"size":      req.Size,
-		"mime_type": req.MIMEType,
-		"owner": bson.M{
-			"type":       req.Owner.Type,
-			"identifier": req.Owner.Identifier,
-		},
-		"created_at":   time.Now(),
-		"updated_at":   nil,
-		"confirmed_at": nil,
-		"deleted_at":   nil,
-	})
-	if err != nil {
-		return "", err
+	filter := bson.M{
+		"idempotency_key":  req.IdempotencyKey,
+		"owner.type":       req.Owner.Type,
+		"owner.identifier": req.Owner.Identifier,
 	}
 
-	id, ok := result.InsertedID.(string)
-	if !ok {
-		return "", errors.New("failed to cast InsertedID to string")
+	result := m.c.FindOneAndUpdate(ctx, filter, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":             ksuid.Generate(ctx, "file").String(),
+			"idempotency_key": req.IdempotencyKey,
+			"name":            req.Name,
+			"size":            req.Size,
+			"mime_type":       req.MIMEType,
+			"owner": bson.M{
+				"type":       req.Owner.Type,
+				"identifier": req.Owner.Identifier,
+			},
+			"sha256":       nil,
+			"blob_id":      nil,
+			"created_at":   time.Now(),
+			"updated_at":   nil,
+			"confirmed_at": nil,
+			"deleted_at":   nil,
+
+			"multipart_upload": nil,
+		},
+		// Each retry gives out a new upload URL, so the upload expires with
+		// whichever URL expires last
+		"$max": bson.M{
+			"upload_expires_at": req.UploadExpiresAt,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var file persistedFile
+	err := result.Decode(&file)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its file
+		err = m.c.FindOne(ctx, filter).Decode(&file)
+	}
+	if err != nil {
+		return "", err
 	}
 
-	return id, nil
+	return file.ID, nil
 }
 
-func (m *mgoFile) ConfirmUpload(ctx context.Context, fileID string) (*models.File, error) {
+func (m *mgoFile) ConfirmUpload(ctx context.Context, fileID, sha256, blobID string) (*models.File, error) {
 	result := m.c.FindOneAndUpdate(ctx, bson.M{
-		"_id": fileID,
+		"_id":        fileID,
+		"deleted_at": nil,
 	}, bson.M{
 		"$currentDate": bson.M{
 			"updated_at": true,
 		},
 		"$set": bson.M{
 			"confirmed_at": time.Now(),
+			"sha256":       sha256,
+			"blob_id":      blobID,
 		},
 	}, options.FindOneAndUpdate().SetUpsert(false).SetReturnDocument(options.After))
 
 	var file *persistedFile
 	if err := result.Decode(&file); err != nil {
+		// The upload expired and was deleted while it was being confirmed
+		if err == mongo.ErrNoDocuments {
+			return nil, cher.New("file_deleted", nil)
+		}
+
 		return nil, err
 	}
 
@@ -144,6 +206,217 @@ func (m *mgoFile) GetMany(ctx context.Context, ids []string) ([]*models.File, er
 	return filesDomain, nil
 }
 
+func (m *mgoFile) ListByOwner(ctx context.Context, query *models.ListFilesByOwnerQuery) ([]*models.File, error) {
+	filter := bson.M{
+		"owner.type":       query.Owner.Type,
+		"owner.identifier": query.Owner.Identifier,
+		"confirmed_at":     bson.M{"$ne": nil},
+		"deleted_at":       nil,
+	}
+
+	// IDs are KSUIDs, which sort by the time they were generated
+	if query.Cursor != nil {
+		filter["_id"] = bson.M{"$lt": *query.Cursor}
+	}
+
+	if len(query.MIMETypes) > 0 {
+		mimeTypeFilters := make(bson.A, len(query.MIMETypes))
+		for i, mimeType := range query.MIMETypes {
+			if prefix, ok := strings.CutSuffix(mimeType, "*"); ok {
+				mimeTypeFilters[i] = bson.M{"mime_type": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+			} else {
+				mimeTypeFilters[i] = bson.M{"mime_type": mimeType}
+			}
+		}
+
+		filter["$or"] = mimeTypeFilters
+	}
+
+	cursor, err := m.c.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(int64(query.Limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []*persistedFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	filesDomain := make([]*models.File, len(files))
+	for i, file := range files {
+		filesDomain[i] = file.ToDomainModel()
+	}
+
+	return filesDomain, nil
+}
+
+func (m *mgoFile) DeleteMany(ctx context.Context, ids []string) error {
+	_, err := m.c.UpdateMany(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"deleted_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"deleted_at": true,
+			"updated_at": true,
+		},
+	})
+
+	return err
+}
+
+func (m *mgoFile) UpdateTranscript(ctx context.Context, fileID string, transcript *models.FileTranscript) error {
+	result, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":          fileID,
+		"confirmed_at": bson.M{"$ne": nil},
+		"deleted_at":   nil,
+	}, bson.M{
+		"$set": bson.M{
+			"transcript": &persistedFileTranscript{
+				Text:       transcript.Text,
+				ProviderID: transcript.ProviderID,
+				ModelID:    transcript.ModelID,
+				CreatedAt:  transcript.CreatedAt,
+			},
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return cher.New("file_not_found", cher.M{"file_id": fileID})
+	}
+
+	return nil
+}
+
+func (m *mgoFile) ListExpiredUploads(ctx context.Context, expiredBefore time.Time, limit int) ([]*models.File, error) {
+	cursor, err := m.c.Find(ctx, bson.M{
+		"confirmed_at":      nil,
+		"deleted_at":        nil,
+		"upload_expires_at": bson.M{"$lt": expiredBefore},
+	}, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []*persistedFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	filesDomain := make([]*models.File, len(files))
+	for i, file := range files {
+		filesDomain[i] = file.ToDomainModel()
+	}
+
+	return filesDomain, nil
+}
+
+func (m *mgoFile) DeleteUnconfirmedUpload(ctx context.Context, fileID string) (bool, error) {
+	result, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":          fileID,
+		"confirmed_at": nil,
+		"deleted_at":   nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"deleted_at": true,
+			"updated_at": true,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (m *mgoFile) ExtendUploadExpiry(ctx context.Context, fileID string, expiresAt time.Time) error {
+	_, err := m.c.UpdateOne(ctx, bson.M{
+		"_id": fileID,
+	}, bson.M{
+		"$max": bson.M{
+			"upload_expires_at": expiresAt,
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	})
+
+	return err
+}
+
+func (m *mgoFile) StartMultipartUpload(ctx context.Context, fileID string, upload *models.MultipartUpload) (*models.File, error) {
+	if _, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":              fileID,
+		"multipart_upload": nil,
+	}, bson.M{
+		"$set": bson.M{
+			"multipart_upload": &persistedMultipartUpload{
+				UploadID:      upload.UploadID,
+				PartSize:      upload.PartSize,
+				PartCount:     upload.PartCount,
+				UploadedParts: []*persistedUploadedPart{},
+				CreatedAt:     upload.CreatedAt,
+			},
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return m.Get(ctx, fileID)
+}
+
+func (m *mgoFile) UpdateMultipartUploadProgress(ctx context.Context, fileID string, parts []*models.UploadedPart) error {
+	persistedParts := make([]*persistedUploadedPart, len(parts))
+	for i, part := range parts {
+		persistedParts[i] = &persistedUploadedPart{
+			PartNumber: part.PartNumber,
+			Size:       part.Size,
+			ETag:       part.ETag,
+		}
+	}
+
+	_, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":              fileID,
+		"multipart_upload": bson.M{"$ne": nil},
+	}, bson.M{
+		"$set": bson.M{
+			"multipart_upload.uploaded_parts": persistedParts,
+		},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	})
+
+	return err
+}
+
+func (m *mgoFile) CompleteMultipartUpload(ctx context.Context, fileID string) error {
+	_, err := m.c.UpdateOne(ctx, bson.M{
+		"_id":                           fileID,
+		"multipart_upload":              bson.M{"$ne": nil},
+		"multipart_upload.completed_at": nil,
+	}, bson.M{
+		"$currentDate": bson.M{
+			"multipart_upload.completed_at": true,
+			"updated_at":                    true,
+		},
+	})
+
+	return err
+}
+
 func (p *persistedFile) ToDomainModel() *models.File {
 	return &models.File{
 		ID:       p.ID,
@@ -154,9 +427,55 @@ func (p *persistedFile) ToDomainModel() *models.File {
 			Type:       models.ActorType(p.Owner.Type),
 			Identifier: p.Owner.Identifier,
 		},
+		SHA256:      p.SHA256,
+		BlobID:      p.BlobID,
+		Transcript:  p.Transcript.ToDomainModel(),
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
 		ConfirmedAt: p.ConfirmedAt,
 		DeletedAt:   p.DeletedAt,
+
+		UploadExpiresAt: p.UploadExpiresAt,
+
+		MultipartUpload: p.MultipartUpload.ToDomainModel(),
+	}
+}
+
+func (p *persistedFileTranscript) ToDomainModel() *models.FileTranscript {
+	if p == nil {
+		return nil
+	}
+
+	return &models.FileTranscript{
+		Text:       p.Text,
+		ProviderID: p.ProviderID,
+		ModelID:    p.ModelID,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+func (p *persistedMultipartUpload) ToDomainModel() *models.MultipartUpload {
+	if p == nil {
+		return nil
+	}
+
+	upload := &models.MultipartUpload{
+		UploadID:      p.UploadID,
+		PartSize:      p.PartSize,
+		PartCount:     p.PartCount,
+		UploadedParts: make([]*models.UploadedPart, len(p.UploadedParts)),
+
+		CreatedAt:   p.CreatedAt,
+		CompletedAt: p.CompletedAt,
 	}
+
+	for i, part := range p.UploadedParts {
+		upload.UploadedParts[i] = &models.UploadedPart{
+			PartNumber: part.PartNumber,
+			Size:       part.Size,
+			ETag:       part.ETag,
+		}
+	}
+
+	return upload
 }
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrate backfills fields which documents written by older versions of the
+// service don't have. Each migration only touches documents which still need
+// it, so this is run on every startup.
+func Migrate(ctx context.Context, db *mongo.Database, uploadURLExpiry time.Duration) error {
+	files := db.Collection("files")
+
+	// Files used to only ever be given an upload URL when they were created
+	if _, err := files.UpdateMany(ctx, bson.M{
+		"upload_expires_at": bson.M{"$exists": false},
+	}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"upload_expires_at": bson.M{"$add": bson.A{"$created_at", uploadURLExpiry.Milliseconds()}},
+		}}},
+	}); err != nil {
+		return fmt.Errorf("failed to backfill file upload expiries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localObjectStore stores each object as a file in root, with its content type
+// in a file next to it.
+type localObjectStore struct {
+	root string
+}
+
+func newLocalObjectStore(root string) (*localObjectStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &localObjectStore{root: root}, nil
+}
+
+func (l *localObjectStore) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	// The content type is written first, so an object is never seen without
+	// one
+	if err := l.writeFile(l.contentTypePath(key), func(f *os.File) error {
+		_, err := f.WriteString(contentType)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return l.writeFile(l.objectPath(key), func(f *os.File) error {
+		_, err := io.Copy(f, r)
+		return err
+	})
+}
+
+func (l *localObjectStore) Get(ctx context.Context, key string) (io.ReadSeekCloser, *objectInfo, error) {
+	info, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(l.objectPath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, errObjectNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+func (l *localObjectStore) Stat(ctx context.Context, key string) (*objectInfo, error) {
+	stat, err := os.Stat(l.objectPath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errObjectNotFound
+		}
+
+		return nil, err
+	}
+
+	contentType, err := os.ReadFile(l.contentTypePath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectInfo{
+		Size:        stat.Size(),
+		ContentType: string(contentType),
+		ModifiedAt:  stat.ModTime(),
+	}, nil
+}
+
+func (l *localObjectStore) Delete(ctx context.Context, key string) error {
+	for _, path := range []string{l.objectPath(key), l.contentTypePath(key)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes to a temporary file which is renamed into place, so readers
+// never see a partially written file.
+func (l *localObjectStore) writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.CreateTemp(l.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (l *localObjectStore) objectPath(key string) string {
+	return filepath.Join(l.root, key)
+}
+
+func (l *localObjectStore) contentTypePath(key string) string {
+	return filepath.Join(l.root, key+".content-type")
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	content []byte
+	info    objectInfo
+}
+
+// memoryObjectStore stores objects in a map.
+type memoryObjectStore struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+func newMemoryObjectStore() *memoryObjectStore {
+	return &memoryObjectStore{objects: map[string]*memoryObject{}}
+}
+
+func (m *memoryObjectStore) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[key] = &memoryObject{
+		content: content,
+		info: objectInfo{
+			Size:        int64(len(content)),
+			ContentType: contentType,
+			ModifiedAt:  time.Now(),
+		},
+	}
+
+	return nil
+}
+
+func (m *memoryObjectStore) Get(ctx context.Context, key string) (io.ReadSeekCloser, *objectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	object, ok := m.objects[key]
+	if !ok {
+		return nil, nil, errObjectNotFound
+	}
+
+	// Objects are replaced rather than modified, so the content can be read
+	// after the lock is released
+	info := object.info
+	return nopSeekCloser{bytes.NewReader(object.content)}, &info, nil
+}
+
+func (m *memoryObjectStore) Stat(ctx context.Context, key string) (*objectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	object, ok := m.objects[key]
+	if !ok {
+		return nil, errObjectNotFound
+	}
+
+	info := object.info
+	return &info, nil
+}
+
+func (m *memoryObjectStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, key)
+
+	return nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
@@ -2,19 +2,18 @@ package services
 
 import (
 	"context"
-	"fmt"
-	"mime"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/ports"
 	"github.com/minio/minio-go/v7"
 )
 
-var (
-	minioPresignedUploadExpiry = 15 * time.Minute
-)
-
 type MinioFileObject struct {
 	client     *minio.Client
 	bucketName string
@@ -27,8 +26,8 @@ func NewMinioFileObject(client *minio.Client, bucketName string) ports.FileObjec
 	}
 }
 
-func (m *MinioFileObject) CreatePresignedUploadURL(ctx context.Context, fileID string) (string, error) {
-	presignedURL, err := m.client.PresignedPutObject(ctx, m.bucketName, fileID, minioPresignedUploadExpiry)
+func (m *MinioFileObject) CreatePresignedUploadURL(ctx context.Context, fileID string, expiry time.Duration) (string, error) {
+	presignedURL, err := m.client.PresignedPutObject(ctx, m.bucketName, fileID, expiry)
 	if err != nil {
 		return "", err
 	}
@@ -51,24 +50,97 @@ func (m *MinioFileObject) EnsureFileUploadValidity(ctx context.Context, fileID,
 		return err
 	}
 
-	if object.Size != int64(size) {
-		return cher.New("file_size_mismatch", cher.M{
-			"expected_size": size,
-			"actual_size":   object.Size,
-		})
+	return ensureObjectMatches(object.Size, object.ContentType, mimeType, size)
+}
+
+func (m *MinioFileObject) OpenObject(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	// GetObject doesn't make a request until the object is read, so check it
+	// exists first to return a useful error
+	if _, err := m.client.StatObject(ctx, m.bucketName, fileID, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, cher.New("file_not_uploaded", cher.M{"file_id": fileID})
+		}
+
+		return nil, err
 	}
 
-	mediaType, _, err := mime.ParseMediaType(object.ContentType)
+	return m.client.GetObject(ctx, m.bucketName, fileID, minio.GetObjectOptions{})
+}
+
+func (m *MinioFileObject) DeleteObject(ctx context.Context, fileID string) error {
+	return m.client.RemoveObject(ctx, m.bucketName, fileID, minio.RemoveObjectOptions{})
+}
+
+func (m *MinioFileObject) CreateMultipartUpload(ctx context.Context, fileID, mimeType string, partCount int) (string, error) {
+	return m.core().NewMultipartUpload(ctx, m.bucketName, fileID, minio.PutObjectOptions{
+		ContentType: mimeType,
+	})
+}
+
+func (m *MinioFileObject) CreatePresignedUploadPartURL(ctx context.Context, fileID, uploadID string, partNumber int, expiry time.Duration) (string, error) {
+	presignedURL, err := m.client.Presign(ctx, http.MethodPut, m.bucketName, fileID, expiry, url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse content type: %w", err)
+		return "", err
 	}
 
-	if mediaType != mimeType {
-		return cher.New("file_mime_type_mismatch", cher.M{
-			"expected_mime_type": mimeType,
-			"actual_mime_type":   mediaType,
-		})
+	return presignedURL.String(), nil
+}
+
+func (m *MinioFileObject) ListUploadedParts(ctx context.Context, fileID, uploadID string) ([]*models.UploadedPart, error) {
+	var parts []*models.UploadedPart
+	partNumberMarker := 0
+
+	for {
+		result, err := m.core().ListObjectParts(ctx, m.bucketName, fileID, uploadID, partNumberMarker, 1000)
+		if err != nil {
+			if minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+				return nil, cher.New("multipart_upload_not_found", cher.M{"file_id": fileID})
+			}
+
+			return nil, err
+		}
+
+		for _, part := range result.ObjectParts {
+			parts = append(parts, &models.UploadedPart{
+				PartNumber: part.PartNumber,
+				Size:       part.Size,
+				ETag:       part.ETag,
+			})
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+
+		partNumberMarker = result.NextPartNumberMarker
+	}
+}
+
+func (m *MinioFileObject) CompleteMultipartUpload(ctx context.Context, fileID, uploadID string, parts []*models.UploadedPart) error {
+	completeParts := make([]minio.CompletePart, len(parts))
+	for i, part := range parts {
+		completeParts[i] = minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		}
 	}
 
-	return nil
+	_, err := m.core().CompleteMultipartUpload(ctx, m.bucketName, fileID, uploadID, completeParts, minio.PutObjectOptions{})
+	return err
+}
+
+func (m *MinioFileObject) AbortMultipartUpload(ctx context.Context, fileID, uploadID string) error {
+	err := m.core().AbortMultipartUpload(ctx, m.bucketName, fileID, uploadID)
+	if err != nil && minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+		return nil
+	}
+
+	return err
+}
+
+func (m *MinioFileObject) core() *minio.Core {
+	return &minio.Core{Client: m.client}
 }
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/ports"
+)
+
+// objectsPathPrefix is where SignedURLFileObject serves objects from.
+const objectsPathPrefix = "/objects/"
+
+var errObjectNotFound = errors.New("object not found")
+
+type objectInfo struct {
+	Size        int64
+	ContentType string
+	ModifiedAt  time.Time
+}
+
+// objectStore stores the content of files for SignedURLFileObject.
+type objectStore interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	// Get and Stat return errObjectNotFound if the object doesn't exist.
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *objectInfo, error)
+	Stat(ctx context.Context, key string) (*objectInfo, error)
+	// Delete is a no-op if the object doesn't exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// SignedURLFileObject stores files without an external object store. It serves
+// uploads and downloads itself, through URLs signed with an HMAC so they can be
+// handed out the same way as presigned MinIO URLs.
+type SignedURLFileObject struct {
+	store   objectStore
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalFileObject stores files in a directory on the local filesystem.
+// baseURL is the public URL of the service, which signed URLs are built from.
+func NewLocalFileObject(root, baseURL string, secret []byte) (*SignedURLFileObject, error) {
+	store, err := newLocalObjectStore(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedURLFileObject{
+		store:   store,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+// NewMemoryFileObject stores files in memory, so they're lost when the service
+// stops. It's intended for tests.
+func NewMemoryFileObject(baseURL string, secret []byte) *SignedURLFileObject {
+	return &SignedURLFileObject{
+		store:   newMemoryObjectStore(),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}
+}
+
+var _ ports.FileObjectService = (*SignedURLFileObject)(nil)
+
+func (s *SignedURLFileObject) CreatePresignedUploadURL(ctx context.Context, fileID string, expiry time.Duration) (string, error) {
+	return s.signURL(http.MethodPut, fileID, time.Now().Add(expiry)), nil
+}
+
+func (s *SignedURLFileObject) CreatePresignedDownloadURL(ctx context.Context, fileID string, expiry time.Duration) (string, error) {
+	return s.signURL(http.MethodGet, fileID, time.Now().Add(expiry)), nil
+}
+
+func (s *SignedURLFileObject) EnsureFileUploadValidity(ctx context.Context, fileID, mimeType string, size int) error {
+	object, err := s.store.Stat(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return cher.New("file_not_uploaded", cher.M{"file_id": fileID})
+		}
+
+		return err
+	}
+
+	return ensureObjectMatches(object.Size, object.ContentType, mimeType, size)
+}
+
+func (s *SignedURLFileObject) OpenObject(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	content, _, err := s.store.Get(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil, cher.New("file_not_uploaded", cher.M{"file_id": fileID})
+		}
+
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (s *SignedURLFileObject) DeleteObject(ctx context.Context, fileID string) error {
+	return s.store.Delete(ctx, fileID)
+}
+
+// CreateMultipartUpload stores a manifest for the upload, holding the MIME type
+// of the file and how many parts it's uploaded in. Each part is uploaded to
+// its own object, which are joined together when the upload completes.
+func (s *SignedURLFileObject) CreateMultipartUpload(ctx context.Context, fileID, mimeType string, partCount int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	uploadID := hex.EncodeToString(b)
+
+	if err := s.store.Put(ctx, uploadManifestKey(fileID, uploadID), mimeType, strings.NewReader(strconv.Itoa(partCount))); err != nil {
+		return "", err
+	}
+
+	return uploadID, nil
+}
+
+func (s *SignedURLFileObject) CreatePresignedUploadPartURL(ctx context.Context, fileID, uploadID string, partNumber int, expiry time.Duration) (string, error) {
+	return s.signURL(http.MethodPut, uploadPartKey(fileID, uploadID, partNumber), time.Now().Add(expiry)), nil
+}
+
+func (s *SignedURLFileObject) ListUploadedParts(ctx context.Context, fileID, uploadID string) ([]*models.UploadedPart, error) {
+	_, partCount, err := s.getUploadManifest(ctx, fileID, uploadID)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil, cher.New("multipart_upload_not_found", cher.M{"file_id": fileID})
+		}
+
+		return nil, err
+	}
+
+	var parts []*models.UploadedPart
+	for partNumber := 1; partNumber <= partCount; partNumber++ {
+		object, err := s.store.Stat(ctx, uploadPartKey(fileID, uploadID, partNumber))
+		if err != nil {
+			if errors.Is(err, errObjectNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		parts = append(parts, &models.UploadedPart{
+			PartNumber: partNumber,
+			Size:       object.Size,
+		})
+	}
+
+	return parts, nil
+}
+
+func (s *SignedURLFileObject) CompleteMultipartUpload(ctx context.Context, fileID, uploadID string, parts []*models.UploadedPart) error {
+	contentType, _, err := s.getUploadManifest(ctx, fileID, uploadID)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return cher.New("multipart_upload_not_found", cher.M{"file_id": fileID})
+		}
+
+		return err
+	}
+
+	// Parts are streamed into the object one at a time, rather than opening
+	// them all at once
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.copyUploadParts(ctx, pw, fileID, uploadID, parts))
+	}()
+
+	err = s.store.Put(ctx, fileID, contentType, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+
+	return s.AbortMultipartUpload(ctx, fileID, uploadID)
+}
+
+func (s *SignedURLFileObject) AbortMultipartUpload(ctx context.Context, fileID, uploadID string) error {
+	_, partCount, err := s.getUploadManifest(ctx, fileID, uploadID)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	for partNumber := 1; partNumber <= partCount; partNumber++ {
+		if err := s.store.Delete(ctx, uploadPartKey(fileID, uploadID, partNumber)); err != nil {
+			return err
+		}
+	}
+
+	// The manifest is deleted last, so a failed abort can be retried
+	return s.store.Delete(ctx, uploadManifestKey(fileID, uploadID))
+}
+
+func (s *SignedURLFileObject) getUploadManifest(ctx context.Context, fileID, uploadID string) (string, int, error) {
+	content, object, err := s.store.Get(ctx, uploadManifestKey(fileID, uploadID))
+	if err != nil {
+		return "", 0, err
+	}
+	defer content.Close()
+
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return "", 0, err
+	}
+
+	partCount, err := strconv.Atoi(string(b))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid multipart upload manifest: %w", err)
+	}
+
+	return object.ContentType, partCount, nil
+}
+
+func (s *SignedURLFileObject) copyUploadParts(ctx context.Context, w io.Writer, fileID, uploadID string, parts []*models.UploadedPart) error {
+	for _, part := range parts {
+		content, _, err := s.store.Get(ctx, uploadPartKey(fileID, uploadID, part.PartNumber))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP handles uploads and downloads made with signed URLs.
+func (s *SignedURLFileObject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	fileID := strings.TrimPrefix(r.URL.Path, objectsPathPrefix)
+
+	// Downloads are signed for GET, which HEAD requests are also allowed to use
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	if !s.verifyURL(method, fileID, r.URL.Query()) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if err := s.store.Put(ctx, fileID, r.Header.Get("Content-Type"), r.Body); err != nil {
+			clog.Get(ctx).WithError(err).Error("failed to store object")
+			http.Error(w, "failed to store object", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet, http.MethodHead:
+		content, object, err := s.store.Get(ctx, fileID)
+		if err != nil {
+			if errors.Is(err, errObjectNotFound) {
+				http.NotFound(w, r)
+				return
+			}
+
+			clog.Get(ctx).WithError(err).Error("failed to get object")
+			http.Error(w, "failed to get object", http.StatusInternalServerError)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", object.ContentType)
+		http.ServeContent(w, r, fileID, object.ModifiedAt, content)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *SignedURLFileObject) signURL(method, fileID string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.signature(method, fileID, expires))
+
+	return fmt.Sprintf("%s%s%s?%s", s.baseURL, objectsPathPrefix, url.PathEscape(fileID), query.Encode())
+}
+
+func (s *SignedURLFileObject) verifyURL(method, fileID string, query url.Values) bool {
+	// File IDs are used as object keys, so they can't contain anything which
+	// could be used to escape the store
+	if fileID == "" || path.Base(fileID) != fileID {
+		return false
+	}
+
+	expires := query.Get("expires")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return false
+	}
+
+	expected, _ := hex.DecodeString(s.signature(method, fileID, expires))
+	return hmac.Equal(signature, expected)
+}
+
+func (s *SignedURLFileObject) signature(method, fileID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s", method, fileID, expires)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// uploadManifestKey and uploadPartKey are the keys of the objects a multipart
+// upload is stored in until it completes. They're served like any other object,
+// so part upload URLs are signed the same way as upload URLs.
+func uploadManifestKey(fileID, uploadID string) string {
+	return fileID + "." + uploadID
+}
+
+func uploadPartKey(fileID, uploadID string, partNumber int) string {
+	return fmt.Sprintf("%s.%s.%d", fileID, uploadID, partNumber)
+}
+
+// ensureObjectMatches checks an uploaded object is the file its upload was
+// created for.
+func ensureObjectMatches(objectSize int64, objectContentType, mimeType string, size int) error {
+	if objectSize != int64(size) {
+		return cher.New("file_size_mismatch", cher.M{
+			"expected_size": size,
+			"actual_size":   objectSize,
+		})
+	}
+
+	mediaType, _, err := mime.ParseMediaType(objectContentType)
+	if err != nil {
+		return fmt.Errorf("failed to parse content type: %w", err)
+	}
+
+	if mediaType != mimeType {
+		return cher.New("file_mime_type_mismatch", cher.M{
+			"expected_mime_type": mimeType,
+			"actual_mime_type":   mediaType,
+		})
+	}
+
+	return nil
+}
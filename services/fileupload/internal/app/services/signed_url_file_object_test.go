@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+var testSecret = []byte("test-secret")
+
+func newTestFileObject() *SignedURLFileObject {
+	return NewMemoryFileObject("http://files.bloefish.local/", testSecret)
+}
+
+// serve makes a request to the file object's handler, as if it were made to a
+// signed URL.
+func serve(s *SignedURLFileObject, method, signedURL, contentType, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, signedURL, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	return w
+}
+
+// withQuery returns the URL with a query parameter replaced.
+func withQuery(is *is.I, signedURL, key, value string) string {
+	u, err := url.Parse(signedURL)
+	is.NoErr(err)
+
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func TestSignedURLFileObject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UploadAndDownload", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+		is.True(strings.HasPrefix(uploadURL, "http://files.bloefish.local/objects/file_1?")) // urls are built from the base url
+
+		w := serve(s, http.MethodPut, uploadURL, "text/plain; charset=utf-8", "hello")
+		is.Equal(w.Code, http.StatusOK)
+
+		is.NoErr(s.EnsureFileUploadValidity(ctx, "file_1", "text/plain", 5))
+
+		downloadURL, err := s.CreatePresignedDownloadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		w = serve(s, http.MethodGet, downloadURL, "", "")
+		is.Equal(w.Code, http.StatusOK)
+		is.Equal(w.Body.String(), "hello")
+		is.Equal(w.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+
+		w = serve(s, http.MethodHead, downloadURL, "", "")
+		is.Equal(w.Code, http.StatusOK) // head requests use download urls
+	})
+
+	t.Run("DownloadMissingObject", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		downloadURL, err := s.CreatePresignedDownloadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		w := serve(s, http.MethodGet, downloadURL, "", "")
+		is.Equal(w.Code, http.StatusNotFound)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", -time.Minute)
+		is.NoErr(err)
+
+		w := serve(s, http.MethodPut, uploadURL, "text/plain", "hello")
+		is.Equal(w.Code, http.StatusForbidden)
+
+		err = s.EnsureFileUploadValidity(ctx, "file_1", "text/plain", 5)
+		_, ok := cher.AsCherWithCode(err, "file_not_uploaded")
+		is.True(ok) // nothing was stored
+	})
+
+	t.Run("ExtendedExpiry", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", -time.Minute)
+		is.NoErr(err)
+
+		extended := withQuery(is, uploadURL, "expires", "99999999999")
+
+		w := serve(s, http.MethodPut, extended, "text/plain", "hello")
+		is.Equal(w.Code, http.StatusForbidden) // the expiry is signed
+	})
+
+	t.Run("TamperedSignature", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		u, err := url.Parse(uploadURL)
+		is.NoErr(err)
+		signature := u.Query().Get("signature")
+
+		// Flip the last hex digit
+		last := signature[len(signature)-1]
+		flipped := byte('0')
+		if last == '0' {
+			flipped = '1'
+		}
+
+		tests := []struct {
+			Name      string
+			Signature string
+		}{
+			{"Flipped", signature[:len(signature)-1] + string(flipped)},
+			{"Truncated", signature[:len(signature)-2]},
+			{"NotHex", "not-a-signature"},
+			{"Empty", ""},
+		}
+
+		for _, test := range tests {
+			w := serve(s, http.MethodPut, withQuery(is, uploadURL, "signature", test.Signature), "text/plain", "hello")
+			is.Equal(w.Code, http.StatusForbidden) // tampered signatures are rejected
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+		other := NewMemoryFileObject("http://files.bloefish.local", []byte("other-secret"))
+
+		uploadURL, err := other.CreatePresignedUploadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		w := serve(s, http.MethodPut, uploadURL, "text/plain", "hello")
+		is.Equal(w.Code, http.StatusForbidden)
+	})
+
+	t.Run("WrongFile", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		w := serve(s, http.MethodPut, strings.Replace(uploadURL, "file_1", "file_2", 1), "text/plain", "hello")
+		is.Equal(w.Code, http.StatusForbidden) // signatures are for a single file
+	})
+
+	t.Run("WrongMethod", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadURL, err := s.CreatePresignedUploadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+		downloadURL, err := s.CreatePresignedDownloadURL(ctx, "file_1", time.Minute)
+		is.NoErr(err)
+
+		w := serve(s, http.MethodPut, downloadURL, "text/plain", "hello")
+		is.Equal(w.Code, http.StatusForbidden) // download urls can't upload
+
+		w = serve(s, http.MethodPut, uploadURL, "text/plain", "hello")
+		is.Equal(w.Code, http.StatusOK)
+
+		w = serve(s, http.MethodGet, uploadURL, "", "")
+		is.Equal(w.Code, http.StatusForbidden) // upload urls can't download
+
+		w = serve(s, http.MethodDelete, uploadURL, "", "")
+		is.Equal(w.Code, http.StatusForbidden) // nothing else is signed
+	})
+
+	t.Run("InvalidFileID", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		expires := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+		signedQuery := func(fileID string) url.Values {
+			query := url.Values{}
+			query.Set("expires", expires)
+			query.Set("signature", s.signature(http.MethodPut, fileID, expires))
+
+			return query
+		}
+
+		is.True(s.verifyURL(http.MethodPut, "file_1", signedQuery("file_1")))
+
+		for _, fileID := range []string{"", "../file_1", "nested/file_1", "/file_1"} {
+			is.True(!s.verifyURL(http.MethodPut, fileID, signedQuery(fileID))) // file ids can't escape the store
+		}
+	})
+
+	t.Run("MultipartUpload", func(t *testing.T) {
+		is := is.New(t)
+		s := newTestFileObject()
+
+		uploadID, err := s.CreateMultipartUpload(ctx, "file_1", "text/plain", 2)
+		is.NoErr(err)
+
+		for i, content := range []string{"hello ", "world"} {
+			partURL, err := s.CreatePresignedUploadPartURL(ctx, "file_1", uploadID, i+1, time.Minute)
+			is.NoErr(err)
+
+			w := serve(s, http.MethodPut, partURL, "", content)
+			is.Equal(w.Code, http.StatusOK)
+		}
+
+		parts, err := s.ListUploadedParts(ctx, "file_1", uploadID)
+		is.NoErr(err)
+		is.Equal(parts, []*models.UploadedPart{
+			{PartNumber: 1, Size: 6},
+			{PartNumber: 2, Size: 5},
+		})
+
+		is.NoErr(s.CompleteMultipartUpload(ctx, "file_1", uploadID, parts))
+		is.NoErr(s.EnsureFileUploadValidity(ctx, "file_1", "text/plain", 11))
+
+		content, err := s.OpenObject(ctx, "file_1")
+		is.NoErr(err)
+		defer content.Close()
+
+		b, err := io.ReadAll(content)
+		is.NoErr(err)
+		is.Equal(string(b), "hello world")
+
+		_, err = s.ListUploadedParts(ctx, "file_1", uploadID)
+		_, ok := cher.AsCherWithCode(err, "multipart_upload_not_found")
+		is.True(ok) // parts are cleaned up once the upload completes
+	})
+}
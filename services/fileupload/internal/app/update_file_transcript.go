@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+func (a *App) UpdateFileTranscript(ctx context.Context, req *fileupload.UpdateFileTranscriptRequest) error {
+	file, err := a.FileRepository.Get(ctx, req.FileID)
+	if err != nil {
+		return err
+	}
+	if file.DeletedAt != nil || file.ConfirmedAt == nil {
+		return cher.New("file_not_found", cher.M{"file_id": file.ID})
+	}
+	if !strings.HasPrefix(file.MIMEType, "audio/") {
+		return cher.New("file_not_audio", cher.M{
+			"file_id":   file.ID,
+			"mime_type": file.MIMEType,
+		})
+	}
+
+	return a.FileRepository.UpdateTranscript(ctx, file.ID, &models.FileTranscript{
+		Text:       req.Text,
+		ProviderID: req.ProviderID,
+		ModelID:    req.ModelID,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func mapFileTranscript(transcript *models.FileTranscript) *fileupload.FileTranscript {
+	if transcript == nil {
+		return nil
+	}
+
+	return &fileupload.FileTranscript{
+		Text:       transcript.Text,
+		ProviderID: transcript.ProviderID,
+		ModelID:    transcript.ModelID,
+		CreatedAt:  transcript.CreatedAt,
+	}
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+)
+
+// sniffLength is how much of a file http.DetectContentType looks at.
+const sniffLength = 512
+
+// sniffedMIMETypeAliases maps the MIME types http.DetectContentType detects to
+// other MIME types files with the same content are commonly declared as.
+var sniffedMIMETypeAliases = map[string][]string{
+	"application/ogg":    {"audio/ogg", "video/ogg", "audio/opus"},
+	"application/x-gzip": {"application/gzip"},
+	"application/zip": {
+		"application/x-zip-compressed",
+		"application/epub+zip",
+		"application/java-archive",
+		"application/vnd.openxmlformats-officedocument.*",
+		"application/vnd.oasis.opendocument.*",
+	},
+	"audio/mpeg":   {"audio/mp3"},
+	"audio/wave":   {"audio/wav", "audio/x-wav", "audio/vnd.wave"},
+	"image/jpeg":   {"image/jpg", "image/pjpeg"},
+	"image/x-icon": {"image/vnd.microsoft.icon"},
+	"video/avi":    {"video/x-msvideo"},
+	"video/mp4":    {"audio/mp4", "audio/x-m4a", "audio/m4a"},
+	"video/webm":   {"audio/webm"},
+}
+
+// signedMIMETypes are the MIME types whose content always starts with a
+// signature http.DetectContentType recognises. Only files declared as one of
+// these are checked, as other types can't be reliably told apart by their
+// content.
+var signedMIMETypes = []string{
+	"application/pdf",
+	"application/x-gzip",
+	"application/zip",
+	"image/bmp",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+}
+
+func (a *App) uploadPolicy(owner *models.Actor) *models.UploadPolicy {
+	if policy, ok := a.OwnerUploadPolicies[*owner]; ok {
+		return policy
+	}
+
+	return a.DefaultUploadPolicy
+}
+
+// ensureUploadAllowed checks a file can be uploaded by its owner, either in a
+// single request or in parts.
+func (a *App) ensureUploadAllowed(owner *models.Actor, size int64, mimeType string, multipart bool) error {
+	policy := a.uploadPolicy(owner)
+	if policy == nil {
+		return nil
+	}
+
+	maxSizeBytes := policy.MaxSizeBytes
+	if multipart {
+		maxSizeBytes = policy.MultipartMaxSizeBytes
+	}
+	if maxSizeBytes > 0 && size > maxSizeBytes {
+		return cher.New("file_too_large", cher.M{
+			"size":           size,
+			"max_size_bytes": maxSizeBytes,
+		})
+	}
+
+	if len(policy.AllowedMIMETypes) > 0 && !slices.ContainsFunc(policy.AllowedMIMETypes, func(pattern string) bool {
+		return mimeTypeMatches(pattern, mimeType)
+	}) {
+		return cher.New("mime_type_not_allowed", cher.M{
+			"mime_type":          mimeType,
+			"allowed_mime_types": policy.AllowedMIMETypes,
+		})
+	}
+
+	return nil
+}
+
+// inspectObject reads a file's uploaded content, to find its SHA-256 digest
+// and the MIME type its content looks like.
+func (a *App) inspectObject(ctx context.Context, fileID string) (string, string, error) {
+	object, err := a.FileObjectService.OpenObject(ctx, fileID)
+	if err != nil {
+		return "", "", err
+	}
+	defer object.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(object, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", "", err
+	}
+	head = head[:n]
+
+	hash := sha256.New()
+	hash.Write(head)
+	if _, err := io.Copy(hash, object); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), http.DetectContentType(head), nil
+}
+
+// ensureContentMatches checks a file's content, as detected by
+// http.DetectContentType, could be of the MIME type it was declared as.
+func ensureContentMatches(declaredMIMEType, detectedMIMEType string) error {
+	detected, _, err := mime.ParseMediaType(detectedMIMEType)
+	if err != nil {
+		detected = detectedMIMEType
+	}
+
+	if !contentMatches(declaredMIMEType, detected) {
+		return cher.New("file_content_mismatch", cher.M{
+			"declared_mime_type": declaredMIMEType,
+			"detected_mime_type": detected,
+		})
+	}
+
+	return nil
+}
+
+func contentMatches(declared, detected string) bool {
+	// Files of an unknown type can contain anything
+	if declared == "application/octet-stream" {
+		return true
+	}
+
+	sniffed := sniffedMIMEType(declared)
+	if detected == sniffed {
+		return true
+	}
+
+	return !slices.Contains(signedMIMETypes, sniffed)
+}
+
+// sniffedMIMEType returns the MIME type http.DetectContentType detects for
+// files of the given MIME type.
+func sniffedMIMEType(mimeType string) string {
+	for sniffed, aliases := range sniffedMIMETypeAliases {
+		for _, alias := range aliases {
+			if mimeTypeMatches(alias, mimeType) {
+				return sniffed
+			}
+		}
+	}
+
+	return mimeType
+}
+
+// mimeTypeMatches checks if a MIME type matches a pattern, which can end with
+// a wildcard such as "image/*".
+func mimeTypeMatches(pattern, mimeType string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(mimeType, prefix)
+	}
+
+	return pattern == mimeType
+}
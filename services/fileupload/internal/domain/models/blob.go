@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Blob is an object in storage. Files with the same owner and content share a
+// blob, rather than each storing a copy of it.
+type Blob struct {
+	ID     string  `json:"id"`
+	Owner  *Actor  `json:"owner"`
+	SHA256 *string `json:"sha256"`
+	Size   int64   `json:"size"`
+
+	// FileIDs are the files which reference the blob. Once it's empty the
+	// blob's object can be deleted.
+	FileIDs []string `json:"file_ids"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type CreateBlobCommand struct {
+	// ID is the ID of the object the blob's content was uploaded to.
+	ID     string
+	Owner  *Actor
+	SHA256 string
+	Size   int64
+	FileID string
+}
@@ -9,20 +9,100 @@ type File struct {
 	MIMEType string `json:"mime_type"`
 	Owner    *Actor `json:"owner"`
 
+	// SHA256 is the hex encoded digest of the file's content, which is set
+	// when the upload is confirmed.
+	SHA256 *string `json:"sha256"`
+	// BlobID is the blob the file's content is stored in, which is set when
+	// the upload is confirmed. Files with the same content share a blob.
+	BlobID *string `json:"blob_id"`
+	// Transcript is the text of an audio file, once it's been transcribed.
+	Transcript *FileTranscript `json:"transcript"`
+
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
 	ConfirmedAt *time.Time `json:"confirmed_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
+
+	// UploadExpiresAt is when the latest upload URL given out for the file
+	// expires. Files which haven't been confirmed by then never will be.
+	UploadExpiresAt *time.Time `json:"upload_expires_at"`
+
+	// MultipartUpload is set for files which are uploaded in parts.
+	MultipartUpload *MultipartUpload `json:"multipart_upload"`
+}
+
+// MultipartUpload tracks a file which is uploaded in parts. Every part is
+// PartSize bytes, other than the last which can be smaller.
+type MultipartUpload struct {
+	UploadID  string `json:"upload_id"`
+	PartSize  int64  `json:"part_size"`
+	PartCount int    `json:"part_count"`
+
+	// UploadedParts are the parts which had been uploaded when the upload's
+	// progress was last checked.
+	UploadedParts []*UploadedPart `json:"uploaded_parts"`
+
+	CreatedAt time.Time `json:"created_at"`
+	// CompletedAt is when the parts were joined into the file's object. The
+	// file still has to be confirmed after that.
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+type UploadedPart struct {
+	PartNumber int    `json:"part_number"`
+	Size       int64  `json:"size"`
+	ETag       string `json:"etag"`
+}
+
+type FileTranscript struct {
+	Text       string    `json:"text"`
+	ProviderID string    `json:"provider_id"`
+	ModelID    string    `json:"model_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ObjectID returns the ID of the object the file's content is stored in.
+// Until the file is confirmed its content is stored under its own ID.
+func (f *File) ObjectID() string {
+	if f.BlobID != nil {
+		return *f.BlobID
+	}
+
+	return f.ID
 }
 
 type CreateUploadCommand struct {
-	Name     string
-	Size     int64
-	MIMEType string
-	Owner    *CreateUploadCommandActor
+	IdempotencyKey string
+	Name           string
+	Size           int64
+	MIMEType       string
+	Owner          *CreateUploadCommandActor
+
+	UploadExpiresAt time.Time
 }
 
 type CreateUploadCommandActor struct {
 	Type       ActorType
 	Identifier string
 }
+
+type ListFilesByOwnerQuery struct {
+	Owner     *Actor
+	MIMETypes []string
+	Cursor    *string
+	Limit     int
+}
+
+// UploadPolicy limits which files an owner can upload.
+type UploadPolicy struct {
+	// MaxSizeBytes is the largest file which can be uploaded, or 0 for no
+	// limit.
+	MaxSizeBytes int64
+	// MultipartMaxSizeBytes replaces MaxSizeBytes for files uploaded in
+	// parts, which can be much larger than a single request.
+	MultipartMaxSizeBytes int64
+	// AllowedMIMETypes are the MIME types which can be uploaded. Types can end
+	// with a wildcard, such as "image/*". When empty, files of any type can be
+	// uploaded.
+	AllowedMIMETypes []string
+}
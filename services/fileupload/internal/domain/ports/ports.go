@@ -2,21 +2,82 @@ package ports
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
 )
 
 type FileRepository interface {
+	// CreateUpload is idempotent, retrying it returns the original file's ID
+	// and pushes back when its upload expires.
 	CreateUpload(ctx context.Context, req *models.CreateUploadCommand) (string, error)
-	ConfirmUpload(ctx context.Context, fileID string) (*models.File, error)
+	// ConfirmUpload marks a file as uploaded, and stores the digest of its
+	// content and the blob it's stored in.
+	ConfirmUpload(ctx context.Context, fileID, sha256, blobID string) (*models.File, error)
 	Get(ctx context.Context, fileID string) (*models.File, error)
 	GetMany(ctx context.Context, ids []string) ([]*models.File, error)
+	// ListByOwner lists confirmed files which haven't been deleted, newest
+	// first. Only files created before the query's cursor are listed.
+	ListByOwner(ctx context.Context, query *models.ListFilesByOwnerQuery) ([]*models.File, error)
+	DeleteMany(ctx context.Context, ids []string) error
+	// UpdateTranscript stores the transcript of a confirmed file which hasn't
+	// been deleted.
+	UpdateTranscript(ctx context.Context, fileID string, transcript *models.FileTranscript) error
+	// ListExpiredUploads lists up to limit files which were never confirmed,
+	// and whose upload expired before expiredBefore.
+	ListExpiredUploads(ctx context.Context, expiredBefore time.Time, limit int) ([]*models.File, error)
+	// DeleteUnconfirmedUpload deletes a file as long as it still hasn't been
+	// confirmed, and returns whether it was deleted.
+	DeleteUnconfirmedUpload(ctx context.Context, fileID string) (bool, error)
+	// ExtendUploadExpiry pushes back when a file's upload expires, it never
+	// brings it forward.
+	ExtendUploadExpiry(ctx context.Context, fileID string, expiresAt time.Time) error
+
+	// StartMultipartUpload sets the multipart upload a file is uploaded with,
+	// unless it already has one. It returns the file with whichever multipart
+	// upload it has.
+	StartMultipartUpload(ctx context.Context, fileID string, upload *models.MultipartUpload) (*models.File, error)
+	UpdateMultipartUploadProgress(ctx context.Context, fileID string, parts []*models.UploadedPart) error
+	CompleteMultipartUpload(ctx context.Context, fileID string) error
+}
+
+type BlobRepository interface {
+	// Create stores a blob referenced by a single file. Retrying it doesn't
+	// create another blob.
+	Create(ctx context.Context, cmd *models.CreateBlobCommand) error
+	// Reference adds a file to the references of a blob with the same owner
+	// and content, and returns the blob. It returns nil if there's no such
+	// blob. Adding a file which already references the blob does nothing.
+	Reference(ctx context.Context, owner *models.Actor, sha256 string, size int64, fileID string) (*models.Blob, error)
+	// Release removes a file's reference to a blob, and returns whether the
+	// blob is no longer referenced so its object should be deleted.
+	Release(ctx context.Context, blobID, fileID string) (bool, error)
 }
 
 type FileObjectService interface {
-	CreatePresignedUploadURL(ctx context.Context, fileID string) (string, error)
+	CreatePresignedUploadURL(ctx context.Context, fileID string, expiry time.Duration) (string, error)
 	CreatePresignedDownloadURL(ctx context.Context, fileID string, expiry time.Duration) (string, error)
 
 	EnsureFileUploadValidity(ctx context.Context, fileID, mimeType string, size int) error
+	// OpenObject reads a file's object. It returns a file_not_uploaded error
+	// if nothing has been uploaded for the file.
+	OpenObject(ctx context.Context, fileID string) (io.ReadCloser, error)
+	// DeleteObject removes a file's object, it's a no-op if the object doesn't
+	// exist.
+	DeleteObject(ctx context.Context, fileID string) error
+
+	// CreateMultipartUpload starts uploading a file's object in partCount
+	// parts, and returns the ID of the upload.
+	CreateMultipartUpload(ctx context.Context, fileID, mimeType string, partCount int) (string, error)
+	CreatePresignedUploadPartURL(ctx context.Context, fileID, uploadID string, partNumber int, expiry time.Duration) (string, error)
+	// ListUploadedParts lists the parts of a multipart upload which have been
+	// uploaded, ordered by part number.
+	ListUploadedParts(ctx context.Context, fileID, uploadID string) ([]*models.UploadedPart, error)
+	// CompleteMultipartUpload joins the parts of a multipart upload into the
+	// file's object.
+	CompleteMultipartUpload(ctx context.Context, fileID, uploadID string, parts []*models.UploadedPart) error
+	// AbortMultipartUpload discards a multipart upload and any parts which
+	// have been uploaded. It's a no-op if the upload doesn't exist.
+	AbortMultipartUpload(ctx context.Context, fileID, uploadID string) error
 }
@@ -2,7 +2,12 @@ package internal
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -13,6 +18,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/app"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/app/repositories"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/app/services"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/domain/ports"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/transport/janitor"
 	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/transport/rpc"
 )
 
@@ -22,11 +30,69 @@ type Config struct {
 	Logging   clog.Config      `env:"LOGGING"`
 	Mongo     config.MongoDB   `env:"MONGO"`
 
-	Minio MinioConfig `env:"MINIO"`
+	Storage StorageConfig `env:"STORAGE"`
+	Minio   MinioConfig   `env:"MINIO"`
 
 	FilesBucket string `env:"FILES_BUCKET"`
+
+	Janitor JanitorConfig `env:"JANITOR"`
+	Uploads UploadsConfig `env:"UPLOADS"`
+}
+
+// UploadsConfig limits which files can be uploaded.
+type UploadsConfig struct {
+	// MaxSizeBytes is the largest file which can be uploaded, or 0 for no
+	// limit.
+	MaxSizeBytes int `env:"MAX_SIZE_BYTES"`
+	// MultipartMaxSizeBytes is the largest file which can be uploaded in
+	// parts, or 0 for no limit.
+	MultipartMaxSizeBytes int `env:"MULTIPART_MAX_SIZE_BYTES"`
+	// AllowedMIMETypes is a comma separated list of MIME types which can be
+	// uploaded. Types can end with a wildcard, such as "image/*". When empty,
+	// files of any type can be uploaded.
+	AllowedMIMETypes string `env:"ALLOWED_MIME_TYPES"`
+	// OwnerPolicies is a JSON array of limits for specific owners, which
+	// replace the limits above when set.
+	OwnerPolicies string `env:"OWNER_POLICIES"`
+}
+
+type ownerUploadPolicy struct {
+	Owner                 models.Actor `json:"owner"`
+	MaxSizeBytes          *int64       `json:"max_size_bytes"`
+	MultipartMaxSizeBytes *int64       `json:"multipart_max_size_bytes"`
+	AllowedMIMETypes      []string     `json:"allowed_mime_types"`
 }
 
+// JanitorConfig configures how often uploads which were never confirmed are
+// purged.
+type JanitorConfig struct {
+	IntervalSeconds int `env:"INTERVAL_SECONDS"`
+}
+
+// uploadURLExpiry is how long clients have to upload a file once they've been
+// given its upload URL.
+const uploadURLExpiry = 15 * time.Minute
+
+// StorageConfig picks where file content is stored. The minio backend stores
+// files in MinIO, which serves the upload and download URLs itself. The local
+// and memory backends store files on the local filesystem or in memory, and
+// this service serves their URLs from PublicURL.
+type StorageConfig struct {
+	Backend   string `env:"BACKEND"`
+	LocalRoot string `env:"LOCAL_ROOT"`
+	PublicURL string `env:"PUBLIC_URL"`
+
+	// SigningSecret signs the local and memory backends' URLs. When empty a
+	// random secret is used, so URLs stop working when the service restarts.
+	SigningSecret string `env:"SIGNING_SECRET"`
+}
+
+const (
+	storageBackendMinio  = "minio"
+	storageBackendLocal  = "local"
+	storageBackendMemory = "memory"
+)
+
 type MinioConfig struct {
 	Endpoint        string `env:"ENDPOINT"`
 	AccessKeyID     string `env:"ACCESS_KEY_ID"`
@@ -54,6 +120,12 @@ func defaultConfig() Config {
 			DatabaseName: "bloefish_svc_file_upload",
 		},
 
+		Storage: StorageConfig{
+			Backend:   storageBackendMinio,
+			LocalRoot: "./data/files",
+			PublicURL: "http://svc_file_upload.bloefish.local:4005",
+		},
+
 		Minio: MinioConfig{
 			Endpoint:        "localhost:9000",
 			AccessKeyID:     "minio_key",
@@ -62,6 +134,15 @@ func defaultConfig() Config {
 		},
 
 		FilesBucket: "bloefish-svc-files",
+
+		Janitor: JanitorConfig{
+			IntervalSeconds: 300,
+		},
+
+		Uploads: UploadsConfig{
+			MaxSizeBytes:          100 * 1024 * 1024,
+			MultipartMaxSizeBytes: 5 * 1024 * 1024 * 1024,
+		},
 	}
 }
 
@@ -79,25 +160,146 @@ func Run(ctx context.Context) error {
 	ctx = clog.Set(ctx, cfg.Logging.Configure(ctx))
 	_, mongoDatabase := cfg.Mongo.MustConnect(ctx)
 
-	minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAccessKey, ""),
-		Secure: cfg.Minio.UseSSL,
-	})
+	if err := repositories.EnsureIndexes(ctx, mongoDatabase); err != nil {
+		return err
+	}
+	if err := repositories.Migrate(ctx, mongoDatabase, uploadURLExpiry); err != nil {
+		return err
+	}
+
+	fileObjectService, objectsHandler, err := newFileObjectService(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	if err := ensureBucketExists(ctx, minioClient, cfg.FilesBucket); err != nil {
-		return fmt.Errorf("failed to ensure bucket exists: %w", err)
+
+	defaultUploadPolicy, ownerUploadPolicies, err := newUploadPolicies(cfg.Uploads)
+	if err != nil {
+		return err
 	}
 
 	app := &app.App{
 		FileRepository:    repositories.NewMgoFile(mongoDatabase),
-		FileObjectService: services.NewMinioFileObject(minioClient, cfg.FilesBucket),
+		BlobRepository:    repositories.NewMgoBlob(mongoDatabase),
+		FileObjectService: fileObjectService,
+
+		UploadURLExpiry: uploadURLExpiry,
+
+		DefaultUploadPolicy: defaultUploadPolicy,
+		OwnerUploadPolicies: ownerUploadPolicies,
+	}
+
+	rpc := rpc.New(ctx, app, objectsHandler)
+	janitor := janitor.New(ctx, app, time.Duration(cfg.Janitor.IntervalSeconds)*time.Second)
+
+	janitorCtx, stopJanitor := context.WithCancel(ctx)
+	janitorDone := make(chan struct{})
+	go func() {
+		defer close(janitorDone)
+
+		janitor.Run(janitorCtx)
+	}()
+
+	err = rpc.Run(ctx, cfg.Server)
+
+	stopJanitor()
+	<-janitorDone
+
+	return err
+}
+
+// newFileObjectService creates the configured storage backend. Backends which
+// serve their own upload and download URLs also return the handler to serve
+// them with.
+func newFileObjectService(ctx context.Context, cfg Config) (ports.FileObjectService, http.Handler, error) {
+	switch cfg.Storage.Backend {
+	case storageBackendMinio:
+		minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.Minio.AccessKeyID, cfg.Minio.SecretAccessKey, ""),
+			Secure: cfg.Minio.UseSSL,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := ensureBucketExists(ctx, minioClient, cfg.FilesBucket); err != nil {
+			return nil, nil, fmt.Errorf("failed to ensure bucket exists: %w", err)
+		}
+
+		return services.NewMinioFileObject(minioClient, cfg.FilesBucket), nil, nil
+
+	case storageBackendLocal, storageBackendMemory:
+		secret := []byte(cfg.Storage.SigningSecret)
+		if len(secret) == 0 {
+			clog.Get(ctx).Warn("no storage signing secret set, using a random one")
+
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if cfg.Storage.Backend == storageBackendMemory {
+			fileObject := services.NewMemoryFileObject(cfg.Storage.PublicURL, secret)
+			return fileObject, fileObject, nil
+		}
+
+		fileObject, err := services.NewLocalFileObject(cfg.Storage.LocalRoot, cfg.Storage.PublicURL, secret)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return fileObject, fileObject, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown storage backend: %s", cfg.Storage.Backend)
+}
+
+// newUploadPolicies creates the default upload policy, and the policies for
+// specific owners. Owner policies inherit any limits they don't set from the
+// default policy.
+func newUploadPolicies(cfg UploadsConfig) (*models.UploadPolicy, map[models.Actor]*models.UploadPolicy, error) {
+	defaultPolicy := &models.UploadPolicy{
+		MaxSizeBytes:          int64(cfg.MaxSizeBytes),
+		MultipartMaxSizeBytes: int64(cfg.MultipartMaxSizeBytes),
+		AllowedMIMETypes:      splitList(cfg.AllowedMIMETypes),
+	}
+
+	ownerPolicies := make(map[models.Actor]*models.UploadPolicy)
+	if cfg.OwnerPolicies == "" {
+		return defaultPolicy, ownerPolicies, nil
 	}
 
-	rpc := rpc.New(ctx, app)
+	var policies []ownerUploadPolicy
+	if err := json.Unmarshal([]byte(cfg.OwnerPolicies), &policies); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse owner upload policies: %w", err)
+	}
+
+	for _, p := range policies {
+		policy := *defaultPolicy
+		if p.MaxSizeBytes != nil {
+			policy.MaxSizeBytes = *p.MaxSizeBytes
+		}
+		if p.MultipartMaxSizeBytes != nil {
+			policy.MultipartMaxSizeBytes = *p.MultipartMaxSizeBytes
+		}
+		if p.AllowedMIMETypes != nil {
+			policy.AllowedMIMETypes = p.AllowedMIMETypes
+		}
+
+		ownerPolicies[p.Owner] = &policy
+	}
+
+	return defaultPolicy, ownerPolicies, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
 
-	return rpc.Run(ctx, cfg.Server)
+	return items
 }
 
 func ensureBucketExists(ctx context.Context, client *minio.Client, bucketName string) error {
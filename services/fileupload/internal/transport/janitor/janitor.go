@@ -0,0 +1,45 @@
+package janitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/fileupload/internal/app"
+)
+
+// Janitor periodically cleans up after uploads which were never finished.
+type Janitor struct {
+	app *app.App
+
+	interval time.Duration
+}
+
+func New(ctx context.Context, app *app.App, interval time.Duration) *Janitor {
+	return &Janitor{
+		app: app,
+
+		interval: interval,
+	}
+}
+
+// Run purges expired uploads straight away, and then on every interval until
+// the context is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	clog.Get(ctx).WithField("interval", j.interval).Info("running janitor")
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.app.PurgeExpiredUploads(ctx); err != nil && ctx.Err() == nil {
+			clog.Get(ctx).WithError(err).Error("failed to purge expired uploads")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
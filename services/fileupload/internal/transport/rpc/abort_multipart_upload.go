@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) AbortMultipartUpload(ctx context.Context, req *fileupload.AbortMultipartUploadRequest) error {
+	return r.app.AbortMultipartUpload(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) CompleteMultipartUpload(ctx context.Context, req *fileupload.CompleteMultipartUploadRequest) error {
+	return r.app.CompleteMultipartUpload(ctx, req)
+}
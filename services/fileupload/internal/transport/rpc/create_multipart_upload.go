@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) CreateMultipartUpload(ctx context.Context, req *fileupload.CreateMultipartUploadRequest) (*fileupload.CreateMultipartUploadResponse, error) {
+	return r.app.CreateMultipartUpload(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) CreateUploadPartURLs(ctx context.Context, req *fileupload.CreateUploadPartURLsRequest) (*fileupload.CreateUploadPartURLsResponse, error) {
+	return r.app.CreateUploadPartURLs(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) DeleteFiles(ctx context.Context, req *fileupload.DeleteFilesRequest) error {
+	return r.app.DeleteFiles(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) GetMultipartUploadProgress(ctx context.Context, req *fileupload.GetMultipartUploadProgressRequest) (*fileupload.GetMultipartUploadProgressResponse, error) {
+	return r.app.GetMultipartUploadProgress(ctx, req)
+}
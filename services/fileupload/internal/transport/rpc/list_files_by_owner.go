@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) ListFilesByOwner(ctx context.Context, req *fileupload.ListFilesByOwnerRequest) (*fileupload.ListFilesByOwnerResponse, error) {
+	return r.app.ListFilesByOwner(ctx, req)
+}
@@ -34,7 +34,10 @@ type RPC struct {
 	httpServer *http.Server
 }
 
-func New(ctx context.Context, app *app.App) *RPC {
+// New creates the RPC transport. If objectsHandler is not nil, it's used to
+// serve upload and download URLs for storage backends which don't serve their
+// own.
+func New(ctx context.Context, app *app.App, objectsHandler http.Handler) *RPC {
 	rpc := &RPC{app: app}
 
 	svcInfo := contexts.GetServiceInfo(ctx)
@@ -43,18 +46,27 @@ func New(ctx context.Context, app *app.App) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("create_upload", "2025-02-12", schema("create_upload"), rpc.CreateUpload)
 	svr.Register("confirm_upload", "2025-02-12", schema("confirm_upload"), rpc.ConfirmUpload)
 	svr.Register("get_file", "2025-02-12", schema("get_file"), rpc.GetFile)
 	svr.Register("get_many_files", "2025-02-12", schema("get_many_files"), rpc.GetManyFiles)
+	svr.Register("list_files_by_owner", "2025-02-12", schema("list_files_by_owner"), rpc.ListFilesByOwner)
+	svr.Register("delete_files", "2025-02-12", schema("delete_files"), rpc.DeleteFiles)
+	svr.Register("update_file_transcript", "2025-02-12", schema("update_file_transcript"), rpc.UpdateFileTranscript)
+	svr.Register("create_multipart_upload", "2025-02-12", schema("create_multipart_upload"), rpc.CreateMultipartUpload)
+	svr.Register("create_upload_part_urls", "2025-02-12", schema("create_upload_part_urls"), rpc.CreateUploadPartURLs)
+	svr.Register("get_multipart_upload_progress", "2025-02-12", schema("get_multipart_upload_progress"), rpc.GetMultipartUploadProgress)
+	svr.Register("complete_multipart_upload", "2025-02-12", schema("complete_multipart_upload"), rpc.CompleteMultipartUpload)
+	svr.Register("abort_multipart_upload", "2025-02-12", schema("abort_multipart_upload"), rpc.AbortMultipartUpload)
 
 	mux := chi.NewRouter()
 	mux.Use(version.HeaderMiddleware(svcInfo.ServiceHTTPName))
 	mux.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
@@ -70,6 +82,15 @@ func New(ctx context.Context, app *app.App) *RPC {
 		).
 		Handle("/rpc/*", svr)
 
+	if objectsHandler != nil {
+		mux.
+			With(
+				middlewares.RequestID,
+				middlewares.Telemetry(clog.Get(ctx)),
+			).
+			Handle("/objects/*", objectsHandler)
+	}
+
 	rpc.httpServer = &http.Server{
 		Handler: mux,
 		BaseContext: func(net.Listener) context.Context {
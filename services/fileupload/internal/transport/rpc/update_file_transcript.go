@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/fileupload"
+)
+
+func (r *RPC) UpdateFileTranscript(ctx context.Context, req *fileupload.UpdateFileTranscriptRequest) error {
+	return r.app.UpdateFileTranscript(ctx, req)
+}
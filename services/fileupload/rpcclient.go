@@ -1,3 +1,5 @@
+// Code generated by crpcgen. DO NOT EDIT.
+
 package fileupload
 
 import (
@@ -7,6 +9,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/crpc"
 )
 
+// Ensure RPCClient implements Service.
+var _ Service = (*RPCClient)(nil)
+
 type RPCClient struct {
 	client *crpc.Client
 }
@@ -32,3 +37,35 @@ func (r *RPCClient) GetFile(ctx context.Context, req *GetFileRequest) (resp *Get
 func (r *RPCClient) GetManyFiles(ctx context.Context, req *GetManyFilesRequest) (resp *GetManyFilesResponse, err error) {
 	return resp, r.client.Do(ctx, "get_many_files", "2025-02-12", req, &resp)
 }
+
+func (r *RPCClient) ListFilesByOwner(ctx context.Context, req *ListFilesByOwnerRequest) (resp *ListFilesByOwnerResponse, err error) {
+	return resp, r.client.Do(ctx, "list_files_by_owner", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) DeleteFiles(ctx context.Context, req *DeleteFilesRequest) error {
+	return r.client.Do(ctx, "delete_files", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) UpdateFileTranscript(ctx context.Context, req *UpdateFileTranscriptRequest) error {
+	return r.client.Do(ctx, "update_file_transcript", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) CreateMultipartUpload(ctx context.Context, req *CreateMultipartUploadRequest) (resp *CreateMultipartUploadResponse, err error) {
+	return resp, r.client.Do(ctx, "create_multipart_upload", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) CreateUploadPartURLs(ctx context.Context, req *CreateUploadPartURLsRequest) (resp *CreateUploadPartURLsResponse, err error) {
+	return resp, r.client.Do(ctx, "create_upload_part_urls", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) GetMultipartUploadProgress(ctx context.Context, req *GetMultipartUploadProgressRequest) (resp *GetMultipartUploadProgressResponse, err error) {
+	return resp, r.client.Do(ctx, "get_multipart_upload_progress", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) CompleteMultipartUpload(ctx context.Context, req *CompleteMultipartUploadRequest) error {
+	return r.client.Do(ctx, "complete_multipart_upload", "2025-02-12", req, nil)
+}
+
+func (r *RPCClient) AbortMultipartUpload(ctx context.Context, req *AbortMultipartUploadRequest) error {
+	return r.client.Do(ctx, "abort_multipart_upload", "2025-02-12", req, nil)
+}
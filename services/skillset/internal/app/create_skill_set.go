@@ -9,10 +9,11 @@ import (
 
 func (a *App) CreateSkillSet(ctx context.Context, req *skillset.CreateSkillSetRequest) error {
 	if _, err := a.SkillSetRepository.CreateSkillSet(ctx, models.CreateSkillSetCommand{
-		Name:        req.Name,
-		Icon:        req.Icon,
-		Description: req.Description,
-		Prompt:      req.Prompt,
+		IdempotencyKey: req.IdempotencyKey,
+		Name:           req.Name,
+		Icon:           req.Icon,
+		Description:    req.Description,
+		Prompt:         req.Prompt,
 		Owner: &models.CreateSkillSetCommandActor{
 			Type:       models.ActorType(req.Owner.Type),
 			Identifier: req.Owner.Identifier,
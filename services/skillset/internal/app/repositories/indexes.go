@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the repositories rely on. Creating an index
+// which already exists is a no-op, so this is run on every startup.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		"skill_sets": {
+			{
+				Keys: bson.D{
+					{Key: "owner.type", Value: 1},
+					{Key: "owner.identifier", Value: 1},
+					{Key: "idempotency_key", Value: 1},
+				},
+				// Skill sets created before idempotency keys were added don't
+				// have one
+				Options: options.Index().
+					SetName("idempotency_key").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$type": "string"}}),
+			},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create %s indexes: %w", collection, err)
+		}
+	}
+
+	return nil
+}
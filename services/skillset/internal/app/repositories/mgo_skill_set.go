@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
 	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
@@ -15,11 +16,12 @@ import (
 )
 
 type persistedSkillSet struct {
-	ID          string `bson:"_id"`
-	Name        string `bson:"name"`
-	Icon        string `bson:"icon"`
-	Description string `bson:"description"`
-	Prompt      string `bson:"prompt"`
+	ID             string `bson:"_id"`
+	IdempotencyKey string `bson:"idempotency_key"`
+	Name           string `bson:"name"`
+	Icon           string `bson:"icon"`
+	Description    string `bson:"description"`
+	Prompt         string `bson:"prompt"`
 
 	Owner struct {
 		Type       string `bson:"type"`
@@ -40,34 +42,44 @@ func NewMgoSkillSet(db *mongo.Database) ports.SkillSetRepository {
 }
 
 func (m *mgoSkillSet) CreateSkillSet(ctx context.Context, req models.CreateSkillSetCommand) (string, error) {
-	id := ksuid.Generate(ctx, "skillset").String()
-
-	result, err := m.c.InsertOne(ctx, bson.M{
-		"_id":         id,
-		"name":        req.Name,
-		"icon":        req.Icon,
-		"description": req.Description,
-		"prompt":      req.Prompt,
-
-		"owner": bson.M{
-			"type":       req.Owner.Type,
-			"identifier": req.Owner.Identifier,
+	filter := bson.M{
+		"idempotency_key":  req.IdempotencyKey,
+		"owner.type":       req.Owner.Type,
+		"owner.identifier": req.Owner.Identifier,
+	}
+
+	result := m.c.FindOneAndUpdate(ctx, filter, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":             ksuid.Generate(ctx, "skillset").String(),
+			"idempotency_key": req.IdempotencyKey,
+			"name":            req.Name,
+			"icon":            req.Icon,
+			"description":     req.Description,
+			"prompt":          req.Prompt,
+
+			"owner": bson.M{
+				"type":       req.Owner.Type,
+				"identifier": req.Owner.Identifier,
+			},
+
+			"created_at": time.Now(),
+			"updated_at": nil,
+			"deleted_at": nil,
 		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
 
-		"created_at": time.Now(),
-		"updated_at": nil,
-		"deleted_at": nil,
-	})
+	var p persistedSkillSet
+	err := result.Decode(&p)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request with the same idempotency key won the race to
+		// insert, so return its skill set
+		err = m.c.FindOne(ctx, filter).Decode(&p)
+	}
 	if err != nil {
 		return "", err
 	}
 
-	id, ok := result.InsertedID.(string)
-	if !ok {
-		return "", errors.New("failed to cast InsertedID to string")
-	}
-
-	return id, nil
+	return p.ID, nil
 }
 
 func (m *mgoSkillSet) GetSkillSet(ctx context.Context, id string) (*models.SkillSet, error) {
@@ -149,11 +161,12 @@ func (m *mgoSkillSet) GetManySkillSets(ctx context.Context, ids []string) ([]*mo
 
 func (p *persistedSkillSet) ToDomainModel() *models.SkillSet {
 	return &models.SkillSet{
-		ID:          p.ID,
-		Name:        p.Name,
-		Icon:        p.Icon,
-		Description: p.Description,
-		Prompt:      p.Prompt,
+		ID:             p.ID,
+		IdempotencyKey: p.IdempotencyKey,
+		Name:           p.Name,
+		Icon:           p.Icon,
+		Description:    p.Description,
+		Prompt:         p.Prompt,
 
 		Owner: &models.Actor{
 			Type:       models.ActorType(p.Owner.Type),
@@ -3,11 +3,12 @@ package models
 import "time"
 
 type SkillSet struct {
-	ID          string
-	Name        string
-	Icon        string
-	Description string
-	Prompt      string
+	ID             string
+	IdempotencyKey string
+	Name           string
+	Icon           string
+	Description    string
+	Prompt         string
 
 	Owner *Actor
 
@@ -17,11 +18,12 @@ type SkillSet struct {
 }
 
 type CreateSkillSetCommand struct {
-	Name        string
-	Icon        string
-	Description string
-	Prompt      string
-	Owner       *CreateSkillSetCommandActor
+	IdempotencyKey string
+	Name           string
+	Icon           string
+	Description    string
+	Prompt         string
+	Owner          *CreateSkillSetCommandActor
 }
 
 type CreateSkillSetCommandActor struct {
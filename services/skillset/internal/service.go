@@ -54,6 +54,10 @@ func Run(ctx context.Context) error {
 	ctx = clog.Set(ctx, cfg.Logging.Configure(ctx))
 	_, mongoDatabase := cfg.Mongo.MustConnect(ctx)
 
+	if err := repositories.EnsureIndexes(ctx, mongoDatabase); err != nil {
+		return err
+	}
+
 	app := &app.App{
 		SkillSetRepository: repositories.NewMgoSkillSet(mongoDatabase),
 	}
@@ -43,6 +43,7 @@ func New(ctx context.Context, app *app.App) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("create_skill_set", "2025-02-12", schema("create_skill_set"), rpc.CreateSkillSet)
@@ -1,5 +1,7 @@
 package skillset
 
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
 import (
 	"context"
 	"time"
@@ -38,11 +40,12 @@ type Actor struct {
 }
 
 type CreateSkillSetRequest struct {
-	Name        string `json:"name"`
-	Icon        string `json:"icon"`
-	Description string `json:"description"`
-	Prompt      string `json:"prompt"`
-	Owner       *Actor `json:"owner"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Name           string `json:"name"`
+	Icon           string `json:"icon"`
+	Description    string `json:"description"`
+	Prompt         string `json:"prompt"`
+	Owner          *Actor `json:"owner"`
 }
 
 type GetSkillSetRequest struct {
@@ -19,6 +19,14 @@ func (a *App) SendMessageFragment(ctx context.Context, req *stream.SendMessageFr
 	return a.MessageBroker.SendMessageFragment(ctx, req.ChannelID, req.MessageContent)
 }
 
+func (a *App) SendReasoningFragment(ctx context.Context, req *stream.SendReasoningFragmentRequest) error {
+	return a.MessageBroker.SendReasoningFragment(ctx, req.ChannelID, req.ReasoningContent)
+}
+
 func (a *App) SendErrorMessage(ctx context.Context, req *stream.SendErrorMessageRequest) error {
 	return a.MessageBroker.SendErrorMessage(ctx, req.ChannelID, req.Error)
 }
+
+func (a *App) SendMessageReset(ctx context.Context, req *stream.SendMessageResetRequest) error {
+	return a.MessageBroker.SendMessageReset(ctx, req.ChannelID)
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/libraries/ksuid"
+	"github.com/0xdeafcafe/bloefish/libraries/merr"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/domain/ports"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// channelHistoryLength is how many events are kept per channel, so
+	// subscribers can resume from the last event they received.
+	channelHistoryLength = 512
+
+	// channelHistoryTTL is how long a channel's history is kept after the last
+	// message sent on it.
+	channelHistoryTTL = 30 * time.Minute
+
+	// subscriptionBufferLength is how many events a subscriber can fall behind
+	// by before it is dropped.
+	subscriptionBufferLength = 256
+
+	// websocketSendQueueLength is how many messages a websocket connection can
+	// fall behind by before it is closed.
+	websocketSendQueueLength = 256
+
+	// websocketPongWait is how long a websocket connection can go without
+	// responding to a ping before it is considered dead. Pings are sent more
+	// frequently than this, so a healthy connection is never timed out.
+	websocketPongWait   = 60 * time.Second
+	websocketPingPeriod = (websocketPongWait * 9) / 10
+	websocketWriteWait  = 10 * time.Second
+)
+
+type websocketConnection struct {
+	id   string
+	conn *websocket.Conn
+
+	send chan []byte
+	done chan struct{}
+}
+
+type channelHistory struct {
+	events     []*models.StreamEvent
+	lastSentAt time.Time
+}
+
+type subscription struct {
+	channelID string
+	events    chan *models.StreamEvent
+}
+
+type messageBroker struct {
+	connections map[string]*websocketConnection
+
+	// subscriptions and history are keyed by channel ID
+	subscriptions map[string]map[*subscription]struct{}
+	history       map[string]*channelHistory
+	lastEventID   uint64
+	lastPrunedAt  time.Time
+
+	connectionsMu   sync.Mutex
+	subscriptionsMu sync.Mutex
+
+	activeConnections  metric.Int64UpDownCounter
+	openedConnections  metric.Int64Counter
+	evictedConnections metric.Int64Counter
+	droppedWrites      metric.Int64Counter
+	droppedSubscribers metric.Int64Counter
+}
+
+func NewMessageBroker() (ports.MessageBroker, error) {
+	meter := otel.Meter("github.com/0xdeafcafe/bloefish/services/stream")
+
+	activeConnections, err := meter.Int64UpDownCounter(
+		"stream.websocket.connections.active",
+		metric.WithDescription("Number of open websocket connections"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	openedConnections, err := meter.Int64Counter(
+		"stream.websocket.connections.opened",
+		metric.WithDescription("Number of websocket connections opened"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	evictedConnections, err := meter.Int64Counter(
+		"stream.websocket.connections.evicted",
+		metric.WithDescription("Number of websocket connections closed for falling behind"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	droppedWrites, err := meter.Int64Counter(
+		"stream.websocket.writes.dropped",
+		metric.WithDescription("Number of messages which couldn't be written to a websocket connection, by reason"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	droppedSubscribers, err := meter.Int64Counter(
+		"stream.subscriptions.dropped",
+		metric.WithDescription("Number of channel subscribers dropped for falling behind"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messageBroker{
+		connections: make(map[string]*websocketConnection),
+
+		subscriptions: make(map[string]map[*subscription]struct{}),
+		history:       make(map[string]*channelHistory),
+
+		connectionsMu:   sync.Mutex{},
+		subscriptionsMu: sync.Mutex{},
+
+		activeConnections:  activeConnections,
+		openedConnections:  openedConnections,
+		evictedConnections: evictedConnections,
+		droppedWrites:      droppedWrites,
+		droppedSubscribers: droppedSubscribers,
+	}, nil
+}
+
+func (w *messageBroker) RegisterConnection(ctx context.Context, conn *websocket.Conn) func() {
+	c := &websocketConnection{
+		id:   ksuid.Generate(ctx, "wsconn").String(),
+		conn: conn,
+		send: make(chan []byte, websocketSendQueueLength),
+		done: make(chan struct{}),
+	}
+
+	// Each pong extends the read deadline, so connections which stop
+	// responding to pings are eventually closed by the reader
+	if err := conn.SetReadDeadline(time.Now().Add(websocketPongWait)); err != nil {
+		clog.Get(ctx).WithError(err).Warn("unable to set read deadline on websocket connection")
+	}
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(websocketPongWait))
+	})
+
+	w.connectionsMu.Lock()
+	w.connections[c.id] = c
+	w.connectionsMu.Unlock()
+
+	w.activeConnections.Add(ctx, 1)
+	w.openedConnections.Add(ctx, 1)
+
+	go w.writePump(ctx, c)
+
+	return func() {
+		w.connectionsMu.Lock()
+		defer w.connectionsMu.Unlock()
+
+		w.removeConnection(ctx, c)
+	}
+}
+
+// removeConnection must be called with connectionsMu held. The connection is
+// closed by its write pump.
+func (w *messageBroker) removeConnection(ctx context.Context, c *websocketConnection) {
+	if _, ok := w.connections[c.id]; !ok {
+		return
+	}
+
+	delete(w.connections, c.id)
+	close(c.done)
+
+	w.activeConnections.Add(ctx, -1)
+}
+
+// writePump is the only writer to a websocket connection. It sends queued
+// messages and keepalive pings until the connection is removed or a write
+// fails.
+func (w *messageBroker) writePump(ctx context.Context, c *websocketConnection) {
+	// The request context is cancelled once the handler returns, but logging
+	// fields are still wanted
+	ctx = context.WithoutCancel(ctx)
+
+	ticker := time.NewTicker(websocketPingPeriod)
+	defer ticker.Stop()
+	defer c.conn.Close()
+
+	for {
+		var err error
+		var failedMessages int64
+
+		select {
+		case <-c.done:
+			_ = c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(websocketWriteWait),
+			)
+			return
+		case msg := <-c.send:
+			failedMessages = 1
+			if err = c.conn.SetWriteDeadline(time.Now().Add(websocketWriteWait)); err == nil {
+				err = c.conn.WriteMessage(websocket.TextMessage, msg)
+			}
+		case <-ticker.C:
+			if err = c.conn.SetWriteDeadline(time.Now().Add(websocketWriteWait)); err == nil {
+				err = c.conn.WriteMessage(websocket.PingMessage, nil)
+			}
+		}
+
+		if err != nil {
+			clog.Get(ctx).WithError(err).Warn("failed to write message to websocket connection")
+
+			// Messages still queued for the connection are lost with it
+			w.droppedWrites.Add(ctx, failedMessages+int64(len(c.send)), metric.WithAttributes(attribute.String("reason", "write_failed")))
+
+			w.connectionsMu.Lock()
+			w.removeConnection(ctx, c)
+			w.connectionsMu.Unlock()
+
+			return
+		}
+	}
+}
+
+func (w *messageBroker) Subscribe(ctx context.Context, channelID string, lastEventID uint64) *models.Subscription {
+	w.subscriptionsMu.Lock()
+	defer w.subscriptionsMu.Unlock()
+
+	sub := &subscription{
+		channelID: channelID,
+		events:    make(chan *models.StreamEvent, subscriptionBufferLength),
+	}
+
+	if _, ok := w.subscriptions[channelID]; !ok {
+		w.subscriptions[channelID] = make(map[*subscription]struct{})
+	}
+	w.subscriptions[channelID][sub] = struct{}{}
+
+	var missed []*models.StreamEvent
+
+	// IDs from before the service restarted can't be resumed from
+	if history, ok := w.history[channelID]; ok && lastEventID > 0 && lastEventID <= w.lastEventID {
+		for _, event := range history.events {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return &models.Subscription{
+		Missed: missed,
+		Events: sub.events,
+		Unsubscribe: func() {
+			w.subscriptionsMu.Lock()
+			defer w.subscriptionsMu.Unlock()
+
+			w.removeSubscription(sub)
+		},
+	}
+}
+
+// removeSubscription must be called with subscriptionsMu held.
+func (w *messageBroker) removeSubscription(sub *subscription) {
+	subs, ok := w.subscriptions[sub.channelID]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.events)
+
+	if len(subs) == 0 {
+		delete(w.subscriptions, sub.channelID)
+	}
+}
+
+func (w *messageBroker) SendMessageFragment(ctx context.Context, channelID string, messageContent string) error {
+	return w.sendMessage(ctx, channelID, &messageContent, nil, models.StreamMessageTypeMessageFragment)
+}
+
+func (w *messageBroker) SendReasoningFragment(ctx context.Context, channelID string, reasoningContent string) error {
+	return w.sendMessage(ctx, channelID, &reasoningContent, nil, models.StreamMessageTypeReasoningFragment)
+}
+
+func (w *messageBroker) SendMessageFull(ctx context.Context, channelID string, messageContent string) error {
+	return w.sendMessage(ctx, channelID, &messageContent, nil, models.StreamMessageTypeMessageFull)
+}
+
+func (w *messageBroker) SendErrorMessage(ctx context.Context, channelID string, errorMessage cher.E) error {
+	return w.sendMessage(ctx, channelID, nil, &errorMessage, models.StreamMessageTypeError)
+}
+
+func (w *messageBroker) SendMessageReset(ctx context.Context, channelID string) error {
+	return w.sendMessage(ctx, channelID, nil, nil, models.StreamMessageTypeMessageReset)
+}
+
+func (w *messageBroker) sendMessage(ctx context.Context, channelID string, messageContent *string, errorMessage *cher.E, messageType models.StreamMessageType) error {
+	msg := &models.StreamMessage{
+		ChannelID: channelID,
+		Type:      messageType,
+	}
+
+	switch msg.Type {
+	case models.StreamMessageTypeMessageFragment:
+		msg.MessageFragment = messageContent
+	case models.StreamMessageTypeReasoningFragment:
+		msg.ReasoningFragment = messageContent
+	case models.StreamMessageTypeMessageFull:
+		msg.MessageFull = messageContent
+	case models.StreamMessageTypeError:
+		msg.Error = errorMessage
+	case models.StreamMessageTypeMessageReset:
+		// Resets carry nothing but their channel
+	default:
+		return merr.New(ctx, "invalid message type", merr.M{
+			"message_type": messageType,
+		})
+	}
+
+	w.publish(ctx, msg)
+
+	jsonText, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal websocket message: %w", err)
+	}
+
+	w.connectionsMu.Lock()
+	defer w.connectionsMu.Unlock()
+
+	for _, c := range w.connections {
+		select {
+		case c.send <- jsonText:
+		default:
+			clog.Get(ctx).WithField("connection_id", c.id).Warn("closing websocket connection which has fallen behind")
+
+			w.evictedConnections.Add(ctx, 1)
+			w.droppedWrites.Add(ctx, int64(1+len(c.send)), metric.WithAttributes(attribute.String("reason", "connection_behind")))
+			w.removeConnection(ctx, c)
+		}
+	}
+
+	return nil
+}
+
+// publish records the message in the channel's history and sends it to the
+// channel's subscribers. Subscribers which have fallen too far behind are
+// dropped rather than blocking the sender.
+func (w *messageBroker) publish(ctx context.Context, msg *models.StreamMessage) {
+	w.subscriptionsMu.Lock()
+	defer w.subscriptionsMu.Unlock()
+
+	now := time.Now()
+
+	w.lastEventID++
+	event := &models.StreamEvent{
+		ID:      w.lastEventID,
+		Message: msg,
+	}
+
+	history, ok := w.history[msg.ChannelID]
+	if !ok {
+		history = &channelHistory{}
+		w.history[msg.ChannelID] = history
+	}
+
+	history.events = append(history.events, event)
+	if len(history.events) > channelHistoryLength {
+		history.events = history.events[len(history.events)-channelHistoryLength:]
+	}
+	history.lastSentAt = now
+
+	for sub := range w.subscriptions[msg.ChannelID] {
+		select {
+		case sub.events <- event:
+		default:
+			clog.Get(ctx).WithField("channel_id", msg.ChannelID).Warn("dropping subscriber which has fallen behind")
+			w.droppedSubscribers.Add(ctx, 1)
+			w.removeSubscription(sub)
+		}
+	}
+
+	if now.Sub(w.lastPrunedAt) > time.Minute {
+		w.lastPrunedAt = now
+
+		for channelID, history := range w.history {
+			if now.Sub(history.lastSentAt) > channelHistoryTTL {
+				delete(w.history, channelID)
+			}
+		}
+	}
+}
@@ -5,15 +5,37 @@ import "github.com/0xdeafcafe/bloefish/libraries/cher"
 type StreamMessageType string
 
 const (
-	StreamMessageTypeMessageFull     StreamMessageType = "message_full"
-	StreamMessageTypeMessageFragment StreamMessageType = "message_fragment"
-	StreamMessageTypeError           StreamMessageType = "error"
+	StreamMessageTypeMessageFull       StreamMessageType = "message_full"
+	StreamMessageTypeMessageFragment   StreamMessageType = "message_fragment"
+	StreamMessageTypeReasoningFragment StreamMessageType = "reasoning_fragment"
+	StreamMessageTypeError             StreamMessageType = "error"
+	StreamMessageTypeMessageReset      StreamMessageType = "message_reset"
 )
 
 type StreamMessage struct {
-	ChannelID       string            `json:"channel_id"`
-	Type            StreamMessageType `json:"type"`
-	MessageFull     *string           `json:"message_full"`
-	MessageFragment *string           `json:"message_fragment"`
-	Error           *cher.E           `json:"error"`
+	ChannelID         string            `json:"channel_id"`
+	Type              StreamMessageType `json:"type"`
+	MessageFull       *string           `json:"message_full"`
+	MessageFragment   *string           `json:"message_fragment"`
+	ReasoningFragment *string           `json:"reasoning_fragment"`
+	Error             *cher.E           `json:"error"`
+}
+
+// StreamEvent is a message sent on a channel, with an ID which increases with
+// each message sent by the broker.
+type StreamEvent struct {
+	ID      uint64
+	Message *StreamMessage
+}
+
+// Subscription receives the messages sent on a single channel. Events is closed
+// if the subscriber falls too far behind, at which point it should resubscribe
+// with the ID of the last event it received.
+type Subscription struct {
+	// Missed are the events sent on the channel after the last event ID given
+	// when subscribing.
+	Missed []*StreamEvent
+	Events <-chan *StreamEvent
+
+	Unsubscribe func()
 }
@@ -4,12 +4,18 @@ import (
 	"context"
 
 	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/domain/models"
 	"github.com/gorilla/websocket"
 )
 
 type MessageBroker interface {
-	RegisterConnection(ctx context.Context, conn *websocket.Conn)
+	// RegisterConnection starts sending messages to the connection, and returns
+	// a function to remove it once the connection is closed.
+	RegisterConnection(ctx context.Context, conn *websocket.Conn) (unregister func())
+	Subscribe(ctx context.Context, channelID string, lastEventID uint64) *models.Subscription
 	SendMessageFull(ctx context.Context, channelID, messageContent string) error
 	SendMessageFragment(ctx context.Context, channelID, messageContent string) error
+	SendReasoningFragment(ctx context.Context, channelID, reasoningContent string) error
 	SendErrorMessage(ctx context.Context, channelID string, err cher.E) error
+	SendMessageReset(ctx context.Context, channelID string) error
 }
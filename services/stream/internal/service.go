@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/0xdeafcafe/bloefish/libraries/clog"
 	"github.com/0xdeafcafe/bloefish/libraries/config"
@@ -11,6 +12,7 @@ import (
 	"github.com/0xdeafcafe/bloefish/services/stream/internal/app"
 	"github.com/0xdeafcafe/bloefish/services/stream/internal/app/services"
 	"github.com/0xdeafcafe/bloefish/services/stream/internal/transport/rpc"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/transport/sse"
 	"github.com/0xdeafcafe/bloefish/services/stream/internal/transport/ws"
 	"github.com/go-chi/chi/v5"
 	"github.com/pkg/errors"
@@ -18,16 +20,27 @@ import (
 
 type Config struct {
 	Server    config.Server    `env:"SERVER"`
+	WebSocket WebSocketConfig  `env:"WEBSOCKET"`
 	Telemetry telemetry.Config `env:"TELEMETRY"`
 	Logging   clog.Config      `env:"LOGGING"`
 }
 
+type WebSocketConfig struct {
+	// AllowedOrigins is a comma separated list of origins browsers can connect
+	// from, or * to allow any origin.
+	AllowedOrigins string `env:"ALLOWED_ORIGINS"`
+}
+
 func defaultConfig() Config {
 	return Config{
 		Server: config.Server{
 			Addr: ":4005",
 		},
 
+		WebSocket: WebSocketConfig{
+			AllowedOrigins: "http://app.bloefish.local:4169,http://localhost:5173",
+		},
+
 		Telemetry: telemetry.Config{
 			Enable: true,
 		},
@@ -52,13 +65,19 @@ func Run(ctx context.Context) error {
 
 	ctx = clog.Set(ctx, cfg.Logging.Configure(ctx))
 
+	messageBroker, err := services.NewMessageBroker()
+	if err != nil {
+		return errors.Wrap(err, "message broker:")
+	}
+
 	app := &app.App{
-		MessageBroker: services.NewWebSocketMessageBroker(),
+		MessageBroker: messageBroker,
 	}
 
 	mux := chi.NewRouter()
 	_ = rpc.New(ctx, app, mux)
-	_ = ws.New(ctx, app, mux)
+	_ = ws.New(ctx, app, mux, splitList(cfg.WebSocket.AllowedOrigins))
+	_ = sse.New(ctx, app, mux)
 
 	clog.Get(ctx).WithField("addr", cfg.Server.Addr).Info("listening")
 	if err := cfg.Server.ListenAndServe(&http.Server{
@@ -72,3 +91,14 @@ func Run(ctx context.Context) error {
 
 	return nil
 }
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
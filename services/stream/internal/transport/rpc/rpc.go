@@ -37,11 +37,14 @@ func New(ctx context.Context, app *app.App, mux *chi.Mux) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("send_message_full", "2025-02-12", schema("send_message_full"), rpc.SendMessageFull)
 	svr.Register("send_message_fragment", "2025-02-12", schema("send_message_fragment"), rpc.SendMessageFragment)
+	svr.Register("send_reasoning_fragment", "2025-02-12", schema("send_reasoning_fragment"), rpc.SendReasoningFragment)
 	svr.Register("send_error_message", "2025-02-12", schema("send_error_message"), rpc.SendErrorMessage)
+	svr.Register("send_message_reset", "2025-02-12", schema("send_message_reset"), rpc.SendMessageReset)
 
 	mux.Use(version.HeaderMiddleware(svcInfo.ServiceHTTPName))
 	mux.Use(cors.Handler(cors.Options{
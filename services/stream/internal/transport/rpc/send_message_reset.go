@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/stream"
+)
+
+func (r *RPC) SendMessageReset(ctx context.Context, req *stream.SendMessageResetRequest) error {
+	return r.app.SendMessageReset(ctx, req)
+}
@@ -0,0 +1,11 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/stream"
+)
+
+func (r *RPC) SendReasoningFragment(ctx context.Context, req *stream.SendReasoningFragmentRequest) error {
+	return r.app.SendReasoningFragment(ctx, req)
+}
@@ -0,0 +1,118 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0xdeafcafe/bloefish/libraries/cher"
+	"github.com/0xdeafcafe/bloefish/libraries/clog"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/app"
+	"github.com/0xdeafcafe/bloefish/services/stream/internal/domain/models"
+)
+
+// heartbeatInterval is how often a comment is sent on idle streams, to stop
+// proxies from closing them.
+const heartbeatInterval = 15 * time.Second
+
+type SSE struct {
+	app *app.App
+}
+
+func New(ctx context.Context, app *app.App, mux *chi.Mux) *SSE {
+	sse := &SSE{app: app}
+
+	mux.Get("/sse", sse.serve)
+
+	return sse
+}
+
+func (s *SSE) serve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	channelID := r.URL.Query().Get("channel")
+	if channelID == "" {
+		writeError(w, cher.New(cher.BadRequest, nil, cher.New("missing_channel", nil)))
+		return
+	}
+
+	// Browsers send the ID of the last event they received when reconnecting,
+	// an unparsable ID starts the stream from now
+	lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	sub := s.app.MessageBroker.Subscribe(ctx, channelID, lastEventID)
+	defer sub.Unsubscribe()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range sub.Missed {
+		if err := writeEvent(w, event); err != nil {
+			clog.Get(ctx).WithError(err).Info("sse connection closed due to write error")
+			return
+		}
+	}
+
+	if err := rc.Flush(); err != nil {
+		clog.Get(ctx).WithError(err).Warn("unable to flush sse connection")
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// The client will reconnect with the last event ID, and pick
+				// up the events it missed
+				clog.Get(ctx).Info("sse subscription dropped by broker")
+				return
+			}
+
+			err = writeEvent(w, event)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			clog.Get(ctx).WithError(err).Info("sse connection closed due to write error")
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event *models.StreamEvent) error {
+	data, err := json.Marshal(event.Message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.ID, data)
+	return err
+}
+
+func writeError(w http.ResponseWriter, err cher.E) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(err.StatusCode())
+
+	_ = json.NewEncoder(w).Encode(err)
+}
@@ -3,7 +3,8 @@ package ws
 import (
 	"context"
 	"net/http"
-	"time"
+	"net/url"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
@@ -14,30 +15,36 @@ import (
 
 type WS struct {
 	app *app.App
-}
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // TODO(afr): Change this to validate something later?
-	},
+	upgrader websocket.Upgrader
 }
 
-func New(ctx context.Context, app *app.App, mux *chi.Mux) *WS {
+// New registers the websocket endpoint. Browsers may only connect from one of
+// allowedOrigins, which may include "*" to allow any origin. Requests without
+// an Origin header don't come from a browser, so are always allowed.
+func New(ctx context.Context, app *app.App, mux *chi.Mux, allowedOrigins []string) *WS {
+	ws := &WS{
+		app: app,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(allowedOrigins),
+		},
+	}
+
 	mux.Get("/ws", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+		conn, err := ws.upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			clog.Get(ctx).WithError(err).Error("unable to upgrade websocket connection")
 			return
 		}
 
-		app.MessageBroker.RegisterConnection(ctx, conn)
-
-		if err := conn.SetReadDeadline(time.Now().Add(10 * time.Minute)); err != nil {
-			clog.Get(ctx).WithError(err).Warn("unable to set read deadline on websocket connection")
-		}
+		unregister := app.MessageBroker.RegisterConnection(ctx, conn)
+		defer unregister()
 
+		// Clients don't send anything, but reading is needed to process pongs
+		// and close messages. Read errors include the deadline extended by
+		// each pong passing.
 		for {
 			_, _, err := conn.ReadMessage()
 			if err != nil {
@@ -47,5 +54,31 @@ func New(ctx context.Context, app *app.App, mux *chi.Mux) *WS {
 		}
 	})
 
-	return &WS{app: app}
+	return ws
+}
+
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		if _, ok := allowed["*"]; ok {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+
+		_, ok := allowed[strings.ToLower(u.Scheme+"://"+u.Host)]
+		return ok
+	}
 }
@@ -1,3 +1,5 @@
+// Code generated by crpcgen. DO NOT EDIT.
+
 package stream
 
 import (
@@ -7,6 +9,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/crpc"
 )
 
+// Ensure RPCClient implements Service.
+var _ Service = (*RPCClient)(nil)
+
 type RPCClient struct {
 	client *crpc.Client
 }
@@ -17,14 +22,22 @@ func NewRPCClient(ctx context.Context, cfg config.UnauthenticatedService) Servic
 	}
 }
 
+func (r *RPCClient) SendMessageFull(ctx context.Context, req *SendMessageFullRequest) error {
+	return r.client.Do(ctx, "send_message_full", "2025-02-12", req, nil)
+}
+
 func (r *RPCClient) SendMessageFragment(ctx context.Context, req *SendMessageFragmentRequest) error {
 	return r.client.Do(ctx, "send_message_fragment", "2025-02-12", req, nil)
 }
 
-func (r *RPCClient) SendMessageFull(ctx context.Context, req *SendMessageFullRequest) error {
-	return r.client.Do(ctx, "send_message_full", "2025-02-12", req, nil)
+func (r *RPCClient) SendReasoningFragment(ctx context.Context, req *SendReasoningFragmentRequest) error {
+	return r.client.Do(ctx, "send_reasoning_fragment", "2025-02-12", req, nil)
 }
 
 func (r *RPCClient) SendErrorMessage(ctx context.Context, req *SendErrorMessageRequest) error {
 	return r.client.Do(ctx, "send_error_message", "2025-02-12", req, nil)
 }
+
+func (r *RPCClient) SendMessageReset(ctx context.Context, req *SendMessageResetRequest) error {
+	return r.client.Do(ctx, "send_message_reset", "2025-02-12", req, nil)
+}
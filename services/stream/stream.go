@@ -1,5 +1,7 @@
 package stream
 
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
 import (
 	"context"
 
@@ -9,15 +11,19 @@ import (
 type Service interface {
 	SendMessageFull(context.Context, *SendMessageFullRequest) error
 	SendMessageFragment(context.Context, *SendMessageFragmentRequest) error
+	SendReasoningFragment(context.Context, *SendReasoningFragmentRequest) error
 	SendErrorMessage(context.Context, *SendErrorMessageRequest) error
+	SendMessageReset(context.Context, *SendMessageResetRequest) error
 }
 
 type StreamedMessageType string
 
 const (
-	StreamedMessageTypeMessageFull     StreamedMessageType = "message_full"
-	StreamedMessageTypeMessageFragment StreamedMessageType = "message_fragment"
-	StreamedMessageTypeError           StreamedMessageType = "error"
+	StreamedMessageTypeMessageFull       StreamedMessageType = "message_full"
+	StreamedMessageTypeMessageFragment   StreamedMessageType = "message_fragment"
+	StreamedMessageTypeReasoningFragment StreamedMessageType = "reasoning_fragment"
+	StreamedMessageTypeError             StreamedMessageType = "error"
+	StreamedMessageTypeMessageReset      StreamedMessageType = "message_reset"
 )
 
 type SendMessageFullRequest struct {
@@ -30,16 +36,26 @@ type SendMessageFragmentRequest struct {
 	MessageContent string `json:"message_content"`
 }
 
+type SendReasoningFragmentRequest struct {
+	ChannelID        string `json:"channel_id"`
+	ReasoningContent string `json:"reasoning_content"`
+}
+
 type SendErrorMessageRequest struct {
 	ChannelID string `json:"channel_id"`
 	Error     cher.E `json:"error"`
 }
 
+type SendMessageResetRequest struct {
+	ChannelID string `json:"channel_id"`
+}
+
 type StreamedMessage struct {
 	ChannelID string              `json:"channel_id"`
 	Type      StreamedMessageType `json:"type"`
 
-	MessageFull     *string `json:"message_full"`
-	MessageFragment *string `json:"message_fragment"`
-	Error           *cher.E `json:"error"`
+	MessageFull       *string `json:"message_full"`
+	MessageFragment   *string `json:"message_fragment"`
+	ReasoningFragment *string `json:"reasoning_fragment"`
+	Error             *cher.E `json:"error"`
 }
@@ -8,7 +8,8 @@ import (
 )
 
 type App struct {
-	UserRepository ports.UserRepository
+	UserRepository            ports.UserRepository
+	UserPreferencesRepository ports.UserPreferencesRepository
 }
 
 func (a *App) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
@@ -18,3 +19,19 @@ func (a *App) GetUserByID(ctx context.Context, userID string) (*models.User, err
 func (a *App) GetOrCreateDefaultUser(ctx context.Context) (*models.User, error) {
 	return a.UserRepository.GetOrCreateDefaultUser(ctx)
 }
+
+func (a *App) GetUserPreferences(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	if _, err := a.UserRepository.GetByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return a.UserPreferencesRepository.GetByUserID(ctx, userID)
+}
+
+func (a *App) UpdateUserPreferences(ctx context.Context, cmd *models.UpdateUserPreferencesCommand) (*models.UserPreferences, error) {
+	if _, err := a.UserRepository.GetByUserID(ctx, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	return a.UserPreferencesRepository.Upsert(ctx, cmd)
+}
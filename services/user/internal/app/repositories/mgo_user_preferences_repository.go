@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/0xdeafcafe/bloefish/services/user/internal/domain/models"
+	"github.com/0xdeafcafe/bloefish/services/user/internal/domain/ports"
+)
+
+type persistedUserPreferences struct {
+	UserID string `bson:"_id"`
+
+	DefaultAIRelayOptions         *persistedAIRelayOptions `bson:"default_ai_relay_options"`
+	TitleGenerationAIRelayOptions *persistedAIRelayOptions `bson:"title_generation_ai_relay_options"`
+
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt *time.Time `bson:"updated_at"`
+}
+
+type persistedAIRelayOptions struct {
+	ProviderID string `bson:"provider_id"`
+	ModelID    string `bson:"model_id"`
+}
+
+type mgoUserPreferences struct {
+	c *mongo.Collection
+}
+
+func NewMgoUserPreferences(db *mongo.Database) ports.UserPreferencesRepository {
+	return &mgoUserPreferences{
+		c: db.Collection("user_preferences"),
+	}
+}
+
+func (r *mgoUserPreferences) GetByUserID(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	var preferences *persistedUserPreferences
+	if err := r.c.FindOne(ctx, bson.M{"_id": userID}).Decode(&preferences); err != nil {
+		// Users who have never saved their preferences get an empty document, so
+		// callers can always fall back to their own defaults.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &models.UserPreferences{UserID: userID}, nil
+		}
+
+		return nil, err
+	}
+
+	return preferences.ToUserPreferences(), nil
+}
+
+func (r *mgoUserPreferences) Upsert(ctx context.Context, cmd *models.UpdateUserPreferencesCommand) (*models.UserPreferences, error) {
+	now := time.Now()
+
+	result := r.c.FindOneAndUpdate(ctx, bson.M{
+		"_id": cmd.UserID,
+	}, bson.M{
+		"$set": bson.M{
+			"default_ai_relay_options":          newPersistedAIRelayOptions(cmd.DefaultAIRelayOptions),
+			"title_generation_ai_relay_options": newPersistedAIRelayOptions(cmd.TitleGenerationAIRelayOptions),
+			"updated_at":                        now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var preferences *persistedUserPreferences
+	if err := result.Decode(&preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences.ToUserPreferences(), nil
+}
+
+func newPersistedAIRelayOptions(opts *models.AIRelayOptions) *persistedAIRelayOptions {
+	if opts == nil {
+		return nil
+	}
+
+	return &persistedAIRelayOptions{
+		ProviderID: opts.ProviderID,
+		ModelID:    opts.ModelID,
+	}
+}
+
+func (p *persistedAIRelayOptions) ToAIRelayOptions() *models.AIRelayOptions {
+	if p == nil {
+		return nil
+	}
+
+	return &models.AIRelayOptions{
+		ProviderID: p.ProviderID,
+		ModelID:    p.ModelID,
+	}
+}
+
+func (p *persistedUserPreferences) ToUserPreferences() *models.UserPreferences {
+	return &models.UserPreferences{
+		UserID:                        p.UserID,
+		DefaultAIRelayOptions:         p.DefaultAIRelayOptions.ToAIRelayOptions(),
+		TitleGenerationAIRelayOptions: p.TitleGenerationAIRelayOptions.ToAIRelayOptions(),
+		CreatedAt:                     &p.CreatedAt,
+		UpdatedAt:                     p.UpdatedAt,
+	}
+}
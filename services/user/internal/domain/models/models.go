@@ -11,3 +11,25 @@ type User struct {
 	UpdatedAt *time.Time `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
 }
+
+type UserPreferences struct {
+	UserID string `json:"user_id"`
+
+	DefaultAIRelayOptions         *AIRelayOptions `json:"default_ai_relay_options"`
+	TitleGenerationAIRelayOptions *AIRelayOptions `json:"title_generation_ai_relay_options"`
+
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type AIRelayOptions struct {
+	ProviderID string `json:"provider_id"`
+	ModelID    string `json:"model_id"`
+}
+
+type UpdateUserPreferencesCommand struct {
+	UserID string
+
+	DefaultAIRelayOptions         *AIRelayOptions
+	TitleGenerationAIRelayOptions *AIRelayOptions
+}
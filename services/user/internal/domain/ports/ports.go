@@ -10,3 +10,8 @@ type UserRepository interface {
 	GetByUserID(ctx context.Context, userID string) (*models.User, error)
 	GetOrCreateDefaultUser(ctx context.Context) (*models.User, error)
 }
+
+type UserPreferencesRepository interface {
+	GetByUserID(ctx context.Context, userID string) (*models.UserPreferences, error)
+	Upsert(ctx context.Context, cmd *models.UpdateUserPreferencesCommand) (*models.UserPreferences, error)
+}
@@ -54,7 +54,8 @@ func Run(ctx context.Context) error {
 	_, mongoDatabase := cfg.Mongo.MustConnect(ctx)
 
 	app := &app.App{
-		UserRepository: repositories.NewMgoUser(mongoDatabase),
+		UserRepository:            repositories.NewMgoUser(mongoDatabase),
+		UserPreferencesRepository: repositories.NewMgoUserPreferences(mongoDatabase),
 	}
 
 	rpc := rpc.New(ctx, app)
@@ -0,0 +1,18 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/user"
+)
+
+func (r *RPC) GetUserPreferences(ctx context.Context, req *user.GetUserPreferencesRequest) (*user.GetUserPreferencesResponse, error) {
+	preferences, err := r.app.GetUserPreferences(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.GetUserPreferencesResponse{
+		Preferences: preferences,
+	}, nil
+}
@@ -42,10 +42,13 @@ func New(ctx context.Context, app *app.App) *RPC {
 	}
 
 	svr := crpc.NewServer(middlewares.UnsafeNoAuthentication)
+	svr.Use(crpc.Metrics())
 	svr.Use(crpc.Logger())
 
 	svr.Register("get_user_by_id", "2025-02-12", schema("get_user_by_id"), rpc.GetUserByID)
 	svr.Register("get_or_create_default_user", "2025-02-12", nil, rpc.GetOrCreateDefaultUser)
+	svr.Register("get_user_preferences", "2025-02-12", schema("get_user_preferences"), rpc.GetUserPreferences)
+	svr.Register("update_user_preferences", "2025-02-12", schema("update_user_preferences"), rpc.UpdateUserPreferences)
 
 	mux := chi.NewRouter()
 	mux.Use(version.HeaderMiddleware(svcInfo.ServiceHTTPName))
@@ -0,0 +1,23 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/0xdeafcafe/bloefish/services/user"
+	"github.com/0xdeafcafe/bloefish/services/user/internal/domain/models"
+)
+
+func (r *RPC) UpdateUserPreferences(ctx context.Context, req *user.UpdateUserPreferencesRequest) (*user.UpdateUserPreferencesResponse, error) {
+	preferences, err := r.app.UpdateUserPreferences(ctx, &models.UpdateUserPreferencesCommand{
+		UserID:                        req.UserID,
+		DefaultAIRelayOptions:         req.DefaultAIRelayOptions,
+		TitleGenerationAIRelayOptions: req.TitleGenerationAIRelayOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.UpdateUserPreferencesResponse{
+		Preferences: preferences,
+	}, nil
+}
@@ -1,3 +1,5 @@
+// Code generated by crpcgen. DO NOT EDIT.
+
 package user
 
 import (
@@ -7,6 +9,9 @@ import (
 	"github.com/0xdeafcafe/bloefish/libraries/crpc"
 )
 
+// Ensure RPCClient implements Service.
+var _ Service = (*RPCClient)(nil)
+
 type RPCClient struct {
 	client *crpc.Client
 }
@@ -17,10 +22,18 @@ func NewRPCClient(ctx context.Context, cfg config.UnauthenticatedService) Servic
 	}
 }
 
-func (c *RPCClient) GetUserByID(ctx context.Context, req *GetUserByIDRequest) (resp *GetUserByIDResponse, err error) {
-	return resp, c.client.Do(ctx, "get_user_by_id", "2025-02-12", req, &resp)
+func (r *RPCClient) GetUserByID(ctx context.Context, req *GetUserByIDRequest) (resp *GetUserByIDResponse, err error) {
+	return resp, r.client.Do(ctx, "get_user_by_id", "2025-02-12", req, &resp)
+}
+
+func (r *RPCClient) GetOrCreateDefaultUser(ctx context.Context) (resp *GetOrCreateDefaultUserResponse, err error) {
+	return resp, r.client.Do(ctx, "get_or_create_default_user", "2025-02-12", nil, &resp)
+}
+
+func (r *RPCClient) GetUserPreferences(ctx context.Context, req *GetUserPreferencesRequest) (resp *GetUserPreferencesResponse, err error) {
+	return resp, r.client.Do(ctx, "get_user_preferences", "2025-02-12", req, &resp)
 }
 
-func (c *RPCClient) GetOrCreateDefaultUser(ctx context.Context) (resp *GetOrCreateDefaultUserResponse, err error) {
-	return resp, c.client.Do(ctx, "get_or_create_default_user", "2025-02-12", nil, &resp)
+func (r *RPCClient) UpdateUserPreferences(ctx context.Context, req *UpdateUserPreferencesRequest) (resp *UpdateUserPreferencesResponse, err error) {
+	return resp, r.client.Do(ctx, "update_user_preferences", "2025-02-12", req, &resp)
 }
@@ -1,5 +1,7 @@
 package user
 
+//go:generate go run github.com/0xdeafcafe/bloefish/cmd/crpcgen
+
 import (
 	"context"
 
@@ -9,6 +11,8 @@ import (
 type Service interface {
 	GetUserByID(ctx context.Context, req *GetUserByIDRequest) (*GetUserByIDResponse, error)
 	GetOrCreateDefaultUser(ctx context.Context) (*GetOrCreateDefaultUserResponse, error)
+	GetUserPreferences(ctx context.Context, req *GetUserPreferencesRequest) (*GetUserPreferencesResponse, error)
+	UpdateUserPreferences(ctx context.Context, req *UpdateUserPreferencesRequest) (*UpdateUserPreferencesResponse, error)
 }
 
 type GetUserByIDRequest struct {
@@ -22,3 +26,22 @@ type GetUserByIDResponse struct {
 type GetOrCreateDefaultUserResponse struct {
 	User *models.User `json:"user"`
 }
+
+type GetUserPreferencesRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type GetUserPreferencesResponse struct {
+	Preferences *models.UserPreferences `json:"preferences"`
+}
+
+type UpdateUserPreferencesRequest struct {
+	UserID string `json:"user_id"`
+
+	DefaultAIRelayOptions         *models.AIRelayOptions `json:"default_ai_relay_options"`
+	TitleGenerationAIRelayOptions *models.AIRelayOptions `json:"title_generation_ai_relay_options"`
+}
+
+type UpdateUserPreferencesResponse struct {
+	Preferences *models.UserPreferences `json:"preferences"`
+}